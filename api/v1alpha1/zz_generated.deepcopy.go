@@ -18,7 +18,8 @@
 package v1alpha1
 
 import (
-	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -37,6 +38,113 @@ func (in *AWSLoadBalancerParameters) DeepCopy() *AWSLoadBalancerParameters {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Address) DeepCopyInto(out *Address) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Address.
+func (in *Address) DeepCopy() *Address {
+	if in == nil {
+		return nil
+	}
+	out := new(Address)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureHealthProbe) DeepCopyInto(out *AzureHealthProbe) {
+	*out = *in
+	if in.IntervalSeconds != nil {
+		in, out := &in.IntervalSeconds, &out.IntervalSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NumberOfProbes != nil {
+		in, out := &in.NumberOfProbes, &out.NumberOfProbes
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureHealthProbe.
+func (in *AzureHealthProbe) DeepCopy() *AzureHealthProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureHealthProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureLoadBalancerParameters) DeepCopyInto(out *AzureLoadBalancerParameters) {
+	*out = *in
+	if in.HealthProbe != nil {
+		in, out := &in.HealthProbe, &out.HealthProbe
+		*out = new(AzureHealthProbe)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureLoadBalancerParameters.
+func (in *AzureLoadBalancerParameters) DeepCopy() *AzureLoadBalancerParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureLoadBalancerParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertGenSettings) DeepCopyInto(out *CertGenSettings) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertGenSettings.
+func (in *CertGenSettings) DeepCopy() *CertGenSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(CertGenSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateStatus) DeepCopyInto(out *CertificateStatus) {
+	*out = *in
+	in.NotAfter.DeepCopyInto(&out.NotAfter)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateStatus.
+func (in *CertificateStatus) DeepCopy() *CertificateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentStatus) DeepCopyInto(out *ComponentStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentStatus.
+func (in *ComponentStatus) DeepCopy() *ComponentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContainerPort) DeepCopyInto(out *ContainerPort) {
 	*out = *in
@@ -79,6 +187,46 @@ func (in *Contour) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContourConfig) DeepCopyInto(out *ContourConfig) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(TLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JSONFields != nil {
+		in, out := &in.JSONFields, &out.JSONFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DefaultHTTPVersions != nil {
+		in, out := &in.DefaultHTTPVersions, &out.DefaultHTTPVersions
+		*out = make([]HTTPVersion, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeouts != nil {
+		in, out := &in.Timeouts, &out.Timeouts
+		*out = new(TimeoutConfig)
+		**out = **in
+	}
+	if in.NumTrustedHops != nil {
+		in, out := &in.NumTrustedHops, &out.NumTrustedHops
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContourConfig.
+func (in *ContourConfig) DeepCopy() *ContourConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ContourConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContourList) DeepCopyInto(out *ContourList) {
 	*out = *in
@@ -111,6 +259,27 @@ func (in *ContourList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContourSettings) DeepCopyInto(out *ContourSettings) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.NodePlacement != nil {
+		in, out := &in.NodePlacement, &out.NodePlacement
+		*out = new(NodePlacement)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContourSettings.
+func (in *ContourSettings) DeepCopy() *ContourSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(ContourSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContourSpec) DeepCopyInto(out *ContourSpec) {
 	*out = *in
@@ -126,6 +295,22 @@ func (in *ContourSpec) DeepCopyInto(out *ContourSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	in.Contour.DeepCopyInto(&out.Contour)
+	in.Envoy.DeepCopyInto(&out.Envoy)
+	in.CertGen.DeepCopyInto(&out.CertGen)
+	out.Monitoring = in.Monitoring
+	in.XDSTLS.DeepCopyInto(&out.XDSTLS)
+	in.Config.DeepCopyInto(&out.Config)
+	if in.IPFamilyPolicy != nil {
+		in, out := &in.IPFamilyPolicy, &out.IPFamilyPolicy
+		*out = new(v1.IPFamilyPolicyType)
+		**out = **in
+	}
+	if in.IPFamilies != nil {
+		in, out := &in.IPFamilies, &out.IPFamilies
+		*out = make([]v1.IPFamily, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContourSpec.
@@ -141,9 +326,31 @@ func (in *ContourSpec) DeepCopy() *ContourSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContourStatus) DeepCopyInto(out *ContourStatus) {
 	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]Address, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodePorts != nil {
+		in, out := &in.NodePorts, &out.NodePorts
+		*out = make([]NodePortStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = make([]CertificateStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]ComponentStatus, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+		*out = make([]metav1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -176,6 +383,20 @@ func (in *EnvoyNetworkPublishing) DeepCopyInto(out *EnvoyNetworkPublishing) {
 		*out = make([]ContainerPort, len(*in))
 		copy(*out, *in)
 	}
+	if in.ServiceAnnotations != nil {
+		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ServiceLabels != nil {
+		in, out := &in.ServiceLabels, &out.ServiceLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoyNetworkPublishing.
@@ -188,10 +409,67 @@ func (in *EnvoyNetworkPublishing) DeepCopy() *EnvoyNetworkPublishing {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvoySettings) DeepCopyInto(out *EnvoySettings) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	in.ShutdownManagerResources.DeepCopyInto(&out.ShutdownManagerResources)
+	if in.NodePlacement != nil {
+		in, out := &in.NodePlacement, &out.NodePlacement
+		*out = new(NodePlacement)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvoySettings.
+func (in *EnvoySettings) DeepCopy() *EnvoySettings {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvoySettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GCPLoadBalancerParameters) DeepCopyInto(out *GCPLoadBalancerParameters) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCPLoadBalancerParameters.
+func (in *GCPLoadBalancerParameters) DeepCopy() *GCPLoadBalancerParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(GCPLoadBalancerParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuerReference) DeepCopyInto(out *IssuerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuerReference.
+func (in *IssuerReference) DeepCopy() *IssuerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadBalancerStrategy) DeepCopyInto(out *LoadBalancerStrategy) {
 	*out = *in
 	in.ProviderParameters.DeepCopyInto(&out.ProviderParameters)
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancerStrategy.
@@ -204,6 +482,21 @@ func (in *LoadBalancerStrategy) DeepCopy() *LoadBalancerStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringSettings) DeepCopyInto(out *MonitoringSettings) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSettings.
+func (in *MonitoringSettings) DeepCopy() *MonitoringSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NamespaceSpec) DeepCopyInto(out *NamespaceSpec) {
 	*out = *in
@@ -219,6 +512,21 @@ func (in *NamespaceSpec) DeepCopy() *NamespaceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacedName) DeepCopyInto(out *NamespacedName) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedName.
+func (in *NamespacedName) DeepCopy() *NamespacedName {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacedName)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkPublishing) DeepCopyInto(out *NetworkPublishing) {
 	*out = *in
@@ -235,6 +543,47 @@ func (in *NetworkPublishing) DeepCopy() *NetworkPublishing {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePlacement) DeepCopyInto(out *NodePlacement) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePlacement.
+func (in *NodePlacement) DeepCopy() *NodePlacement {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePlacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodePort) DeepCopyInto(out *NodePort) {
 	*out = *in
@@ -255,6 +604,21 @@ func (in *NodePort) DeepCopy() *NodePort {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePortStatus) DeepCopyInto(out *NodePortStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePortStatus.
+func (in *NodePortStatus) DeepCopy() *NodePortStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePortStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProviderLoadBalancerParameters) DeepCopyInto(out *ProviderLoadBalancerParameters) {
 	*out = *in
@@ -263,6 +627,16 @@ func (in *ProviderLoadBalancerParameters) DeepCopyInto(out *ProviderLoadBalancer
 		*out = new(AWSLoadBalancerParameters)
 		**out = **in
 	}
+	if in.Azure != nil {
+		in, out := &in.Azure, &out.Azure
+		*out = new(AzureLoadBalancerParameters)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GCP != nil {
+		in, out := &in.GCP, &out.GCP
+		*out = new(GCPLoadBalancerParameters)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderLoadBalancerParameters.
@@ -274,3 +648,88 @@ func (in *ProviderLoadBalancerParameters) DeepCopy() *ProviderLoadBalancerParame
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
+	*out = *in
+	if in.CipherSuites != nil {
+		in, out := &in.CipherSuites, &out.CipherSuites
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FallbackCertificate != nil {
+		in, out := &in.FallbackCertificate, &out.FallbackCertificate
+		*out = new(NamespacedName)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSConfig.
+func (in *TLSConfig) DeepCopy() *TLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimeoutConfig) DeepCopyInto(out *TimeoutConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimeoutConfig.
+func (in *TimeoutConfig) DeepCopy() *TimeoutConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TimeoutConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *XDSTLSSecretRefs) DeepCopyInto(out *XDSTLSSecretRefs) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new XDSTLSSecretRefs.
+func (in *XDSTLSSecretRefs) DeepCopy() *XDSTLSSecretRefs {
+	if in == nil {
+		return nil
+	}
+	out := new(XDSTLSSecretRefs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *XDSTLSSettings) DeepCopyInto(out *XDSTLSSettings) {
+	*out = *in
+	if in.CertificateLifetime != nil {
+		in, out := &in.CertificateLifetime, &out.CertificateLifetime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(IssuerReference)
+		**out = **in
+	}
+	if in.SecretRefs != nil {
+		in, out := &in.SecretRefs, &out.SecretRefs
+		*out = new(XDSTLSSecretRefs)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new XDSTLSSettings.
+func (in *XDSTLSSettings) DeepCopy() *XDSTLSSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(XDSTLSSettings)
+	in.DeepCopyInto(out)
+	return out
+}
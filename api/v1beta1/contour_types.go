@@ -0,0 +1,1195 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// +kubebuilder:object:root=true
+
+// Contour is the Schema for the contours API.
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Available")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Available")].reason`
+type Contour struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the desired state of Contour.
+	Spec ContourSpec `json:"spec,omitempty"`
+	// Status defines the observed state of Contour.
+	Status ContourStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ContourList contains a list of Contour.
+type ContourList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Contour `json:"items"`
+}
+
+// ContourSpec defines the desired state of Contour.
+type ContourSpec struct {
+	// Replicas is the desired number of Contour replicas. If unset,
+	// defaults to 2.
+	//
+	// +kubebuilder:default=2
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Namespace defines the schema of a Contour namespace. See each field for
+	// additional details. Namespace name should be the same namespace as the
+	// Gateway when GatewayClassRef is set.
+	//
+	// +kubebuilder:default={name: "projectcontour", removeOnDeletion: false}
+	Namespace NamespaceSpec `json:"namespace,omitempty"`
+
+	// NetworkPublishing defines the schema for publishing Contour to a network.
+	//
+	// See each field for additional details.
+	//
+	// +kubebuilder:default={envoy: {type: LoadBalancerService, ports: {http: {containerPort: 8080}, https: {containerPort: 8443}}}}
+	NetworkPublishing NetworkPublishing `json:"networkPublishing,omitempty"`
+
+	// GatewayClassRef is a reference to a GatewayClass name used for
+	// managing a Contour.
+	//
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	GatewayClassRef *string `json:"gatewayClassRef,omitempty"`
+
+	// IngressClassName is the name of the IngressClass used by Contour. If unset,
+	// Contour will process all ingress objects without an ingress class annotation
+	// or ingress objects with an annotation matching ingress-class=contour. When
+	// specified, Contour will only process ingress objects that match the provided
+	// class.
+	//
+	// For additional IngressClass details, refer to:
+	//   https://projectcontour.io/docs/main/config/annotations/#ingress-class
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+
+	// Contour defines the schema for running Contour, the control plane.
+	//
+	// See each field for additional details.
+	//
+	// +optional
+	Contour ContourSettings `json:"contour,omitempty"`
+
+	// Envoy defines the schema for running Envoy, the data plane of Contour.
+	//
+	// See each field for additional details.
+	//
+	// +kubebuilder:default={workloadType: DaemonSet, replicas: 2}
+	Envoy EnvoySettings `json:"envoy,omitempty"`
+
+	// CertGen defines the schema for running certgen, the job that
+	// generates the xDS TLS certificates used between Contour and Envoy.
+	//
+	// See each field for additional details.
+	//
+	// +optional
+	CertGen CertGenSettings `json:"certgen,omitempty"`
+
+	// Monitoring defines the schema for monitoring Contour and Envoy with
+	// the Prometheus Operator.
+	//
+	// See each field for additional details.
+	//
+	// +optional
+	Monitoring MonitoringSettings `json:"monitoring,omitempty"`
+
+	// XDSTLS defines the schema for the TLS certificates that secure the xDS
+	// connection between Contour and Envoy.
+	//
+	// See each field for additional details.
+	//
+	// +kubebuilder:default={provider: CertGen}
+	XDSTLS XDSTLSSettings `json:"xdsTLS,omitempty"`
+
+	// Config defines the schema of the Contour configuration file. Unset
+	// fields use the defaults of Contour.
+	//
+	// See each field for additional details.
+	//
+	// +optional
+	Config ContourConfig `json:"config,omitempty"`
+
+	// IPFamilyPolicy is the IP family policy of the Contour and Envoy Services.
+	// Valid values are "SingleStack", "PreferDualStack" and "RequireDualStack".
+	// If IPv6 is used, Contour and Envoy bind to the IPv6 wildcard address "::",
+	// which also accepts IPv4 connections in dual-stack clusters.
+	//
+	// If unset, the default IP family policy of the cluster is used.
+	//
+	// See: https://kubernetes.io/docs/concepts/services-networking/dual-stack/
+	//
+	// +kubebuilder:validation:Enum=SingleStack;PreferDualStack;RequireDualStack
+	// +optional
+	IPFamilyPolicy *corev1.IPFamilyPolicyType `json:"ipFamilyPolicy,omitempty"`
+
+	// IPFamilies are the IP families of the Contour and Envoy Services, in order
+	// of preference. Valid values are "IPv4" and "IPv6". Use a single "IPv6"
+	// family for IPv6-only clusters.
+	//
+	// If unset, the IP families are chosen based on ipFamilyPolicy and the
+	// cluster configuration.
+	//
+	// +kubebuilder:validation:MaxItems=2
+	// +optional
+	IPFamilies []corev1.IPFamily `json:"ipFamilies,omitempty"`
+}
+
+// ContourConfig defines the schema of the Contour configuration file.
+type ContourConfig struct {
+	// TLS defines the TLS configuration of Envoy listeners.
+	//
+	// +optional
+	TLS *TLSConfig `json:"tls,omitempty"`
+
+	// AccessLogFormat is the format of Envoy access logs. If unset,
+	// defaults to "envoy".
+	//
+	// +optional
+	AccessLogFormat AccessLogFormat `json:"accessLogFormat,omitempty"`
+
+	// JSONFields are the fields logged by Envoy when AccessLogFormat is
+	// "json". If unset, Contour logs its default list of fields.
+	//
+	// See https://godoc.org/github.com/projectcontour/contour/internal/envoy#JSONFields
+	// for the canonical list of fields.
+	//
+	// +optional
+	JSONFields []string `json:"jsonFields,omitempty"`
+
+	// DefaultHTTPVersions are the HTTP versions that Envoy will offer to
+	// upstream services. If unset, both "HTTP/1.1" and "HTTP/2" are offered.
+	//
+	// +optional
+	DefaultHTTPVersions []HTTPVersion `json:"defaultHTTPVersions,omitempty"`
+
+	// Timeouts defines the timeouts of Envoy connections and requests.
+	//
+	// +optional
+	Timeouts *TimeoutConfig `json:"timeouts,omitempty"`
+
+	// DNSLookupFamily is the DNS IP address resolution policy of Envoy
+	// clusters that reference external names. If unset, defaults to "auto".
+	//
+	// +optional
+	DNSLookupFamily DNSLookupFamily `json:"dnsLookupFamily,omitempty"`
+
+	// NumTrustedHops is the number of additional ingress proxy hops from
+	// the right side of the x-forwarded-for HTTP header to trust when
+	// determining the origin client's IP address. If unset, defaults to 0.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	NumTrustedHops *int32 `json:"numTrustedHops,omitempty"`
+}
+
+// TLSConfig defines the TLS configuration of Envoy listeners.
+type TLSConfig struct {
+	// MinimumProtocolVersion is the minimum TLS version that Envoy will
+	// negotiate. If unset, defaults to "1.2".
+	//
+	// +kubebuilder:validation:Enum="1.2";"1.3"
+	// +optional
+	MinimumProtocolVersion string `json:"minimumProtocolVersion,omitempty"`
+
+	// CipherSuites are the TLS ciphers supported by Envoy listeners when
+	// negotiating TLS 1.2. If unset, Contour's default cipher suites are used.
+	//
+	// +optional
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+
+	// FallbackCertificate is a reference to the secret used as the fallback
+	// certificate for requests that don't match the SNI of a virtual host.
+	//
+	// +optional
+	FallbackCertificate *NamespacedName `json:"fallbackCertificate,omitempty"`
+}
+
+// NamespacedName is a reference to a namespaced resource.
+type NamespacedName struct {
+	// Name is the name of the resource.
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the resource.
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	Namespace string `json:"namespace"`
+}
+
+// TimeoutConfig defines the timeouts of Envoy connections and requests.
+// Each timeout is a duration such as "30s" or "5m", or "infinity" to
+// disable the timeout. Unset timeouts use the defaults of Contour.
+type TimeoutConfig struct {
+	// RequestTimeout is the timeout for an entire request.
+	//
+	// +optional
+	RequestTimeout string `json:"requestTimeout,omitempty"`
+
+	// ConnectionIdleTimeout is the time to wait before closing an idle
+	// connection.
+	//
+	// +optional
+	ConnectionIdleTimeout string `json:"connectionIdleTimeout,omitempty"`
+
+	// StreamIdleTimeout is the time to wait before resetting an idle stream.
+	//
+	// +optional
+	StreamIdleTimeout string `json:"streamIdleTimeout,omitempty"`
+
+	// MaxConnectionDuration is the maximum lifetime of a connection.
+	//
+	// +optional
+	MaxConnectionDuration string `json:"maxConnectionDuration,omitempty"`
+
+	// DelayedCloseTimeout is the time to wait for a downstream client to
+	// close a connection after Envoy sends a close.
+	//
+	// +optional
+	DelayedCloseTimeout string `json:"delayedCloseTimeout,omitempty"`
+
+	// ConnectionShutdownGracePeriod is the time to wait between sending an
+	// initial GOAWAY frame and a final GOAWAY frame when draining an HTTP/2
+	// connection.
+	//
+	// +optional
+	ConnectionShutdownGracePeriod string `json:"connectionShutdownGracePeriod,omitempty"`
+}
+
+// AccessLogFormat is the format of Envoy access logs.
+// +kubebuilder:validation:Enum=envoy;json
+type AccessLogFormat string
+
+const (
+	// EnvoyAccessLogFormat logs using Envoy's default format.
+	EnvoyAccessLogFormat AccessLogFormat = "envoy"
+
+	// JSONAccessLogFormat logs the fields specified by JSONFields
+	// as JSON.
+	JSONAccessLogFormat AccessLogFormat = "json"
+)
+
+// HTTPVersion is an HTTP version offered by Envoy to upstream services.
+// +kubebuilder:validation:Enum="HTTP/1.1";"HTTP/2"
+type HTTPVersion string
+
+const (
+	// HTTPVersion1 is HTTP/1.1.
+	HTTPVersion1 HTTPVersion = "HTTP/1.1"
+
+	// HTTPVersion2 is HTTP/2.
+	HTTPVersion2 HTTPVersion = "HTTP/2"
+)
+
+// DNSLookupFamily is the DNS IP address resolution policy of Envoy clusters.
+// +kubebuilder:validation:Enum=auto;v4;v6
+type DNSLookupFamily string
+
+const (
+	// AutoDNSLookupFamily looks up IPv6 addresses and falls back to IPv4.
+	AutoDNSLookupFamily DNSLookupFamily = "auto"
+
+	// IPv4DNSLookupFamily only looks up IPv4 addresses.
+	IPv4DNSLookupFamily DNSLookupFamily = "v4"
+
+	// IPv6DNSLookupFamily only looks up IPv6 addresses.
+	IPv6DNSLookupFamily DNSLookupFamily = "v6"
+)
+
+// ContourSettings defines the schema for running Contour.
+type ContourSettings struct {
+	// Image is the container image reference used for Contour, e.g.
+	// "docker.io/projectcontour/contour:main". The image is also used
+	// for the certgen job and the Envoy shutdown-manager and initconfig
+	// containers. If unset, the image of the operator's "--contour-image"
+	// flag is used.
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Resources are the compute resource requirements of the Contour
+	// container. If unset, no requests or limits are specified.
+	//
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// NodePlacement describes node scheduling configuration of Contour pods.
+	//
+	// +optional
+	NodePlacement *NodePlacement `json:"nodePlacement,omitempty"`
+}
+
+// CertGenSettings defines the schema for running certgen.
+type CertGenSettings struct {
+	// Resources are the compute resource requirements of the certgen
+	// container. If unset, no requests or limits are specified.
+	//
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// MonitoringSettings defines the schema for monitoring Contour and Envoy.
+type MonitoringSettings struct {
+	// PodMonitors specifies whether PodMonitors are created to scrape the
+	// metrics of Contour and Envoy. PodMonitors are only created when the
+	// monitoring.coreos.com CRDs of the Prometheus Operator are installed.
+	// If unset, defaults to false.
+	//
+	// +optional
+	PodMonitors bool `json:"podMonitors,omitempty"`
+}
+
+// XDSTLSSettings defines the schema for the xDS TLS certificates.
+type XDSTLSSettings struct {
+	// Provider is the provider of the xDS TLS certificates. Valid values are:
+	//
+	// * CertGen: The certgen job generates the certificates once. The
+	//   certificates are not rotated.
+	//
+	// * Operator: The operator issues the certificates from a CA it manages
+	//   and rotates them before they expire, restarting Contour and Envoy.
+	//
+	// * CertManager: cert-manager issues the certificates from IssuerRef
+	//   and rotates them before they expire, restarting Contour and Envoy.
+	//   Requires the cert-manager CRDs to exist when the operator starts.
+	//
+	// If unset, defaults to "CertGen".
+	//
+	// +kubebuilder:default=CertGen
+	Provider XDSTLSProvider `json:"provider,omitempty"`
+
+	// CertificateLifetime is the lifetime of the certificates issued when
+	// Provider is "Operator" or "CertManager", e.g. "2160h". The certificates
+	// are rotated once less than a third of their lifetime remains. Must be
+	// at least 24h. If unset, defaults to "8760h" (365 days).
+	//
+	// +optional
+	CertificateLifetime *metav1.Duration `json:"certificateLifetime,omitempty"`
+
+	// IssuerRef is the cert-manager issuer of the certificates when Provider
+	// is "CertManager". The issuer must populate the "ca.crt" key of the
+	// certificate secrets, e.g. a CA issuer, since Contour and Envoy verify
+	// each other using the CA of their own certificate.
+	//
+	// +optional
+	IssuerRef *IssuerReference `json:"issuerRef,omitempty"`
+
+	// SecretRefs are pre-existing secrets containing the certificates, e.g.
+	// issued by an external PKI. When set, the operator mounts the secrets
+	// instead of provisioning certificates, so Provider must be "CertGen".
+	//
+	// +optional
+	SecretRefs *XDSTLSSecretRefs `json:"secretRefs,omitempty"`
+}
+
+// XDSTLSSecretRefs references the secrets containing the xDS TLS certificates
+// of Contour and Envoy. The secrets must exist in the namespace of
+// spec.namespace.name and contain the "ca.crt", "tls.crt" and "tls.key" keys.
+// The certificate of each secret must be issued by the CA of both secrets,
+// since Contour and Envoy verify each other using the CA of their own secret.
+type XDSTLSSecretRefs struct {
+	// Contour is the name of the secret containing the certificate of
+	// Contour.
+	//
+	// +kubebuilder:validation:MinLength=1
+	Contour string `json:"contour"`
+
+	// Envoy is the name of the secret containing the certificate of Envoy.
+	//
+	// +kubebuilder:validation:MinLength=1
+	Envoy string `json:"envoy"`
+}
+
+// IssuerReference is a reference to a cert-manager Issuer or ClusterIssuer.
+type IssuerReference struct {
+	// Name is the name of the issuer.
+	//
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Kind is the kind of the issuer. An Issuer must exist in the namespace
+	// of spec.namespace.name. If unset, defaults to "Issuer".
+	//
+	// +kubebuilder:default=Issuer
+	Kind IssuerKind `json:"kind,omitempty"`
+}
+
+// IssuerKind is the kind of a cert-manager issuer.
+// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+type IssuerKind string
+
+const (
+	// IssuerKindIssuer is a namespaced cert-manager Issuer.
+	IssuerKindIssuer IssuerKind = "Issuer"
+
+	// IssuerKindClusterIssuer is a cluster-scoped cert-manager ClusterIssuer.
+	IssuerKindClusterIssuer IssuerKind = "ClusterIssuer"
+)
+
+// XDSTLSProvider is the provider of the xDS TLS certificates.
+// +kubebuilder:validation:Enum=CertGen;Operator;CertManager
+type XDSTLSProvider string
+
+const (
+	// CertGenXDSTLSProvider generates the xDS TLS certificates using the
+	// certgen job.
+	CertGenXDSTLSProvider XDSTLSProvider = "CertGen"
+
+	// OperatorXDSTLSProvider issues and rotates the xDS TLS certificates
+	// in the operator.
+	OperatorXDSTLSProvider XDSTLSProvider = "Operator"
+
+	// CertManagerXDSTLSProvider issues and rotates the xDS TLS certificates
+	// using cert-manager.
+	CertManagerXDSTLSProvider XDSTLSProvider = "CertManager"
+)
+
+// EnvoySettings defines the schema for running Envoy.
+type EnvoySettings struct {
+	// WorkloadType is the type of workload used to run Envoy. If unset,
+	// defaults to "DaemonSet".
+	//
+	// +kubebuilder:default=DaemonSet
+	WorkloadType EnvoyWorkloadType `json:"workloadType,omitempty"`
+
+	// Replicas is the desired number of Envoy replicas when WorkloadType
+	// is "Deployment". Replicas is ignored when WorkloadType is "DaemonSet".
+	// If unset, defaults to 2.
+	//
+	// +kubebuilder:default=2
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Image is the container image reference used for Envoy, e.g.
+	// "docker.io/envoyproxy/envoy:v1.18.3". If unset, the image of the
+	// operator's "--envoy-image" flag is used.
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Resources are the compute resource requirements of the Envoy
+	// container. If unset, no requests or limits are specified.
+	//
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// ShutdownManagerResources are the compute resource requirements of
+	// the shutdown-manager container and the envoy-initconfig init
+	// container. If unset, no requests or limits are specified.
+	//
+	// +optional
+	ShutdownManagerResources corev1.ResourceRequirements `json:"shutdownManagerResources,omitempty"`
+
+	// NodePlacement describes node scheduling configuration of Envoy pods.
+	//
+	// +optional
+	NodePlacement *NodePlacement `json:"nodePlacement,omitempty"`
+}
+
+// NodePlacement describes node scheduling configuration for pods. If
+// nodeSelector and affinity are unset, pods may be scheduled to any node
+// of the cluster.
+type NodePlacement struct {
+	// NodeSelector is the simplest recommended form of node selection constraint
+	// and specifies a map of key-value pairs. For the pod to be eligible
+	// to run on a node, the node must have each of the indicated key-value pairs
+	// as labels (it can have additional labels as well).
+	//
+	// If unset, the pod(s) will be scheduled to any available node.
+	//
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations work with taints to ensure that pods are not scheduled
+	// onto inappropriate nodes. One or more taints are applied to a node; this
+	// marks that the node should not accept any pods that do not tolerate the
+	// taints.
+	//
+	// The default is an empty list.
+	//
+	// See https://kubernetes.io/docs/concepts/configuration/taint-and-toleration/
+	// for additional details.
+	//
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity is a group of affinity scheduling rules. If set, Affinity
+	// replaces the default pod anti-affinity that spreads the pods of a
+	// Deployment across nodes.
+	//
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// TopologySpreadConstraints describes how pods are spread across
+	// topology domains such as zones or nodes.
+	//
+	// See https://kubernetes.io/docs/concepts/workloads/pods/pod-topology-spread-constraints/
+	// for additional details.
+	//
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// PriorityClassName is the name of the PriorityClass of the pods.
+	// If unset, the pods use the default priority of the cluster.
+	//
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+}
+
+// EnvoyWorkloadType is the type of workload used to run Envoy.
+// +kubebuilder:validation:Enum=DaemonSet;Deployment
+type EnvoyWorkloadType string
+
+const (
+	// DaemonSetEnvoyWorkloadType runs Envoy using a DaemonSet, scheduling
+	// an Envoy pod to every eligible node of the cluster.
+	DaemonSetEnvoyWorkloadType EnvoyWorkloadType = "DaemonSet"
+
+	// DeploymentEnvoyWorkloadType runs Envoy using a Deployment with the
+	// number of replicas specified by Replicas.
+	DeploymentEnvoyWorkloadType EnvoyWorkloadType = "Deployment"
+)
+
+// NamespaceSpec defines the schema of a Contour namespace.
+type NamespaceSpec struct {
+	// Name is the name of the namespace to run Contour and dependent
+	// resources. If unset, defaults to "projectcontour".
+	//
+	// +kubebuilder:default=projectcontour
+	Name string `json:"name,omitempty"`
+
+	// RemoveOnDeletion will remove the namespace when the Contour is
+	// deleted. If set to True, deletion will not occur if any of the
+	// following conditions exist:
+	//
+	// 1. The Contour namespace is "default", "kube-system" or the
+	//    contour-operator's namespace.
+	//
+	// 2. Another Contour exists in the namespace.
+	//
+	// 3. The namespace does not contain the Contour owning label.
+	//
+	// +kubebuilder:default=false
+	RemoveOnDeletion bool `json:"removeOnDeletion,omitempty"`
+}
+
+// NetworkPublishing defines the schema for publishing Contour to a network.
+type NetworkPublishing struct {
+	// Envoy provides the schema for publishing the network endpoints of Envoy.
+	//
+	// If unset, defaults to:
+	//   type: LoadBalancerService
+	//   ports:
+	//     http:
+	//       containerPort: 8080
+	//     https:
+	//       containerPort: 8443
+	//
+	// +kubebuilder:default={type: LoadBalancerService, loadBalancer: {scope: External, providerParameters: {type: AWS}}, ports: {http: {containerPort: 8080}, https: {containerPort: 8443}}}
+	Envoy EnvoyNetworkPublishing `json:"envoy,omitempty"`
+}
+
+// EnvoyNetworkPublishing defines the schema to publish Envoy to a network.
+// +union
+type EnvoyNetworkPublishing struct {
+	// Type is the type of publishing strategy to use. Valid values are:
+	//
+	// * LoadBalancerService
+	//
+	// In this configuration, network endpoints for Envoy use container networking.
+	// A Kubernetes LoadBalancer Service is created to publish Envoy network
+	// endpoints. The Service uses port 80 to publish Envoy's HTTP network endpoint
+	// and port 443 to publish Envoy's HTTPS network endpoint.
+	//
+	// See: https://kubernetes.io/docs/concepts/services-networking/service/#loadbalancer
+	//
+	// * NodePortService
+	//
+	// Publishes Envoy network endpoints using a Kubernetes NodePort Service.
+	//
+	// In this configuration, Envoy network endpoints use container networking. A Kubernetes
+	// NodePort Service is created to publish the network endpoints.
+	//
+	// See: https://kubernetes.io/docs/concepts/services-networking/service/#nodeport
+	//
+	// * ClusterIPService
+	//
+	// Publishes Envoy network endpoints using a Kubernetes ClusterIP Service.
+	//
+	// In this configuration, Envoy network endpoints use container networking. A Kubernetes
+	// ClusterIP Service is created to publish the network endpoints.
+	//
+	// See: https://kubernetes.io/docs/concepts/services-networking/service/#publishing-services-service-types
+	//
+	// +unionDiscriminator
+	// +kubebuilder:default=LoadBalancerService
+	Type NetworkPublishingType `json:"type,omitempty"`
+
+	// LoadBalancer holds parameters for the load balancer. Present only if type is
+	// LoadBalancerService.
+	//
+	// If unspecified, defaults to an external Classic AWS ELB.
+	//
+	// +kubebuilder:default={scope: External, providerParameters: {type: AWS}}
+	LoadBalancer LoadBalancerStrategy `json:"loadBalancer,omitempty"`
+
+	// Ports are the network ports of Envoy's insecure (HTTP) and secure
+	// (HTTPS) listeners.
+	//
+	// +kubebuilder:default={http: {containerPort: 8080}, https: {containerPort: 8443}}
+	Ports EnvoyPorts `json:"ports,omitempty"`
+
+	// ServiceAnnotations are annotations added to the Envoy Service, e.g. to
+	// configure external-dns or provider-specific load balancer settings.
+	// Annotations set here take precedence over annotations the operator sets
+	// based on the load balancer parameters.
+	//
+	// +optional
+	ServiceAnnotations map[string]string `json:"serviceAnnotations,omitempty"`
+
+	// ServiceLabels are labels added to the Envoy Service. Labels used by the
+	// operator to track ownership of the Service cannot be overridden.
+	//
+	// +optional
+	ServiceLabels map[string]string `json:"serviceLabels,omitempty"`
+
+	// ExternalTrafficPolicy describes how nodes distribute service traffic they
+	// receive on one of the Service's "externally-facing" addresses. Valid values
+	// are "Local" and "Cluster". Not supported if type is ClusterIPService.
+	//
+	// If unset, defaults to "Local".
+	//
+	// +kubebuilder:validation:Enum=Local;Cluster
+	// +optional
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicyType `json:"externalTrafficPolicy,omitempty"`
+
+	// SessionAffinity is the session affinity of the Envoy Service. Valid values
+	// are "None" and "ClientIP".
+	//
+	// +kubebuilder:validation:Enum=None;ClientIP
+	// +kubebuilder:default=None
+	// +optional
+	SessionAffinity corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
+}
+
+// NetworkPublishingType is a way to publish network endpoints.
+// +kubebuilder:validation:Enum=LoadBalancerService;NodePortService;ClusterIPService
+type NetworkPublishingType string
+
+const (
+	// LoadBalancerServicePublishingType publishes a network endpoint using a Kubernetes
+	// LoadBalancer Service.
+	LoadBalancerServicePublishingType NetworkPublishingType = "LoadBalancerService"
+
+	// NodePortServicePublishingType publishes a network endpoint using a Kubernetes
+	// NodePort Service.
+	NodePortServicePublishingType NetworkPublishingType = "NodePortService"
+
+	// ClusterIPServicePublishingType publishes a network endpoint using a Kubernetes
+	// ClusterIP Service.
+	ClusterIPServicePublishingType NetworkPublishingType = "ClusterIPService"
+)
+
+// LoadBalancerStrategy holds parameters for a load balancer.
+type LoadBalancerStrategy struct {
+	// Scope indicates the scope at which the load balancer is exposed.
+	// Possible values are "External" and "Internal".
+	//
+	// +kubebuilder:default=External
+	Scope LoadBalancerScope `json:"scope,omitempty"`
+
+	// ProviderParameters contains load balancer information specific to
+	// the underlying infrastructure provider.
+	//
+	// +kubebuilder:default={type: "AWS"}
+	ProviderParameters ProviderLoadBalancerParameters `json:"providerParameters,omitempty"`
+
+	// LoadBalancerIP is the IP address requested for the load balancer. This
+	// is only honored by infrastructure providers that support it.
+	//
+	// +optional
+	LoadBalancerIP string `json:"loadBalancerIP,omitempty"`
+
+	// LoadBalancerSourceRanges restricts the client IP ranges allowed to access
+	// the load balancer, in CIDR notation. This is only honored by infrastructure
+	// providers that support it. If unset, all client IPs are allowed.
+	//
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+}
+
+// LoadBalancerScope is the scope at which a load balancer is exposed.
+// +kubebuilder:validation:Enum=Internal;External
+type LoadBalancerScope string
+
+var (
+	// InternalLoadBalancer is a load balancer that is exposed only on the
+	// cluster's private network.
+	InternalLoadBalancer LoadBalancerScope = "Internal"
+
+	// ExternalLoadBalancer is a load balancer that is exposed on the
+	// cluster's public network (which is typically on the Internet).
+	ExternalLoadBalancer LoadBalancerScope = "External"
+)
+
+// ProviderLoadBalancerParameters holds desired load balancer information
+// specific to the underlying infrastructure provider.
+//
+// +union
+type ProviderLoadBalancerParameters struct {
+	// Type is the underlying infrastructure provider for the load balancer.
+	// Allowed values are "AWS", "Azure", and "GCP".
+	//
+	// +unionDiscriminator
+	// +kubebuilder:default=AWS
+	Type LoadBalancerProviderType `json:"type,omitempty"`
+
+	// AWS provides configuration settings that are specific to AWS
+	// load balancers.
+	//
+	// If empty, defaults will be applied. See specific aws fields for
+	// details about their defaults.
+	//
+	// +optional
+	AWS *AWSLoadBalancerParameters `json:"aws,omitempty"`
+
+	// Azure provides configuration settings that are specific to Azure
+	// load balancers. Present only if type is Azure.
+	//
+	// +optional
+	Azure *AzureLoadBalancerParameters `json:"azure,omitempty"`
+
+	// GCP provides configuration settings that are specific to GCP
+	// load balancers. Present only if type is GCP.
+	//
+	// +optional
+	GCP *GCPLoadBalancerParameters `json:"gcp,omitempty"`
+}
+
+// LoadBalancerProviderType is the underlying infrastructure provider for the
+// load balancer. Allowed values are "AWS", "Azure", and "GCP".
+//
+// +kubebuilder:validation:Enum=AWS;Azure;GCP
+type LoadBalancerProviderType string
+
+const (
+	AWSLoadBalancerProvider   LoadBalancerProviderType = "AWS"
+	AzureLoadBalancerProvider LoadBalancerProviderType = "Azure"
+	GCPLoadBalancerProvider   LoadBalancerProviderType = "GCP"
+)
+
+// AWSLoadBalancerParameters provides configuration settings that are specific to
+// AWS load balancers.
+type AWSLoadBalancerParameters struct {
+	// Type is the type of AWS load balancer to manage.
+	//
+	// Valid values are:
+	//
+	// * "Classic": A Classic load balancer makes routing decisions at either the
+	//   transport layer (TCP/SSL) or the application layer (HTTP/HTTPS). See
+	//   the following for additional details:
+	//
+	//     https://docs.aws.amazon.com/AmazonECS/latest/developerguide/load-balancer-types.html#clb
+	//
+	// * "NLB": A Network load balancer makes routing decisions at the transport
+	//   layer (TCP/SSL). See the following for additional details:
+	//
+	//     https://docs.aws.amazon.com/AmazonECS/latest/developerguide/load-balancer-types.html#nlb
+	//
+	// If unset, defaults to "Classic".
+	//
+	// +kubebuilder:default=Classic
+	Type AWSLoadBalancerType `json:"type,omitempty"`
+}
+
+// AWSLoadBalancerType is the type of AWS load balancer to manage.
+// +kubebuilder:validation:Enum=Classic;NLB
+type AWSLoadBalancerType string
+
+const (
+	AWSClassicLoadBalancer AWSLoadBalancerType = "Classic"
+	AWSNetworkLoadBalancer AWSLoadBalancerType = "NLB"
+)
+
+// AzureLoadBalancerParameters provides configuration settings that are specific
+// to Azure load balancers. See the following for additional details:
+//
+//   https://docs.microsoft.com/en-us/azure/aks/load-balancer-standard
+//
+type AzureLoadBalancerParameters struct {
+	// ResourceGroup is the name of the resource group of the load balancer's
+	// public IP address, if it's not in the node resource group of the cluster.
+	//
+	// +kubebuilder:validation:MaxLength=90
+	// +optional
+	ResourceGroup string `json:"resourceGroup,omitempty"`
+
+	// PublicIPName is the name of a pre-created static public IP address
+	// to use for the load balancer.
+	//
+	// +kubebuilder:validation:MaxLength=80
+	// +optional
+	PublicIPName string `json:"publicIPName,omitempty"`
+
+	// Subnet is the name of the subnet in which to place an internal load
+	// balancer. Only supported if the load balancer scope is Internal.
+	//
+	// +kubebuilder:validation:MaxLength=80
+	// +optional
+	Subnet string `json:"subnet,omitempty"`
+
+	// HealthProbe holds the settings of the load balancer's health probe.
+	//
+	// +optional
+	HealthProbe *AzureHealthProbe `json:"healthProbe,omitempty"`
+}
+
+// AzureHealthProbe holds the settings of an Azure load balancer health probe.
+type AzureHealthProbe struct {
+	// Protocol is the protocol of the health probe. Valid values are "Http",
+	// "Https" and "Tcp".
+	//
+	// +kubebuilder:validation:Enum=Http;Https;Tcp
+	// +optional
+	Protocol AzureHealthProbeProtocol `json:"protocol,omitempty"`
+
+	// RequestPath is the request path of the health probe. Only supported
+	// if protocol is Http or Https.
+	//
+	// +optional
+	RequestPath string `json:"requestPath,omitempty"`
+
+	// IntervalSeconds is the interval between health probes in seconds.
+	//
+	// +kubebuilder:validation:Minimum=5
+	// +optional
+	IntervalSeconds *int32 `json:"intervalSeconds,omitempty"`
+
+	// NumberOfProbes is the number of consecutive failed health probes
+	// before a backend is considered unhealthy.
+	//
+	// +kubebuilder:validation:Minimum=2
+	// +optional
+	NumberOfProbes *int32 `json:"numberOfProbes,omitempty"`
+}
+
+// AzureHealthProbeProtocol is the protocol of an Azure load balancer health probe.
+type AzureHealthProbeProtocol string
+
+const (
+	AzureHealthProbeProtocolHTTP  AzureHealthProbeProtocol = "Http"
+	AzureHealthProbeProtocolHTTPS AzureHealthProbeProtocol = "Https"
+	AzureHealthProbeProtocolTCP   AzureHealthProbeProtocol = "Tcp"
+)
+
+// GCPLoadBalancerParameters provides configuration settings that are specific
+// to GCP load balancers. See the following for additional details:
+//
+//   https://cloud.google.com/kubernetes-engine/docs/concepts/service-load-balancer-parameters
+//
+type GCPLoadBalancerParameters struct {
+	// Subnet is the name of the subnet in which to place an internal load
+	// balancer. Only supported if the load balancer scope is Internal.
+	//
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	Subnet string `json:"subnet,omitempty"`
+
+	// GlobalAccess allows clients from any region to access an internal load
+	// balancer. Only supported if the load balancer scope is Internal.
+	//
+	// +optional
+	GlobalAccess bool `json:"globalAccess,omitempty"`
+
+	// BackendServiceBased creates an external load balancer that uses a
+	// regional backend service instead of a target pool. Only supported if
+	// the load balancer scope is External.
+	//
+	// +optional
+	BackendServiceBased bool `json:"backendServiceBased,omitempty"`
+
+	// NetworkEndpointGroups exposes the ports of the Envoy Service as
+	// standalone network endpoint groups (NEGs), which can be used as
+	// backends of other GCP load balancers.
+	//
+	// +optional
+	NetworkEndpointGroups bool `json:"networkEndpointGroups,omitempty"`
+}
+
+// EnvoyPorts is the schema to specify the network ports of Envoy's listeners.
+type EnvoyPorts struct {
+	// HTTP is the network port of Envoy's insecure listener.
+	//
+	// +kubebuilder:default={containerPort: 8080}
+	HTTP EnvoyPort `json:"http,omitempty"`
+
+	// HTTPS is the network port of Envoy's secure listener.
+	//
+	// +kubebuilder:default={containerPort: 8443}
+	HTTPS EnvoyPort `json:"https,omitempty"`
+}
+
+// EnvoyPort is the schema to specify a network port of an Envoy listener.
+type EnvoyPort struct {
+	// ContainerPort is the network port number Envoy listens on inside the
+	// Envoy pod. The number must be greater than 0 and less than 65536.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	ContainerPort int32 `json:"containerPort,omitempty"`
+
+	// NodePort is the network port number to expose on each node's IP for
+	// the listener. Present only if networkPublishing.envoy.type is
+	// NodePortService. If unspecified, a port number will be assigned from
+	// the cluster's nodeport service range, i.e. --service-node-port-range
+	// flag (default: 30000-32767).
+	//
+	// If specified, the number must:
+	//
+	// 1. Not be used by another NodePort Service.
+	// 2. Be within the cluster's nodeport service range, i.e. --service-node-port-range
+	//    flag (default: 30000-32767).
+	// 3. Be a valid network port number, i.e. greater than 0 and less than 65536.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	NodePort *int32 `json:"nodePort,omitempty"`
+}
+
+const (
+	// ContourAvailableConditionType indicates that the contour is running
+	// and available.
+	ContourAvailableConditionType = "Available"
+
+	// ContourNamespaceMigratedConditionType indicates whether the resources
+	// of the contour run in spec.namespace.name. The condition is false while
+	// the resources are migrated from the previously applied namespace, or if
+	// the migration is blocked.
+	ContourNamespaceMigratedConditionType = "NamespaceMigrated"
+
+	// ContourProgressingConditionType indicates that the resources of the
+	// contour are being rolled out, e.g. the Contour and Envoy pods are not
+	// yet ready or the Envoy load balancer is not yet provisioned.
+	ContourProgressingConditionType = "Progressing"
+
+	// ContourDegradedConditionType indicates that the operator failed to
+	// ensure the resources of a component of the contour, or that the
+	// contour failed validation.
+	ContourDegradedConditionType = "Degraded"
+
+	// ContourValidConditionType indicates whether the spec of the contour
+	// is valid. The resources of an invalid contour are not ensured until
+	// the contour, or an object it conflicts with, is changed.
+	ContourValidConditionType = "Valid"
+
+	// ContourCertificatesReadyConditionType indicates whether the xDS TLS
+	// certificates used by Contour and Envoy are issued. The condition is
+	// false while the certificates are pending, or if issuing them failed,
+	// e.g. because the certgen job failed.
+	ContourCertificatesReadyConditionType = "CertificatesReady"
+)
+
+const (
+	// NamespaceComponent is the namespace specified by spec.namespace.name.
+	NamespaceComponent = "Namespace"
+
+	// RBACComponent is the service accounts, roles and role bindings
+	// of Contour, Envoy and the certgen job.
+	RBACComponent = "RBAC"
+
+	// CertGenComponent is the certgen job that generates the TLS
+	// certificates used by Contour and Envoy.
+	CertGenComponent = "CertGen"
+
+	// ContourComponent is the Contour deployment, its configmap and
+	// its service.
+	ContourComponent = "Contour"
+
+	// EnvoyComponent is the Envoy daemonset or deployment.
+	EnvoyComponent = "Envoy"
+
+	// EnvoyServiceComponent is the Envoy service, as specified by
+	// spec.networkPublishing.envoy.
+	EnvoyServiceComponent = "EnvoyService"
+)
+
+// AddressType defines how a network address is represented.
+//
+// +kubebuilder:validation:Enum=IPAddress;Hostname
+type AddressType string
+
+const (
+	// IPAddressType is an IPv4 or IPv6 address.
+	IPAddressType AddressType = "IPAddress"
+
+	// HostnameAddressType is a DNS hostname.
+	HostnameAddressType AddressType = "Hostname"
+)
+
+// Address is a network address Envoy is reachable at.
+type Address struct {
+	// Type is the type of the address, either "IPAddress" or "Hostname".
+	Type AddressType `json:"type"`
+
+	// Value is the IP address or hostname.
+	Value string `json:"value"`
+}
+
+// NodePortStatus is a port allocated on each node for a port of the
+// Envoy service.
+type NodePortStatus struct {
+	// Name is the name of the Envoy service port, i.e. "http" or "https".
+	Name string `json:"name"`
+
+	// PortNumber is the port number allocated on each node.
+	PortNumber int32 `json:"portNumber"`
+}
+
+// CertificateStatus is the observed state of an xDS TLS certificate.
+type CertificateStatus struct {
+	// Name is the name of the secret containing the certificate.
+	Name string `json:"name"`
+
+	// NotAfter is the time the certificate expires.
+	NotAfter metav1.Time `json:"notAfter"`
+}
+
+// ComponentStatus is the observed state of a component of a contour.
+type ComponentStatus struct {
+	// Name is the name of the component. Known components are "Namespace",
+	// "RBAC", "CertGen", "Contour", "Envoy" and "EnvoyService".
+	Name string `json:"name"`
+
+	// Ready is true if the resources of the component were ensured and
+	// are ready.
+	Ready bool `json:"ready"`
+
+	// Reason is a brief CamelCase reason for the readiness of the component.
+	//
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable message with details about the readiness
+	// of the component.
+	//
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ContourStatus defines the observed state of Contour.
+type ContourStatus struct {
+	// AvailableContours is the number of observed available replicas
+	// according to the Contour deployment. The deployment and its pods
+	// will reside in the namespace specified by spec.namespace.name of
+	// the contour.
+	AvailableContours int32 `json:"availableContours"`
+
+	// AvailableEnvoys is the number of observed available pods from
+	// the Envoy daemonset or deployment. The workload and its pods will
+	// reside in the namespace specified by spec.namespace.name of the contour.
+	AvailableEnvoys int32 `json:"availableEnvoys"`
+
+	// ContourImage is the container image reference used by the Contour
+	// deployment.
+	//
+	// +optional
+	ContourImage string `json:"contourImage,omitempty"`
+
+	// EnvoyImage is the container image reference used by the Envoy
+	// daemonset or deployment.
+	//
+	// +optional
+	EnvoyImage string `json:"envoyImage,omitempty"`
+
+	// AppliedNamespace is the namespace the resources of the contour were last
+	// made available in. When spec.namespace.name changes, the resources in
+	// the applied namespace are removed once the contour is available in the
+	// new namespace.
+	//
+	// +optional
+	AppliedNamespace string `json:"appliedNamespace,omitempty"`
+
+	// Addresses are the addresses Envoy is reachable at. When
+	// spec.networkPublishing.envoy.type is LoadBalancerService, the addresses
+	// are the ingress points of the provisioned load balancer, otherwise the
+	// cluster IPs of the Envoy service.
+	//
+	// +optional
+	Addresses []Address `json:"addresses,omitempty"`
+
+	// NodePorts are the ports allocated on each node for the ports of the
+	// Envoy service, when spec.networkPublishing.envoy.type is
+	// NodePortService or LoadBalancerService.
+	//
+	// +optional
+	NodePorts []NodePortStatus `json:"nodePorts,omitempty"`
+
+	// Certificates are the xDS TLS certificates used by Contour and Envoy
+	// and, when spec.xdsTLS.provider is "Operator", the CA that issued them.
+	//
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Certificates []CertificateStatus `json:"certificates,omitempty"`
+
+	// ObservedGeneration is the most recent generation of the contour
+	// observed by the operator.
+	//
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Components is the readiness of each component of the contour, as
+	// observed when ObservedGeneration was reconciled.
+	//
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Components []ComponentStatus `json:"components,omitempty"`
+
+	// Conditions represent the observations of a contour's current state.
+	// Known condition types are "Available", "Progressing", "Degraded",
+	// "Valid" and "NamespaceMigrated".
+	// Reference the condition type for additional details.
+	//
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Contour{}, &ContourList{})
+}
@@ -0,0 +1,164 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/projectcontour/contour-operator/api/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+const (
+	// portsAnnotation is the annotation used to preserve the v1alpha1 container
+	// and node ports of a Contour that can't be represented by v1beta1 ports,
+	// e.g. ports listed in a different order.
+	portsAnnotation = "operator.projectcontour.io/v1alpha1-ports"
+
+	httpPortName  = "http"
+	httpsPortName = "https"
+)
+
+// v1alpha1Ports are the ports of a v1alpha1 Contour.
+type v1alpha1Ports struct {
+	ContainerPorts []v1alpha1.ContainerPort `json:"containerPorts,omitempty"`
+	NodePorts      []v1alpha1.NodePort      `json:"nodePorts,omitempty"`
+}
+
+// ConvertTo converts c to the hub version of Contour.
+func (c *Contour) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.Contour)
+	dst.ObjectMeta = *c.ObjectMeta.DeepCopy()
+	dst.Spec = v1alpha1.ContourSpec{}
+	if err := convert(&c.Spec, &dst.Spec); err != nil {
+		return fmt.Errorf("failed to convert spec of contour %s/%s: %w", c.Namespace, c.Name, err)
+	}
+	dst.Status = v1alpha1.ContourStatus{}
+	if err := convert(&c.Status, &dst.Status); err != nil {
+		return fmt.Errorf("failed to convert status of contour %s/%s: %w", c.Namespace, c.Name, err)
+	}
+
+	ports := c.Spec.NetworkPublishing.Envoy.Ports
+	hubPorts := toV1alpha1Ports(ports)
+	if raw, ok := dst.Annotations[portsAnnotation]; ok {
+		delete(dst.Annotations, portsAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+		// Only restore the preserved ports if they still match ports,
+		// i.e. the ports weren't changed through v1beta1.
+		preserved := v1alpha1Ports{}
+		if err := json.Unmarshal([]byte(raw), &preserved); err == nil &&
+			equality.Semantic.DeepEqual(fromV1alpha1Ports(preserved), ports) {
+			hubPorts = preserved
+		}
+	}
+	dst.Spec.NetworkPublishing.Envoy.ContainerPorts = hubPorts.ContainerPorts
+	dst.Spec.NetworkPublishing.Envoy.NodePorts = hubPorts.NodePorts
+	return nil
+}
+
+// ConvertFrom converts the hub version of Contour to c.
+func (c *Contour) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.Contour)
+	c.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	c.Spec = ContourSpec{}
+	if err := convert(&src.Spec, &c.Spec); err != nil {
+		return fmt.Errorf("failed to convert spec of contour %s/%s: %w", src.Namespace, src.Name, err)
+	}
+	c.Status = ContourStatus{}
+	if err := convert(&src.Status, &c.Status); err != nil {
+		return fmt.Errorf("failed to convert status of contour %s/%s: %w", src.Namespace, src.Name, err)
+	}
+
+	hubPorts := v1alpha1Ports{
+		ContainerPorts: src.Spec.NetworkPublishing.Envoy.ContainerPorts,
+		NodePorts:      src.Spec.NetworkPublishing.Envoy.NodePorts,
+	}
+	ports := fromV1alpha1Ports(hubPorts)
+	c.Spec.NetworkPublishing.Envoy.Ports = ports
+	if !equality.Semantic.DeepEqual(toV1alpha1Ports(ports), hubPorts) {
+		raw, err := json.Marshal(hubPorts)
+		if err != nil {
+			return fmt.Errorf("failed to marshal ports of contour %s/%s: %w", src.Namespace, src.Name, err)
+		}
+		if c.Annotations == nil {
+			c.Annotations = map[string]string{}
+		}
+		c.Annotations[portsAnnotation] = string(raw)
+	}
+	return nil
+}
+
+// convert converts src to dst through their JSON representation. The
+// fields of src and dst with the same JSON name must have the same schema.
+func convert(src, dst interface{}) error {
+	raw, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, dst)
+}
+
+// toV1alpha1Ports returns the v1alpha1 container and node ports of ports.
+func toV1alpha1Ports(ports EnvoyPorts) v1alpha1Ports {
+	var converted v1alpha1Ports
+	if ports.HTTP.ContainerPort != 0 || ports.HTTPS.ContainerPort != 0 {
+		converted.ContainerPorts = []v1alpha1.ContainerPort{
+			{Name: httpPortName, PortNumber: ports.HTTP.ContainerPort},
+			{Name: httpsPortName, PortNumber: ports.HTTPS.ContainerPort},
+		}
+	}
+	if ports.HTTP.NodePort != nil || ports.HTTPS.NodePort != nil {
+		converted.NodePorts = []v1alpha1.NodePort{
+			{Name: httpPortName, PortNumber: copyInt32(ports.HTTP.NodePort)},
+			{Name: httpsPortName, PortNumber: copyInt32(ports.HTTPS.NodePort)},
+		}
+	}
+	return converted
+}
+
+// fromV1alpha1Ports returns the v1beta1 ports of the v1alpha1 container
+// and node ports of ports. Ports not named "http" or "https" are ignored.
+func fromV1alpha1Ports(ports v1alpha1Ports) EnvoyPorts {
+	var converted EnvoyPorts
+	for _, p := range ports.ContainerPorts {
+		switch p.Name {
+		case httpPortName:
+			converted.HTTP.ContainerPort = p.PortNumber
+		case httpsPortName:
+			converted.HTTPS.ContainerPort = p.PortNumber
+		}
+	}
+	for _, p := range ports.NodePorts {
+		switch p.Name {
+		case httpPortName:
+			converted.HTTP.NodePort = copyInt32(p.PortNumber)
+		case httpsPortName:
+			converted.HTTPS.NodePort = copyInt32(p.PortNumber)
+		}
+	}
+	return converted
+}
+
+func copyInt32(i *int32) *int32 {
+	if i == nil {
+		return nil
+	}
+	out := *i
+	return &out
+}
@@ -0,0 +1,283 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/projectcontour/contour-operator/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestConvertHubRoundTrip(t *testing.T) {
+	testCases := []struct {
+		description    string
+		containerPorts []v1alpha1.ContainerPort
+		nodePorts      []v1alpha1.NodePort
+		expectPorts    EnvoyPorts
+		expectPreserve bool
+	}{
+		{
+			description: "unspecified ports",
+		},
+		{
+			description: "default container ports",
+			containerPorts: []v1alpha1.ContainerPort{
+				{Name: "http", PortNumber: int32(8080)},
+				{Name: "https", PortNumber: int32(8443)},
+			},
+			expectPorts: EnvoyPorts{
+				HTTP:  EnvoyPort{ContainerPort: int32(8080)},
+				HTTPS: EnvoyPort{ContainerPort: int32(8443)},
+			},
+		},
+		{
+			description: "container and node ports",
+			containerPorts: []v1alpha1.ContainerPort{
+				{Name: "http", PortNumber: int32(8080)},
+				{Name: "https", PortNumber: int32(8443)},
+			},
+			nodePorts: []v1alpha1.NodePort{
+				{Name: "http", PortNumber: pointer.Int32Ptr(int32(30080))},
+				{Name: "https", PortNumber: pointer.Int32Ptr(int32(30443))},
+			},
+			expectPorts: EnvoyPorts{
+				HTTP:  EnvoyPort{ContainerPort: int32(8080), NodePort: pointer.Int32Ptr(int32(30080))},
+				HTTPS: EnvoyPort{ContainerPort: int32(8443), NodePort: pointer.Int32Ptr(int32(30443))},
+			},
+		},
+		{
+			description: "reordered container ports",
+			containerPorts: []v1alpha1.ContainerPort{
+				{Name: "https", PortNumber: int32(8443)},
+				{Name: "http", PortNumber: int32(8080)},
+			},
+			expectPorts: EnvoyPorts{
+				HTTP:  EnvoyPort{ContainerPort: int32(8080)},
+				HTTPS: EnvoyPort{ContainerPort: int32(8443)},
+			},
+			expectPreserve: true,
+		},
+		{
+			description: "node ports without port numbers",
+			containerPorts: []v1alpha1.ContainerPort{
+				{Name: "http", PortNumber: int32(8080)},
+				{Name: "https", PortNumber: int32(8443)},
+			},
+			nodePorts: []v1alpha1.NodePort{
+				{Name: "http"},
+				{Name: "https"},
+			},
+			expectPorts: EnvoyPorts{
+				HTTP:  EnvoyPort{ContainerPort: int32(8080)},
+				HTTPS: EnvoyPort{ContainerPort: int32(8443)},
+			},
+			expectPreserve: true,
+		},
+		{
+			description: "invalid port names",
+			containerPorts: []v1alpha1.ContainerPort{
+				{Name: "foo", PortNumber: int32(8080)},
+				{Name: "https", PortNumber: int32(8443)},
+			},
+			expectPorts: EnvoyPorts{
+				HTTPS: EnvoyPort{ContainerPort: int32(8443)},
+			},
+			expectPreserve: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		hub := &v1alpha1.Contour{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "test-ns",
+				Name:        "test",
+				Annotations: map[string]string{"foo": "bar"},
+			},
+			Spec: v1alpha1.ContourSpec{
+				Replicas: int32(3),
+				Namespace: v1alpha1.NamespaceSpec{
+					Name:             "test-contour-ns",
+					RemoveOnDeletion: true,
+				},
+				NetworkPublishing: v1alpha1.NetworkPublishing{
+					Envoy: v1alpha1.EnvoyNetworkPublishing{
+						Type: v1alpha1.LoadBalancerServicePublishingType,
+						LoadBalancer: v1alpha1.LoadBalancerStrategy{
+							Scope: v1alpha1.InternalLoadBalancer,
+							ProviderParameters: v1alpha1.ProviderLoadBalancerParameters{
+								Type: v1alpha1.AzureLoadBalancerProvider,
+								Azure: &v1alpha1.AzureLoadBalancerParameters{
+									Subnet: "test-subnet",
+								},
+							},
+						},
+						ContainerPorts:  tc.containerPorts,
+						NodePorts:       tc.nodePorts,
+						SessionAffinity: corev1.ServiceAffinityClientIP,
+					},
+				},
+				Envoy: v1alpha1.EnvoySettings{
+					WorkloadType: v1alpha1.DeploymentEnvoyWorkloadType,
+					Replicas:     int32(4),
+				},
+			},
+			Status: v1alpha1.ContourStatus{
+				AvailableContours: int32(3),
+				Conditions: []metav1.Condition{{
+					Type:   v1alpha1.ContourAvailableConditionType,
+					Status: metav1.ConditionTrue,
+				}},
+			},
+		}
+
+		spoke := &Contour{}
+		if err := spoke.ConvertFrom(hub); err != nil {
+			t.Fatalf("%q: failed to convert from hub: %v", tc.description, err)
+		}
+		if !equality.Semantic.DeepEqual(spoke.Spec.NetworkPublishing.Envoy.Ports, tc.expectPorts) {
+			t.Errorf("%q: expected ports %+v, got %+v", tc.description, tc.expectPorts,
+				spoke.Spec.NetworkPublishing.Envoy.Ports)
+		}
+		if _, ok := spoke.Annotations[portsAnnotation]; ok != tc.expectPreserve {
+			t.Errorf("%q: expected ports annotation to exist: %t", tc.description, tc.expectPreserve)
+		}
+		if spoke.Spec.Replicas != hub.Spec.Replicas || spoke.Spec.NetworkPublishing.Envoy.LoadBalancer.ProviderParameters.Azure == nil {
+			t.Errorf("%q: expected spec to be converted, got %+v", tc.description, spoke.Spec)
+		}
+
+		converted := &v1alpha1.Contour{}
+		if err := spoke.ConvertTo(converted); err != nil {
+			t.Fatalf("%q: failed to convert to hub: %v", tc.description, err)
+		}
+		if !equality.Semantic.DeepEqual(converted, hub) {
+			t.Errorf("%q: expected round trip to return %+v, got %+v", tc.description, hub, converted)
+		}
+	}
+}
+
+func TestConvertSpokeRoundTrip(t *testing.T) {
+	testCases := []struct {
+		description string
+		annotations map[string]string
+		ports       EnvoyPorts
+		expectPorts []v1alpha1.ContainerPort
+	}{
+		{
+			description: "container and node ports",
+			ports: EnvoyPorts{
+				HTTP:  EnvoyPort{ContainerPort: int32(8081), NodePort: pointer.Int32Ptr(int32(30080))},
+				HTTPS: EnvoyPort{ContainerPort: int32(8444)},
+			},
+			expectPorts: []v1alpha1.ContainerPort{
+				{Name: "http", PortNumber: int32(8081)},
+				{Name: "https", PortNumber: int32(8444)},
+			},
+		},
+		{
+			description: "preserved ports",
+			annotations: map[string]string{
+				portsAnnotation: `{"containerPorts":[{"name":"https","portNumber":8443},{"name":"http","portNumber":8080}]}`,
+			},
+			ports: EnvoyPorts{
+				HTTP:  EnvoyPort{ContainerPort: int32(8080)},
+				HTTPS: EnvoyPort{ContainerPort: int32(8443)},
+			},
+			expectPorts: []v1alpha1.ContainerPort{
+				{Name: "https", PortNumber: int32(8443)},
+				{Name: "http", PortNumber: int32(8080)},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		spoke := &Contour{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "test-ns",
+				Name:        "test",
+				Annotations: tc.annotations,
+			},
+			Spec: ContourSpec{
+				Replicas: int32(2),
+				NetworkPublishing: NetworkPublishing{
+					Envoy: EnvoyNetworkPublishing{
+						Type:  NodePortServicePublishingType,
+						Ports: tc.ports,
+					},
+				},
+			},
+		}
+
+		hub := &v1alpha1.Contour{}
+		if err := spoke.ConvertTo(hub); err != nil {
+			t.Fatalf("%q: failed to convert to hub: %v", tc.description, err)
+		}
+		if !equality.Semantic.DeepEqual(hub.Spec.NetworkPublishing.Envoy.ContainerPorts, tc.expectPorts) {
+			t.Errorf("%q: expected container ports %+v, got %+v", tc.description, tc.expectPorts,
+				hub.Spec.NetworkPublishing.Envoy.ContainerPorts)
+		}
+		if _, ok := hub.Annotations[portsAnnotation]; ok {
+			t.Errorf("%q: expected ports annotation to be removed", tc.description)
+		}
+
+		converted := &Contour{}
+		if err := converted.ConvertFrom(hub); err != nil {
+			t.Fatalf("%q: failed to convert from hub: %v", tc.description, err)
+		}
+		if !equality.Semantic.DeepEqual(converted, spoke) {
+			t.Errorf("%q: expected round trip to return %+v, got %+v", tc.description, spoke, converted)
+		}
+	}
+}
+
+func TestConvertChangedPorts(t *testing.T) {
+	// The ports annotation was written for ports 8080/8443, but the ports
+	// were changed through v1beta1 since.
+	spoke := &Contour{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				portsAnnotation: `{"containerPorts":[{"name":"https","portNumber":8443},{"name":"http","portNumber":8080}]}`,
+			},
+		},
+		Spec: ContourSpec{
+			NetworkPublishing: NetworkPublishing{
+				Envoy: EnvoyNetworkPublishing{
+					Ports: EnvoyPorts{
+						HTTP:  EnvoyPort{ContainerPort: int32(8081)},
+						HTTPS: EnvoyPort{ContainerPort: int32(8444)},
+					},
+				},
+			},
+		},
+	}
+	expected := []v1alpha1.ContainerPort{
+		{Name: "http", PortNumber: int32(8081)},
+		{Name: "https", PortNumber: int32(8444)},
+	}
+
+	hub := &v1alpha1.Contour{}
+	if err := spoke.ConvertTo(hub); err != nil {
+		t.Fatalf("failed to convert to hub: %v", err)
+	}
+	if !equality.Semantic.DeepEqual(hub.Spec.NetworkPublishing.Envoy.ContainerPorts, expected) {
+		t.Errorf("expected container ports %+v, got %+v", expected, hub.Spec.NetworkPublishing.Envoy.ContainerPorts)
+	}
+	if hub.Annotations != nil {
+		t.Errorf("expected no annotations, got %v", hub.Annotations)
+	}
+}
@@ -39,6 +39,14 @@ func main() {
 		"address the metric endpoint binds to. It can be set to \"0\" to disable serving metrics.")
 	flag.BoolVar(&opCfg.LeaderElection, "enable-leader-election", operatorconfig.DefaultEnableLeaderElection,
 		"Enable leader election for the operator. Enabling this will ensure there is only one active operator.")
+	flag.BoolVar(&opCfg.EnableWebhooks, "enable-webhooks", operatorconfig.DefaultEnableWebhooks,
+		"Enable the conversion and defaulting webhooks for Contour objects and the validating admission "+
+			"webhooks for Contour, GatewayClass and Gateway objects. The conversion webhook is required to "+
+			"serve the v1beta1 Contour API.")
+	flag.IntVar(&opCfg.WebhookPort, "webhook-port", operatorconfig.DefaultWebhookPort,
+		"The port the webhook server serves at.")
+	flag.StringVar(&opCfg.WebhookCertDir, "webhook-cert-dir", operatorconfig.DefaultWebhookCertDir,
+		"The directory that contains the webhook server key and certificate, named tls.key and tls.crt.")
 	flag.Parse()
 
 	opCfg.LeaderElectionID = operatorconfig.DefaultEnableLeaderElectionID
@@ -0,0 +1,176 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certificate
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	// keySize is the size of the RSA keys of the issued certificates.
+	keySize = 2048
+	// serialNumberBits is the number of random bits of the serial number of
+	// the issued certificates.
+	serialNumberBits = 128
+)
+
+// KeyPair is an X.509 certificate and its private key.
+type KeyPair struct {
+	Cert *x509.Certificate
+	Key  *rsa.PrivateKey
+}
+
+// NewCA returns a self-signed CA named commonName, valid from notBefore for
+// lifetime.
+func NewCA(commonName string, notBefore time.Time, lifetime time.Duration) (*KeyPair, error) {
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(lifetime),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return newKeyPair(template, nil)
+}
+
+// NewCert returns a certificate named commonName for dnsNames, valid from
+// notBefore for lifetime and issued by ca. The certificate is valid for both
+// server and client authentication.
+func NewCert(ca *KeyPair, commonName string, dnsNames []string, notBefore time.Time, lifetime time.Duration) (*KeyPair, error) {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: commonName},
+		DNSNames:    dnsNames,
+		NotBefore:   notBefore,
+		NotAfter:    notBefore.Add(lifetime),
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	return newKeyPair(template, ca)
+}
+
+// newKeyPair generates a key and signs template with it, or with the key of
+// ca if ca is not nil.
+func newKeyPair(template *x509.Certificate, ca *KeyPair) (*KeyPair, error) {
+	key, err := rsa.GenerateKey(rand.Reader, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), serialNumberBits))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	template.SerialNumber = serial
+	parent, signer := template, key
+	if ca != nil {
+		parent, signer = ca.Cert, ca.Key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return &KeyPair{Cert: cert, Key: key}, nil
+}
+
+// Parse parses a key pair from a PEM-encoded certificate and PEM-encoded
+// PKCS #1 RSA private key.
+func Parse(certPEM, keyPEM []byte) (*KeyPair, error) {
+	cert, err := ParseCert(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil || block.Type != "RSA PRIVATE KEY" {
+		return nil, fmt.Errorf("failed to decode rsa private key")
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rsa private key: %w", err)
+	}
+	if pub, ok := cert.PublicKey.(*rsa.PublicKey); !ok || pub.N.Cmp(key.PublicKey.N) != 0 {
+		return nil, fmt.Errorf("private key does not match certificate")
+	}
+	return &KeyPair{Cert: cert, Key: key}, nil
+}
+
+// ParseCert parses a PEM-encoded certificate.
+func ParseCert(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("failed to decode certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// Verify verifies that certPEM and keyPEM are a matching key pair and that the
+// first certificate of certPEM, using the certificates following it as
+// intermediates, chains to a CA of caPEM at the current time.
+func Verify(certPEM, keyPEM, caPEM []byte) error {
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return fmt.Errorf("invalid key pair: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return fmt.Errorf("failed to decode ca certificates")
+	}
+	intermediates := x509.NewCertPool()
+	var leaf *x509.Certificate
+	for i, der := range pair.Certificate {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		if i == 0 {
+			leaf = cert
+		} else {
+			intermediates.AddCert(cert)
+		}
+	}
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	if _, err := leaf.Verify(opts); err != nil {
+		return fmt.Errorf("failed to verify certificate: %w", err)
+	}
+	return nil
+}
+
+// CertPEM returns the PEM-encoded certificate of kp.
+func (kp *KeyPair) CertPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: kp.Cert.Raw})
+}
+
+// KeyPEM returns the PEM-encoded PKCS #1 private key of kp.
+func (kp *KeyPair) KeyPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(kp.Key)})
+}
@@ -0,0 +1,170 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certificate
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+func TestNewCert(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	ca, err := NewCA("test-ca", now, 5*time.Hour)
+	if err != nil {
+		t.Fatalf("failed to issue ca: %v", err)
+	}
+	cert, err := NewCert(ca, "contour", []string{"contour", "contour.projectcontour.svc"}, now, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to issue certificate: %v", err)
+	}
+	if !ca.Cert.IsCA {
+		t.Errorf("expected ca to be a CA")
+	}
+	if cert.Cert.IsCA {
+		t.Errorf("expected certificate not to be a CA")
+	}
+	if !cert.Cert.NotAfter.Equal(now.Add(time.Hour)) {
+		t.Errorf("expected certificate to expire at %s, got %s", now.Add(time.Hour), cert.Cert.NotAfter)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+	for _, usage := range []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth} {
+		opts := x509.VerifyOptions{
+			DNSName:     "contour.projectcontour.svc",
+			Roots:       roots,
+			CurrentTime: now.Add(time.Minute),
+			KeyUsages:   []x509.ExtKeyUsage{usage},
+		}
+		if _, err := cert.Cert.Verify(opts); err != nil {
+			t.Errorf("failed to verify certificate for usage %v: %v", usage, err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	now := time.Now()
+	ca, err := NewCA("test-ca", now, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to issue ca: %v", err)
+	}
+	other, err := NewCA("other-ca", now, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to issue ca: %v", err)
+	}
+
+	testCases := []struct {
+		description string
+		certPEM     []byte
+		keyPEM      []byte
+		expectErr   bool
+	}{
+		{
+			description: "matching key pair",
+			certPEM:     ca.CertPEM(),
+			keyPEM:      ca.KeyPEM(),
+		},
+		{
+			description: "key of another certificate",
+			certPEM:     ca.CertPEM(),
+			keyPEM:      other.KeyPEM(),
+			expectErr:   true,
+		},
+		{
+			description: "key as certificate",
+			certPEM:     ca.KeyPEM(),
+			keyPEM:      ca.KeyPEM(),
+			expectErr:   true,
+		},
+		{
+			description: "empty key",
+			certPEM:     ca.CertPEM(),
+			expectErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		kp, err := Parse(tc.certPEM, tc.keyPEM)
+		switch {
+		case tc.expectErr && err == nil:
+			t.Errorf("%q: expected an error", tc.description)
+		case !tc.expectErr && err != nil:
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		case !tc.expectErr && !kp.Cert.Equal(ca.Cert):
+			t.Errorf("%q: expected parsed certificate to equal the ca certificate", tc.description)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	ca, err := NewCA("test-ca", now, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to issue ca: %v", err)
+	}
+	other, err := NewCA("other-ca", now, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to issue ca: %v", err)
+	}
+	cert, err := NewCert(ca, "contour", []string{"contour"}, now, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to issue certificate: %v", err)
+	}
+
+	testCases := []struct {
+		description string
+		certPEM     []byte
+		keyPEM      []byte
+		caPEM       []byte
+		expectErr   bool
+	}{
+		{
+			description: "certificate issued by ca",
+			certPEM:     cert.CertPEM(),
+			keyPEM:      cert.KeyPEM(),
+			caPEM:       ca.CertPEM(),
+		},
+		{
+			description: "certificate issued by another ca",
+			certPEM:     cert.CertPEM(),
+			keyPEM:      cert.KeyPEM(),
+			caPEM:       other.CertPEM(),
+			expectErr:   true,
+		},
+		{
+			description: "key of another certificate",
+			certPEM:     cert.CertPEM(),
+			keyPEM:      ca.KeyPEM(),
+			caPEM:       ca.CertPEM(),
+			expectErr:   true,
+		},
+		{
+			description: "invalid ca",
+			certPEM:     cert.CertPEM(),
+			keyPEM:      cert.KeyPEM(),
+			caPEM:       []byte("invalid"),
+			expectErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := Verify(tc.certPEM, tc.keyPEM, tc.caPEM)
+		switch {
+		case tc.expectErr && err == nil:
+			t.Errorf("%q: expected an error", tc.description)
+		case !tc.expectErr && err != nil:
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		}
+	}
+}
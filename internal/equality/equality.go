@@ -14,6 +14,8 @@
 package equality
 
 import (
+	"strings"
+
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -21,6 +23,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	gatewayv1alpha1 "sigs.k8s.io/gateway-api/apis/v1alpha1"
 )
 
@@ -36,7 +39,10 @@ func DaemonsetConfigChanged(current, expected *appsv1.DaemonSet) (*appsv1.Daemon
 
 	}
 
-	if !apiequality.Semantic.DeepEqual(current.Spec, expected.Spec) {
+	currentSpec, expectedSpec := current.Spec.DeepCopy(), expected.Spec.DeepCopy()
+	defaultResourceRequests(&currentSpec.Template.Spec)
+	defaultResourceRequests(&expectedSpec.Template.Spec)
+	if !apiequality.Semantic.DeepEqual(*currentSpec, *expectedSpec) {
 		changed = true
 		updated.Spec = expected.Spec
 	}
@@ -85,7 +91,10 @@ func JobConfigChanged(current, expected *batchv1.Job) (*batchv1.Job, bool) {
 		}
 	}
 
-	if !apiequality.Semantic.DeepEqual(current.Spec.Template.Spec, expected.Spec.Template.Spec) {
+	currentPodSpec, expectedPodSpec := current.Spec.Template.Spec.DeepCopy(), expected.Spec.Template.Spec.DeepCopy()
+	defaultResourceRequests(currentPodSpec)
+	defaultResourceRequests(expectedPodSpec)
+	if !apiequality.Semantic.DeepEqual(*currentPodSpec, *expectedPodSpec) {
 		updated = expected
 		changed = true
 	}
@@ -108,7 +117,10 @@ func DeploymentConfigChanged(current, expected *appsv1.Deployment) (*appsv1.Depl
 		changed = true
 	}
 
-	if !apiequality.Semantic.DeepEqual(current.Spec, expected.Spec) {
+	currentSpec, expectedSpec := current.Spec.DeepCopy(), expected.Spec.DeepCopy()
+	defaultResourceRequests(&currentSpec.Template.Spec)
+	defaultResourceRequests(&expectedSpec.Template.Spec)
+	if !apiequality.Semantic.DeepEqual(*currentSpec, *expectedSpec) {
 		updated = expected
 		changed = true
 	}
@@ -120,6 +132,27 @@ func DeploymentConfigChanged(current, expected *appsv1.Deployment) (*appsv1.Depl
 	return updated, true
 }
 
+// defaultResourceRequests sets the resource requests of each container in spec
+// to its limits when the request is unspecified, matching the defaulting
+// performed by the API server. Otherwise, a container that only specifies
+// limits would always differ from its current state.
+func defaultResourceRequests(spec *corev1.PodSpec) {
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			resources := &containers[i].Resources
+			for name, limit := range resources.Limits {
+				if _, ok := resources.Requests[name]; ok {
+					continue
+				}
+				if resources.Requests == nil {
+					resources.Requests = corev1.ResourceList{}
+				}
+				resources.Requests[name] = limit.DeepCopy()
+			}
+		}
+	}
+}
+
 // DeploymentSelectorsDiffer checks if the current and expected Deployment selectors differ.
 func DeploymentSelectorsDiffer(current, expected *appsv1.Deployment) bool {
 	return !apiequality.Semantic.DeepEqual(current.Spec.Selector, expected.Spec.Selector)
@@ -158,6 +191,22 @@ func ClusterIPServiceChanged(current, expected *corev1.Service) (*corev1.Service
 		changed = true
 	}
 
+	if ipFamiliesChanged(current, expected) {
+		if expected.Spec.IPFamilyPolicy != nil {
+			updated.Spec.IPFamilyPolicy = expected.Spec.IPFamilyPolicy
+		}
+		if len(expected.Spec.IPFamilies) > 0 {
+			updated.Spec.IPFamilies = expected.Spec.IPFamilies
+		}
+		changed = true
+	}
+
+	if annotations, labels, differ := managedMetadataChanged(current, expected); differ {
+		updated.Annotations = annotations
+		updated.Labels = labels
+		changed = true
+	}
+
 	if !changed {
 		return nil, false
 	}
@@ -212,13 +261,34 @@ func LoadBalancerServiceChanged(current, expected *corev1.Service) (*corev1.Serv
 		changed = true
 	}
 
+	if current.Spec.LoadBalancerIP != expected.Spec.LoadBalancerIP {
+		updated.Spec.LoadBalancerIP = expected.Spec.LoadBalancerIP
+		changed = true
+	}
+
+	if !apiequality.Semantic.DeepEqual(current.Spec.LoadBalancerSourceRanges, expected.Spec.LoadBalancerSourceRanges) {
+		updated.Spec.LoadBalancerSourceRanges = expected.Spec.LoadBalancerSourceRanges
+		changed = true
+	}
+
 	if !apiequality.Semantic.DeepEqual(current.Spec.Type, expected.Spec.Type) {
 		updated.Spec.Type = expected.Spec.Type
 		changed = true
 	}
 
-	if !apiequality.Semantic.DeepEqual(current.Annotations, expected.Annotations) {
-		updated.Annotations = expected.Annotations
+	if ipFamiliesChanged(current, expected) {
+		if expected.Spec.IPFamilyPolicy != nil {
+			updated.Spec.IPFamilyPolicy = expected.Spec.IPFamilyPolicy
+		}
+		if len(expected.Spec.IPFamilies) > 0 {
+			updated.Spec.IPFamilies = expected.Spec.IPFamilies
+		}
+		changed = true
+	}
+
+	if annotations, labels, differ := managedMetadataChanged(current, expected); differ {
+		updated.Annotations = annotations
+		updated.Labels = labels
 		changed = true
 	}
 
@@ -268,8 +338,19 @@ func NodePortServiceChanged(current, expected *corev1.Service) (*corev1.Service,
 		changed = true
 	}
 
-	if !apiequality.Semantic.DeepEqual(current.Annotations, expected.Annotations) {
-		updated.Annotations = expected.Annotations
+	if ipFamiliesChanged(current, expected) {
+		if expected.Spec.IPFamilyPolicy != nil {
+			updated.Spec.IPFamilyPolicy = expected.Spec.IPFamilyPolicy
+		}
+		if len(expected.Spec.IPFamilies) > 0 {
+			updated.Spec.IPFamilies = expected.Spec.IPFamilies
+		}
+		changed = true
+	}
+
+	if annotations, labels, differ := managedMetadataChanged(current, expected); differ {
+		updated.Annotations = annotations
+		updated.Labels = labels
 		changed = true
 	}
 
@@ -280,6 +361,51 @@ func NodePortServiceChanged(current, expected *corev1.Service) (*corev1.Service,
 	return updated, true
 }
 
+// ipFamiliesChanged returns true if the IP family policy or IP families of
+// expected are set and don't match current. Unset fields are defaulted by
+// the API server, so they are not compared.
+func ipFamiliesChanged(current, expected *corev1.Service) bool {
+	if expected.Spec.IPFamilyPolicy != nil &&
+		!apiequality.Semantic.DeepEqual(current.Spec.IPFamilyPolicy, expected.Spec.IPFamilyPolicy) {
+		return true
+	}
+	return len(expected.Spec.IPFamilies) > 0 &&
+		!apiequality.Semantic.DeepEqual(current.Spec.IPFamilies, expected.Spec.IPFamilies)
+}
+
+// managedMetadataChanged merges the annotations and labels of expected into
+// those of current, returning the merged annotations and labels and true if
+// they differ from current. Keys that were previously set by the operator,
+// as recorded by the managed keys annotations of current, are removed when
+// no longer expected. All other keys of current, e.g. annotations added by
+// cloud controllers, are preserved.
+func managedMetadataChanged(current, expected *corev1.Service) (map[string]string, map[string]string, bool) {
+	annotations, annotationsChanged := mergeManaged(current.Annotations, expected.Annotations,
+		current.Annotations[operatorv1alpha1.ManagedAnnotationsAnnotation])
+	labels, labelsChanged := mergeManaged(current.Labels, expected.Labels,
+		current.Annotations[operatorv1alpha1.ManagedLabelsAnnotation])
+	return annotations, labels, annotationsChanged || labelsChanged
+}
+
+// mergeManaged returns current with the keys of expected set and the keys
+// of the comma-separated managed list removed if not in expected, and true
+// if the result differs from current.
+func mergeManaged(current, expected map[string]string, managed string) (map[string]string, bool) {
+	merged := map[string]string{}
+	for k, v := range current {
+		merged[k] = v
+	}
+	for _, k := range strings.Split(managed, ",") {
+		if _, ok := expected[k]; !ok {
+			delete(merged, k)
+		}
+	}
+	for k, v := range expected {
+		merged[k] = v
+	}
+	return merged, !apiequality.Semantic.DeepEqual(current, merged)
+}
+
 // ContourStatusChanged checks if current and expected match and if not,
 // returns true.
 func ContourStatusChanged(current, expected operatorv1alpha1.ContourStatus) bool {
@@ -291,6 +417,38 @@ func ContourStatusChanged(current, expected operatorv1alpha1.ContourStatus) bool
 		return true
 	}
 
+	if current.ContourImage != expected.ContourImage {
+		return true
+	}
+
+	if current.EnvoyImage != expected.EnvoyImage {
+		return true
+	}
+
+	if current.AppliedNamespace != expected.AppliedNamespace {
+		return true
+	}
+
+	if !apiequality.Semantic.DeepEqual(current.Addresses, expected.Addresses) {
+		return true
+	}
+
+	if !apiequality.Semantic.DeepEqual(current.NodePorts, expected.NodePorts) {
+		return true
+	}
+
+	if !apiequality.Semantic.DeepEqual(current.Certificates, expected.Certificates) {
+		return true
+	}
+
+	if current.ObservedGeneration != expected.ObservedGeneration {
+		return true
+	}
+
+	if !apiequality.Semantic.DeepEqual(current.Components, expected.Components) {
+		return true
+	}
+
 	if !apiequality.Semantic.DeepEqual(current.Conditions, expected.Conditions) {
 		return true
 	}
@@ -438,6 +596,41 @@ func RoleBindingConfigChanged(current, expected *rbacv1.RoleBinding) (*rbacv1.Ro
 	return updated, true
 }
 
+// PodMonitorConfigChanged checks if the current and expected PodMonitor
+// match and if not, returns true and the updated PodMonitor.
+func PodMonitorConfigChanged(current, expected *unstructured.Unstructured) (*unstructured.Unstructured, bool) {
+	return unstructuredConfigChanged(current, expected)
+}
+
+// CertificateConfigChanged checks if the current and expected cert-manager
+// Certificate match and if not, returns true and the updated Certificate.
+func CertificateConfigChanged(current, expected *unstructured.Unstructured) (*unstructured.Unstructured, bool) {
+	return unstructuredConfigChanged(current, expected)
+}
+
+// unstructuredConfigChanged checks if the labels and spec of the current and
+// expected objects match and if not, returns true and the updated object.
+func unstructuredConfigChanged(current, expected *unstructured.Unstructured) (*unstructured.Unstructured, bool) {
+	changed := false
+	updated := current.DeepCopy()
+
+	if !apiequality.Semantic.DeepEqual(current.GetLabels(), expected.GetLabels()) {
+		changed = true
+		updated.SetLabels(expected.GetLabels())
+	}
+
+	if !apiequality.Semantic.DeepEqual(current.Object["spec"], expected.Object["spec"]) {
+		changed = true
+		updated.Object["spec"] = expected.Object["spec"]
+	}
+
+	if !changed {
+		return nil, false
+	}
+
+	return updated, true
+}
+
 // GatewayClassStatusChanged checks if current and expected match and if not,
 // returns true.
 func GatewayClassStatusChanged(current, expected gatewayv1alpha1.GatewayClassStatus) bool {
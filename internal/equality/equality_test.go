@@ -26,6 +26,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -89,6 +90,44 @@ func TestDaemonSetConfigChanged(t *testing.T) {
 			},
 			expect: true,
 		},
+		{
+			description: "if container resources are changed",
+			mutate: func(ds *appsv1.DaemonSet) {
+				ds.Spec.Template.Spec.Containers[0].Resources = corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("500m"),
+					},
+				}
+			},
+			expect: true,
+		},
+		{
+			description: "if node selector is changed",
+			mutate: func(ds *appsv1.DaemonSet) {
+				ds.Spec.Template.Spec.NodeSelector = map[string]string{"node-role.kubernetes.io/edge": ""}
+			},
+			expect: true,
+		},
+		{
+			description: "if tolerations are changed",
+			mutate: func(ds *appsv1.DaemonSet) {
+				ds.Spec.Template.Spec.Tolerations = []corev1.Toleration{
+					{Key: "node-role.kubernetes.io/edge", Operator: corev1.TolerationOpExists},
+				}
+			},
+			expect: true,
+		},
+		{
+			description: "if init container resources are changed",
+			mutate: func(ds *appsv1.DaemonSet) {
+				ds.Spec.Template.Spec.InitContainers[0].Resources = corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("64Mi"),
+					},
+				}
+			},
+			expect: true,
+		},
 		{
 			description: "if container commands are changed",
 			mutate: func(ds *appsv1.DaemonSet) {
@@ -129,7 +168,7 @@ func TestDaemonSetConfigChanged(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.description, func(t *testing.T) {
-			original := objds.DesiredDaemonSet(cntr, testImage, testImage)
+			original := objds.DesiredDaemonSet(cntr, testImage, testImage, nil)
 
 			mutated := original.DeepCopy()
 			tc.mutate(mutated)
@@ -144,6 +183,42 @@ func TestDaemonSetConfigChanged(t *testing.T) {
 	}
 }
 
+func TestConfigChangedDefaultedResourceRequests(t *testing.T) {
+	limits := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("1"),
+		corev1.ResourceMemory: resource.MustParse("256Mi"),
+	}
+	// The API server defaults unspecified requests to limits.
+	defaulted := corev1.ResourceRequirements{
+		Limits:   limits,
+		Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi"), corev1.ResourceCPU: resource.MustParse("1000m")},
+	}
+
+	expectedDs := objds.DesiredDaemonSet(cntr, testImage, testImage, nil)
+	expectedDs.Spec.Template.Spec.Containers[0].Resources = corev1.ResourceRequirements{Limits: limits}
+	currentDs := expectedDs.DeepCopy()
+	currentDs.Spec.Template.Spec.Containers[0].Resources = defaulted
+	if _, changed := equality.DaemonsetConfigChanged(currentDs, expectedDs); changed {
+		t.Error("expect daemonsetConfigChanged to be false for defaulted resource requests, got true")
+	}
+
+	expectedDeploy := objdeploy.DesiredDeployment(cntr, testImage, nil)
+	expectedDeploy.Spec.Template.Spec.Containers[0].Resources = corev1.ResourceRequirements{Limits: limits}
+	currentDeploy := expectedDeploy.DeepCopy()
+	currentDeploy.Spec.Template.Spec.Containers[0].Resources = defaulted
+	if _, changed := equality.DeploymentConfigChanged(currentDeploy, expectedDeploy); changed {
+		t.Error("expect deploymentConfigChanged to be false for defaulted resource requests, got true")
+	}
+
+	expectedJob := objjob.DesiredJob(cntr, testImage)
+	expectedJob.Spec.Template.Spec.Containers[0].Resources = corev1.ResourceRequirements{Limits: limits}
+	currentJob := expectedJob.DeepCopy()
+	currentJob.Spec.Template.Spec.Containers[0].Resources = defaulted
+	if _, changed := equality.JobConfigChanged(currentJob, expectedJob); changed {
+		t.Error("expect jobConfigChanged to be false for defaulted resource requests, got true")
+	}
+}
+
 func TestJobConfigChanged(t *testing.T) {
 	zero := int32(0)
 
@@ -275,6 +350,45 @@ func TestDeploymentConfigChanged(t *testing.T) {
 			},
 			expect: true,
 		},
+		{
+			description: "if affinity is changed",
+			mutate: func(deploy *appsv1.Deployment) {
+				deploy.Spec.Template.Spec.Affinity = nil
+			},
+			expect: true,
+		},
+		{
+			description: "if topology spread constraints are changed",
+			mutate: func(deploy *appsv1.Deployment) {
+				deploy.Spec.Template.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{
+					{
+						MaxSkew:           int32(1),
+						TopologyKey:       "topology.kubernetes.io/zone",
+						WhenUnsatisfiable: corev1.DoNotSchedule,
+					},
+				}
+			},
+			expect: true,
+		},
+		{
+			description: "if priority class name is changed",
+			mutate: func(deploy *appsv1.Deployment) {
+				deploy.Spec.Template.Spec.PriorityClassName = "system-cluster-critical"
+			},
+			expect: true,
+		},
+		{
+			description: "if container resources are changed",
+			mutate: func(deploy *appsv1.Deployment) {
+				deploy.Spec.Template.Spec.Containers[0].Resources = corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("100m"),
+						corev1.ResourceMemory: resource.MustParse("128Mi"),
+					},
+				}
+			},
+			expect: true,
+		},
 		{
 			description: "if the container image is changed",
 			mutate: func(deploy *appsv1.Deployment) {
@@ -311,6 +425,13 @@ func TestDeploymentConfigChanged(t *testing.T) {
 			},
 			expect: true,
 		},
+		{
+			description: "if the config checksum annotation is changed",
+			mutate: func(deploy *appsv1.Deployment) {
+				deploy.Spec.Template.Annotations[objdeploy.ConfigChecksumAnnotation] = "foo"
+			},
+			expect: true,
+		},
 		{
 			description: "if probe values are set to default values",
 			mutate: func(deployment *appsv1.Deployment) {
@@ -330,7 +451,7 @@ func TestDeploymentConfigChanged(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		original := objdeploy.DesiredDeployment(cntr, testImage)
+		original := objdeploy.DesiredDeployment(cntr, testImage, nil)
 		mutated := original.DeepCopy()
 		tc.mutate(mutated)
 		if updated, changed := equality.DeploymentConfigChanged(original, mutated); changed != tc.expect {
@@ -448,6 +569,68 @@ func TestClusterIpServiceChanged(t *testing.T) {
 	}
 }
 
+func TestServiceIPFamiliesChanged(t *testing.T) {
+	singleStack := corev1.IPFamilyPolicySingleStack
+	dualStack := corev1.IPFamilyPolicyRequireDualStack
+	testCases := []struct {
+		description string
+		policy      *corev1.IPFamilyPolicyType
+		families    []corev1.IPFamily
+		mutate      func(service *corev1.Service)
+		expect      bool
+	}{
+		{
+			description: "if unspecified ip families are defaulted",
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.IPFamilyPolicy = &singleStack
+				svc.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol}
+			},
+			expect: false,
+		},
+		{
+			description: "if nothing changed",
+			policy:      &dualStack,
+			families:    []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol},
+			mutate:      func(_ *corev1.Service) {},
+			expect:      false,
+		},
+		{
+			description: "if ip family policy changed",
+			policy:      &dualStack,
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.IPFamilyPolicy = &singleStack
+				svc.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol}
+			},
+			expect: true,
+		},
+		{
+			description: "if ip families changed",
+			families:    []corev1.IPFamily{corev1.IPv6Protocol},
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol}
+			},
+			expect: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		c := cntr.DeepCopy()
+		c.Spec.IPFamilyPolicy = tc.policy
+		c.Spec.IPFamilies = tc.families
+		expected := objsvc.DesiredContourService(c)
+
+		mutated := expected.DeepCopy()
+		tc.mutate(mutated)
+		if updated, changed := equality.ClusterIPServiceChanged(mutated, expected); changed != tc.expect {
+			t.Errorf("%s, expect ClusterIpServiceChanged to be %t, got %t", tc.description, tc.expect, changed)
+		} else if changed {
+			if _, changedAgain := equality.ClusterIPServiceChanged(updated, expected); changedAgain {
+				t.Errorf("%s, ClusterIpServiceChanged does not behave as a fixed point function", tc.description)
+			}
+		}
+	}
+}
+
 func TestLoadBalancerServiceChanged(t *testing.T) {
 	testCases := []struct {
 		description string
@@ -550,6 +733,50 @@ func TestLoadBalancerServiceChanged(t *testing.T) {
 			},
 			expect: true,
 		},
+		{
+			description: "if an annotation was added by a cloud controller",
+			mutate: func(svc *corev1.Service) {
+				svc.Annotations["service.beta.kubernetes.io/aws-load-balancer-id"] = "foo"
+			},
+			expect: false,
+		},
+		{
+			description: "if a label was added by another controller",
+			mutate: func(svc *corev1.Service) {
+				svc.Labels["foo"] = "bar"
+			},
+			expect: false,
+		},
+		{
+			description: "if a managed annotation is no longer desired",
+			mutate: func(svc *corev1.Service) {
+				svc.Annotations["foo"] = "bar"
+				svc.Annotations[operatorv1alpha1.ManagedAnnotationsAnnotation] += ",foo"
+			},
+			expect: true,
+		},
+		{
+			description: "if a managed label is no longer desired",
+			mutate: func(svc *corev1.Service) {
+				svc.Labels["foo"] = "bar"
+				svc.Annotations[operatorv1alpha1.ManagedLabelsAnnotation] += ",foo"
+			},
+			expect: true,
+		},
+		{
+			description: "if load balancer IP changed",
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.LoadBalancerIP = "203.0.113.10"
+			},
+			expect: true,
+		},
+		{
+			description: "if load balancer source ranges changed",
+			mutate: func(svc *corev1.Service) {
+				svc.Spec.LoadBalancerSourceRanges = []string{"10.0.0.0/8"}
+			},
+			expect: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -663,6 +890,71 @@ func TestContourStatusChangedChanged(t *testing.T) {
 			},
 			expect: true,
 		},
+		{
+			description: "if contour image changed",
+			current:     operatorv1alpha1.ContourStatus{},
+			mutate: func(status *operatorv1alpha1.ContourStatus) {
+				status.ContourImage = "docker.io/projectcontour/contour:main"
+			},
+			expect: true,
+		},
+		{
+			description: "if envoy image changed",
+			current:     operatorv1alpha1.ContourStatus{},
+			mutate: func(status *operatorv1alpha1.ContourStatus) {
+				status.EnvoyImage = "docker.io/envoyproxy/envoy:v1.18.3"
+			},
+			expect: true,
+		},
+		{
+			description: "if applied namespace changed",
+			current:     operatorv1alpha1.ContourStatus{},
+			mutate: func(status *operatorv1alpha1.ContourStatus) {
+				status.AppliedNamespace = "projectcontour"
+			},
+			expect: true,
+		},
+		{
+			description: "if an address is added",
+			current:     operatorv1alpha1.ContourStatus{},
+			mutate: func(status *operatorv1alpha1.ContourStatus) {
+				status.Addresses = []operatorv1alpha1.Address{
+					{Type: operatorv1alpha1.HostnameAddressType, Value: "lb.example.com"},
+				}
+			},
+			expect: true,
+		},
+		{
+			description: "if a node port changed",
+			current: operatorv1alpha1.ContourStatus{
+				NodePorts: []operatorv1alpha1.NodePortStatus{{Name: "http", PortNumber: int32(30080)}},
+			},
+			mutate: func(status *operatorv1alpha1.ContourStatus) {
+				status.NodePorts[0].PortNumber = int32(30081)
+			},
+			expect: true,
+		},
+		{
+			description: "if observed generation changed",
+			current:     operatorv1alpha1.ContourStatus{},
+			mutate: func(status *operatorv1alpha1.ContourStatus) {
+				status.ObservedGeneration = int64(2)
+			},
+			expect: true,
+		},
+		{
+			description: "if a component became ready",
+			current: operatorv1alpha1.ContourStatus{
+				Components: []operatorv1alpha1.ComponentStatus{
+					{Name: operatorv1alpha1.EnvoyComponent, Ready: false, Reason: "EnvoyProgressing"},
+				},
+			},
+			mutate: func(status *operatorv1alpha1.ContourStatus) {
+				status.Components[0].Ready = true
+				status.Components[0].Reason = "Available"
+			},
+			expect: true,
+		},
 		{
 			description: "if a condition is added",
 			current:     operatorv1alpha1.ContourStatus{},
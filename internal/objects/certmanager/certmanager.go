@@ -0,0 +1,251 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certmanager
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	"github.com/projectcontour/contour-operator/internal/equality"
+	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+	objsecret "github.com/projectcontour/contour-operator/internal/objects/secret"
+	"github.com/projectcontour/contour-operator/internal/operator/metrics/drift"
+	"github.com/projectcontour/contour-operator/pkg/labels"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// keySize is the size of the RSA keys of the certificates.
+	keySize = int64(2048)
+	// renewFraction is the fraction of the lifetime of a certificate that
+	// remains when cert-manager renews the certificate.
+	renewFraction = 3
+	// readyConditionType is the type of the condition of a Certificate that
+	// is true when the certificate is issued and up to date.
+	readyConditionType = "Ready"
+)
+
+var (
+	// GroupVersionKind is the group, version and kind of a cert-manager
+	// Certificate.
+	GroupVersionKind = schema.GroupVersionKind{
+		Group:   "cert-manager.io",
+		Version: "v1",
+		Kind:    "Certificate",
+	}
+	// GroupVersionResource is the group, version and resource of a
+	// cert-manager Certificate.
+	GroupVersionResource = GroupVersionKind.GroupVersion().WithResource("certificates")
+	// ErrCRDNotFound is the error of ensuring the Certificates of a contour
+	// when the Certificate CRD did not exist when the operator started.
+	ErrCRDNotFound = fmt.Errorf("cert-manager Certificate CRD not found; restart the operator after installing cert-manager")
+)
+
+// EnsureCertificates ensures Certificates exist that issue the xDS TLS
+// certificates of Contour and Envoy for the provided contour from the issuer
+// of spec.xdsTLS.issuerRef.
+func EnsureCertificates(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
+	for _, desired := range DesiredCertificates(contour) {
+		if err := ensureCertificate(ctx, cli, contour, desired); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnsureCertificatesDeleted ensures the Certificates for the provided contour
+// are deleted if Contour owner labels exist. The secrets of the Certificates
+// are kept, since they are replaced by the provider of the certificates.
+func EnsureCertificatesDeleted(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
+	for _, desired := range DesiredCertificates(contour) {
+		current, err := CurrentCertificate(ctx, cli, desired.GetNamespace(), desired.GetName())
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if labels.Exist(current, objcontour.OwnerLabels(contour)) {
+			if err := cli.Delete(ctx, current); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DesiredCertificates returns the desired Certificates of the xDS TLS
+// certificates of Contour and Envoy for the provided contour. Each Certificate
+// is named after its secret.
+func DesiredCertificates(contour *operatorv1alpha1.Contour) []*unstructured.Unstructured {
+	lifetime := objcontour.CertificateLifetime(contour)
+	issuerRef := map[string]interface{}{
+		"group": GroupVersionKind.Group,
+		"kind":  string(operatorv1alpha1.IssuerKindIssuer),
+	}
+	if ref := contour.Spec.XDSTLS.IssuerRef; ref != nil {
+		issuerRef["name"] = ref.Name
+		if len(ref.Kind) > 0 {
+			issuerRef["kind"] = string(ref.Kind)
+		}
+	}
+	var certs []*unstructured.Unstructured
+	for _, c := range objsecret.XDSCertsFor(contour) {
+		var dnsNames []interface{}
+		for _, name := range c.DNSNames {
+			dnsNames = append(dnsNames, name)
+		}
+		cert := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"secretName":  c.SecretName,
+					"commonName":  c.CommonName,
+					"dnsNames":    dnsNames,
+					"duration":    lifetime.String(),
+					"renewBefore": (lifetime / renewFraction).String(),
+					// Contour reads PKCS #1 keys, like the keys generated by certgen.
+					"privateKey": map[string]interface{}{
+						"algorithm":      "RSA",
+						"encoding":       "PKCS1",
+						"size":           keySize,
+						"rotationPolicy": "Always",
+					},
+					"usages":    []interface{}{"digital signature", "key encipherment", "server auth", "client auth"},
+					"issuerRef": issuerRef,
+				},
+			},
+		}
+		cert.SetGroupVersionKind(GroupVersionKind)
+		cert.SetNamespace(contour.Spec.Namespace.Name)
+		cert.SetName(c.SecretName)
+		cert.SetLabels(objcontour.OwnerLabels(contour))
+		certs = append(certs, cert)
+	}
+	return certs
+}
+
+// CurrentCertificate returns the current Certificate for the provided ns/name.
+func CurrentCertificate(ctx context.Context, cli client.Client, ns, name string) (*unstructured.Unstructured, error) {
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(GroupVersionKind)
+	key := types.NamespacedName{
+		Namespace: ns,
+		Name:      name,
+	}
+	if err := cli.Get(ctx, key, current); err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+// CurrentCertificates returns the current Certificates for the provided
+// contour. Certificates that do not exist are skipped, as are all Certificates
+// if the Certificate CRD does not exist.
+func CurrentCertificates(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) ([]*unstructured.Unstructured, error) {
+	var certs []*unstructured.Unstructured
+	for _, desired := range DesiredCertificates(contour) {
+		current, err := CurrentCertificate(ctx, cli, desired.GetNamespace(), desired.GetName())
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			if meta.IsNoMatchError(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to get certificate %s/%s: %w", desired.GetNamespace(), desired.GetName(), err)
+		}
+		certs = append(certs, current)
+	}
+	return certs, nil
+}
+
+// Ready returns true if certs contain the Certificates of the provided contour
+// and all of them are ready, along with a message describing the readiness.
+func Ready(contour *operatorv1alpha1.Contour, certs []*unstructured.Unstructured) (bool, string) {
+	current := map[string]*unstructured.Unstructured{}
+	for _, cert := range certs {
+		current[cert.GetName()] = cert
+	}
+	for _, desired := range DesiredCertificates(contour) {
+		cert, ok := current[desired.GetName()]
+		if !ok {
+			return false, fmt.Sprintf("Certificate %s does not exist.", desired.GetName())
+		}
+		if ready, msg := certificateReady(cert); !ready {
+			return false, fmt.Sprintf("Certificate %s is not ready: %s", cert.GetName(), msg)
+		}
+	}
+	return true, "Certificates are ready."
+}
+
+// certificateReady returns true if the Ready condition of cert is true, along
+// with the message of the condition.
+func certificateReady(cert *unstructured.Unstructured) (bool, string) {
+	conditions, _, _ := unstructured.NestedSlice(cert.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok || cond["type"] != readyConditionType {
+			continue
+		}
+		msg, _ := cond["message"].(string)
+		return cond["status"] == "True", msg
+	}
+	return false, "Certificate has no Ready condition."
+}
+
+// ensureCertificate ensures the desired Certificate exists for the provided
+// contour.
+func ensureCertificate(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, desired *unstructured.Unstructured) error {
+	current, err := CurrentCertificate(ctx, cli, desired.GetNamespace(), desired.GetName())
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return createCertificate(ctx, cli, desired)
+		}
+		return fmt.Errorf("failed to get certificate %s/%s: %w", desired.GetNamespace(), desired.GetName(), err)
+	}
+	if err := updateCertificateIfNeeded(ctx, cli, contour, current, desired); err != nil {
+		return fmt.Errorf("failed to update certificate for contour %s/%s: %w", contour.Namespace, contour.Name, err)
+	}
+	return nil
+}
+
+// createCertificate creates a Certificate resource for the provided cert.
+func createCertificate(ctx context.Context, cli client.Client, cert *unstructured.Unstructured) error {
+	if err := cli.Create(ctx, cert); err != nil {
+		return fmt.Errorf("failed to create certificate %s/%s: %w", cert.GetNamespace(), cert.GetName(), err)
+	}
+	return nil
+}
+
+// updateCertificateIfNeeded updates a Certificate if current does not match
+// desired, using contour to verify the existence of owner labels.
+func updateCertificateIfNeeded(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, current, desired *unstructured.Unstructured) error {
+	if labels.Exist(current, objcontour.OwnerLabels(contour)) {
+		cert, updated := equality.CertificateConfigChanged(current, desired)
+		if updated {
+			if err := cli.Update(ctx, cert); err != nil {
+				return fmt.Errorf("failed to update certificate %s/%s: %w", cert.GetNamespace(), cert.GetName(), err)
+			}
+			drift.ObserveCorrection(cli, cert)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func testContour() *operatorv1alpha1.Contour {
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "test-cm",
+		Namespace:   "test-cm-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	cntr.Spec.XDSTLS = operatorv1alpha1.XDSTLSSettings{
+		Provider:            operatorv1alpha1.CertManagerXDSTLSProvider,
+		CertificateLifetime: &metav1.Duration{Duration: 90 * 24 * time.Hour},
+		IssuerRef: &operatorv1alpha1.IssuerReference{
+			Name: "xds-ca",
+			Kind: operatorv1alpha1.IssuerKindClusterIssuer,
+		},
+	}
+	return cntr
+}
+
+// setReady sets the Ready condition of cert to status with msg.
+func setReady(t *testing.T, cert *unstructured.Unstructured, status, msg string) {
+	t.Helper()
+	cond := map[string]interface{}{"type": "Ready", "status": status, "message": msg}
+	if err := unstructured.SetNestedSlice(cert.Object, []interface{}{cond}, "status", "conditions"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDesiredCertificates(t *testing.T) {
+	cntr := testContour()
+	certs := DesiredCertificates(cntr)
+	if len(certs) != 2 {
+		t.Fatalf("expected 2 certificates, got %d", len(certs))
+	}
+
+	testCases := []struct {
+		description string
+		cert        *unstructured.Unstructured
+		name        string
+		commonName  string
+		dnsNames    []interface{}
+	}{
+		{
+			description: "contour certificate",
+			cert:        certs[0],
+			name:        objcontour.ContourCertsSecretName(cntr),
+			commonName:  "contour",
+			dnsNames: []interface{}{"contour", "test-cm-contour", "test-cm-contour.projectcontour",
+				"test-cm-contour.projectcontour.svc"},
+		},
+		{
+			description: "envoy certificate",
+			cert:        certs[1],
+			name:        objcontour.EnvoyCertsSecretName(cntr),
+			commonName:  "envoy",
+			dnsNames: []interface{}{"envoy", "test-cm-envoy", "test-cm-envoy.projectcontour",
+				"test-cm-envoy.projectcontour.svc"},
+		},
+	}
+
+	for _, tc := range testCases {
+		if tc.cert.GroupVersionKind() != GroupVersionKind {
+			t.Errorf("%q: unexpected group version kind %s", tc.description, tc.cert.GroupVersionKind())
+		}
+		if tc.cert.GetNamespace() != "projectcontour" || tc.cert.GetName() != tc.name {
+			t.Errorf("%q: unexpected name %s/%s", tc.description, tc.cert.GetNamespace(), tc.cert.GetName())
+		}
+		if !apiequality.Semantic.DeepEqual(tc.cert.GetLabels(), objcontour.OwnerLabels(cntr)) {
+			t.Errorf("%q: unexpected labels %v", tc.description, tc.cert.GetLabels())
+		}
+		spec, _, _ := unstructured.NestedMap(tc.cert.Object, "spec")
+		if spec["secretName"] != tc.name {
+			t.Errorf("%q: expected secret name %s, got %v", tc.description, tc.name, spec["secretName"])
+		}
+		if spec["commonName"] != tc.commonName {
+			t.Errorf("%q: expected common name %s, got %v", tc.description, tc.commonName, spec["commonName"])
+		}
+		if !apiequality.Semantic.DeepEqual(spec["dnsNames"], tc.dnsNames) {
+			t.Errorf("%q: expected dns names %v, got %v", tc.description, tc.dnsNames, spec["dnsNames"])
+		}
+		if spec["duration"] != "2160h0m0s" || spec["renewBefore"] != "720h0m0s" {
+			t.Errorf("%q: unexpected duration %v and renew before %v", tc.description, spec["duration"], spec["renewBefore"])
+		}
+		issuerRef := map[string]interface{}{"name": "xds-ca", "kind": "ClusterIssuer", "group": "cert-manager.io"}
+		if !apiequality.Semantic.DeepEqual(spec["issuerRef"], issuerRef) {
+			t.Errorf("%q: expected issuer %v, got %v", tc.description, issuerRef, spec["issuerRef"])
+		}
+	}
+}
+
+func TestEnsureCertificates(t *testing.T) {
+	ctx := context.TODO()
+	cntr := testContour()
+	cli := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+
+	if err := EnsureCertificates(ctx, cli, cntr); err != nil {
+		t.Fatalf("failed to ensure certificates: %v", err)
+	}
+
+	// A change of the issuer is applied.
+	cntr.Spec.XDSTLS.IssuerRef = &operatorv1alpha1.IssuerReference{Name: "other-ca"}
+	if err := EnsureCertificates(ctx, cli, cntr); err != nil {
+		t.Fatalf("failed to ensure certificates: %v", err)
+	}
+	certs, err := CurrentCertificates(ctx, cli, cntr)
+	if err != nil {
+		t.Fatalf("failed to get certificates: %v", err)
+	}
+	if len(certs) != 2 {
+		t.Fatalf("expected 2 certificates, got %d", len(certs))
+	}
+	for _, cert := range certs {
+		name, _, _ := unstructured.NestedString(cert.Object, "spec", "issuerRef", "name")
+		kind, _, _ := unstructured.NestedString(cert.Object, "spec", "issuerRef", "kind")
+		if name != "other-ca" || kind != "Issuer" {
+			t.Errorf("expected certificate %s to reference issuer other-ca, got %s %s", cert.GetName(), kind, name)
+		}
+	}
+
+	if err := EnsureCertificatesDeleted(ctx, cli, cntr); err != nil {
+		t.Fatalf("failed to delete certificates: %v", err)
+	}
+	for _, cert := range certs {
+		if _, err := CurrentCertificate(ctx, cli, cert.GetNamespace(), cert.GetName()); !errors.IsNotFound(err) {
+			t.Errorf("expected certificate %s/%s to be deleted, got %v", cert.GetNamespace(), cert.GetName(), err)
+		}
+	}
+	// Deleting missing certificates is a no-op.
+	if err := EnsureCertificatesDeleted(ctx, cli, cntr); err != nil {
+		t.Errorf("failed to delete missing certificates: %v", err)
+	}
+}
+
+func TestReady(t *testing.T) {
+	cntr := testContour()
+	ready := func() []*unstructured.Unstructured {
+		certs := DesiredCertificates(cntr)
+		for _, cert := range certs {
+			setReady(t, cert, "True", "Certificate is up to date and has not expired")
+		}
+		return certs
+	}
+
+	testCases := []struct {
+		description string
+		certs       func() []*unstructured.Unstructured
+		expected    bool
+	}{
+		{
+			description: "ready certificates",
+			certs:       ready,
+			expected:    true,
+		},
+		{
+			description: "missing certificate",
+			certs: func() []*unstructured.Unstructured {
+				return ready()[:1]
+			},
+		},
+		{
+			description: "certificate without conditions",
+			certs: func() []*unstructured.Unstructured {
+				certs := ready()
+				unstructured.RemoveNestedField(certs[1].Object, "status")
+				return certs
+			},
+		},
+		{
+			description: "certificate not ready",
+			certs: func() []*unstructured.Unstructured {
+				certs := ready()
+				setReady(t, certs[0], "False", "Issuing certificate as Secret does not exist")
+				return certs
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		if actual, msg := Ready(cntr, tc.certs()); actual != tc.expected {
+			t.Errorf("%q: expected ready %t, got %t: %s", tc.description, tc.expected, actual, msg)
+		}
+	}
+}
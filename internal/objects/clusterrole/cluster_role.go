@@ -20,6 +20,7 @@ import (
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
 	"github.com/projectcontour/contour-operator/internal/equality"
 	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+	"github.com/projectcontour/contour-operator/internal/operator/metrics/drift"
 	"github.com/projectcontour/contour-operator/pkg/labels"
 
 	corev1 "k8s.io/api/core/v1"
@@ -188,6 +189,7 @@ func updateClusterRoleIfNeeded(ctx context.Context, cli client.Client, contour *
 			if err := cli.Update(ctx, cr); err != nil {
 				return nil, fmt.Errorf("failed to update cluster role %s: %w", cr.Name, err)
 			}
+			drift.ObserveCorrection(cli, cr)
 			return cr, nil
 		}
 	}
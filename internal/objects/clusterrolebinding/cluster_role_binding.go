@@ -20,6 +20,7 @@ import (
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
 	"github.com/projectcontour/contour-operator/internal/equality"
 	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+	"github.com/projectcontour/contour-operator/internal/operator/metrics/drift"
 	"github.com/projectcontour/contour-operator/pkg/labels"
 
 	corev1 "k8s.io/api/core/v1"
@@ -113,6 +114,7 @@ func updateClusterRoleBindingIfNeeded(ctx context.Context, cli client.Client, co
 			if err := cli.Update(ctx, crb); err != nil {
 				return fmt.Errorf("failed to update cluster role binding %s: %w", crb.Name, err)
 			}
+			drift.ObserveCorrection(cli, crb)
 			return nil
 		}
 	}
@@ -16,7 +16,10 @@ package configmap
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"sort"
 	"text/template"
 
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
@@ -33,13 +36,6 @@ import (
 	gatewayv1alpha1 "sigs.k8s.io/gateway-api/apis/v1alpha1"
 )
 
-const (
-	// ContourCfgMapName is the name of Contour's ConfigMap resource.
-	// [TODO] danehans: Remove and use contour.Name when
-	// https://github.com/projectcontour/contour/issues/2122 is fixed.
-	ContourCfgMapName = "contour"
-)
-
 var contourCfgTemplate = template.Must(template.New("contour.yaml").Parse(`
 #
 # server:
@@ -66,37 +62,46 @@ gateway:
 # Disable HTTPProxy permitInsecure field
 disablePermitInsecure: false
 tls:
-# minimum TLS version that Contour will negotiate
-# minimum-protocol-version: "1.2"
+# minimum TLS version that Contour will negotiate{{if .MinimumProtocolVersion}}
+  minimum-protocol-version: {{printf "%q" .MinimumProtocolVersion}}{{else}}
+# minimum-protocol-version: "1.2"{{end}}
 # TLS ciphers to be supported by Envoy TLS listeners when negotiating
-# TLS 1.2.
+# TLS 1.2.{{if .CipherSuites}}
+  cipher-suites:{{range .CipherSuites}}
+  - {{printf "%q" .}}{{end}}{{else}}
 # cipher-suites:
 # - '[ECDHE-ECDSA-AES128-GCM-SHA256|ECDHE-ECDSA-CHACHA20-POLY1305]'
 # - '[ECDHE-RSA-AES128-GCM-SHA256|ECDHE-RSA-CHACHA20-POLY1305]'
 # - 'ECDHE-ECDSA-AES256-GCM-SHA384'
-# - 'ECDHE-RSA-AES256-GCM-SHA384'
+# - 'ECDHE-RSA-AES256-GCM-SHA384'{{end}}
 # Defines the Kubernetes name/namespace matching a secret to use
 # as the fallback certificate when requests which don't match the
 # SNI defined for a vhost.
-  fallback-certificate:
+  fallback-certificate:{{with .FallbackCertificate}}
+    name: {{.Name}}
+    namespace: {{.Namespace}}{{else}}
 #   name: fallback-secret-name
-#   namespace: projectcontour
+#   namespace: projectcontour{{end}}
   envoy-client-certificate:
 #   name: envoy-client-cert-secret-name
 #   namespace: projectcontour
-# The following config shows the defaults for the leader election.
+# The following config shows the defaults for the leader election.{{if .LeaderElectionName}}
+leaderelection:
+  configmap-name: {{.LeaderElectionName}}{{else}}
 # leaderelection:
-#   configmap-name: leader-elect
+#   configmap-name: leader-elect{{end}}
 #   configmap-namespace: projectcontour
 ### Logging options
 # Default setting
-accesslog-format: envoy
+accesslog-format: {{if .AccessLogFormat}}{{.AccessLogFormat}}{{else}}envoy{{end}}
 # To enable JSON logging in Envoy
 # accesslog-format: json
 # The default fields that will be logged are specified below.
 # To customize this list, just add or remove entries.
 # The canonical list is available at
-# https://godoc.org/github.com/projectcontour/contour/internal/envoy#JSONFields
+# https://godoc.org/github.com/projectcontour/contour/internal/envoy#JSONFields{{if .JSONFields}}
+json-fields:{{range .JSONFields}}
+  - {{printf "%q" .}}{{end}}{{else}}
 # json-fields:
 #   - "@timestamp"
 #   - "authority"
@@ -118,39 +123,56 @@ accesslog-format: envoy
 #   - "upstream_local_address"
 #   - "upstream_service_time"
 #   - "user_agent"
-#   - "x_forwarded_for"
-#
+#   - "x_forwarded_for"{{end}}
+#{{if .DefaultHTTPVersions}}
+default-http-versions:{{range .DefaultHTTPVersions}}
+- {{printf "%q" .}}{{end}}{{else}}
 # default-http-versions:
 # - "HTTP/2"
-# - "HTTP/1.1"
+# - "HTTP/1.1"{{end}}
 #
-# The following shows the default proxy timeout settings.
+# The following shows the default proxy timeout settings.{{with .Timeouts}}
+timeouts:{{if .RequestTimeout}}
+  request-timeout: {{.RequestTimeout}}{{end}}{{if .ConnectionIdleTimeout}}
+  connection-idle-timeout: {{.ConnectionIdleTimeout}}{{end}}{{if .StreamIdleTimeout}}
+  stream-idle-timeout: {{.StreamIdleTimeout}}{{end}}{{if .MaxConnectionDuration}}
+  max-connection-duration: {{.MaxConnectionDuration}}{{end}}{{if .DelayedCloseTimeout}}
+  delayed-close-timeout: {{.DelayedCloseTimeout}}{{end}}{{if .ConnectionShutdownGracePeriod}}
+  connection-shutdown-grace-period: {{.ConnectionShutdownGracePeriod}}{{end}}{{else}}
 # timeouts:
 #   request-timeout: infinity
 #   connection-idle-timeout: 60s
 #   stream-idle-timeout: 5m
 #   max-connection-duration: infinity
 #   delayed-close-timeout: 1s
-#   connection-shutdown-grace-period: 5s
+#   connection-shutdown-grace-period: 5s{{end}}
 #
-# Envoy cluster settings.
+# Envoy cluster settings.{{if .DNSLookupFamily}}
+cluster:
+#   configure the cluster dns lookup family
+#   valid options are: auto (default), v4, v6
+  dns-lookup-family: {{.DNSLookupFamily}}{{else}}
 # cluster:
 #   configure the cluster dns lookup family
 #   valid options are: auto (default), v4, v6
-#   dns-lookup-family: auto
+#   dns-lookup-family: auto{{end}}
 #
-# Envoy network settings.
+# Envoy network settings.{{with .NumTrustedHops}}
+network:
+#   Configure the number of additional ingress proxy hops from the
+#   right side of the x-forwarded-for HTTP header to trust.
+  num-trusted-hops: {{.}}{{else}}
 # network:
 #   Configure the number of additional ingress proxy hops from the
 #   right side of the x-forwarded-for HTTP header to trust.
-#   num-trusted-hops: 0
+#   num-trusted-hops: 0{{end}}
 `))
 
 // Config contains everything needed to manage a ConfigMap.
 type Config struct {
 	// Namespace is the namespace of the ConfigMap.
 	Namespace string
-	// Name is the name of the ConfigMap. Defaults to "contour".
+	// Name is the name of the ConfigMap.
 	Name string
 	// Labels are labels to apply to the ConfigMap.
 	Labels map[string]string
@@ -164,25 +186,66 @@ type contourConfig struct {
 	GatewayNamespace string
 	// GatewayName is the Gateway name Contour should watch.
 	GatewayName string
+	// LeaderElectionName is the name of the ConfigMap Contour uses
+	// for leader election.
+	LeaderElectionName string
+	// MinimumProtocolVersion is the minimum TLS version Envoy negotiates.
+	MinimumProtocolVersion string
+	// CipherSuites are the TLS 1.2 ciphers supported by Envoy listeners.
+	CipherSuites []string
+	// FallbackCertificate is a reference to the fallback certificate secret.
+	FallbackCertificate *operatorv1alpha1.NamespacedName
+	// AccessLogFormat is the format of Envoy access logs.
+	AccessLogFormat operatorv1alpha1.AccessLogFormat
+	// JSONFields are the fields of JSON Envoy access logs.
+	JSONFields []string
+	// DefaultHTTPVersions are the HTTP versions Envoy offers to upstreams.
+	DefaultHTTPVersions []operatorv1alpha1.HTTPVersion
+	// Timeouts are the timeouts of Envoy connections and requests.
+	Timeouts *operatorv1alpha1.TimeoutConfig
+	// DNSLookupFamily is the DNS lookup family of Envoy clusters.
+	DNSLookupFamily operatorv1alpha1.DNSLookupFamily
+	// NumTrustedHops is the number of trusted x-forwarded-for hops.
+	NumTrustedHops *int32
 }
 
-// NewConfig returns a Config with default fields set.
-func NewConfig() *Config {
-	return &Config{Name: ContourCfgMapName}
+// NewConfig returns a Config with default fields set for contour, using
+// spec.config of contour for Contour configuration parameters.
+func NewConfig(contour *operatorv1alpha1.Contour) *Config {
+	spec := contour.Spec.Config
+	cfg := &Config{
+		Name: objcontour.ConfigMapName(contour),
+		Contour: contourConfig{
+			LeaderElectionName:  objcontour.LeaderElectionName(contour),
+			AccessLogFormat:     spec.AccessLogFormat,
+			JSONFields:          spec.JSONFields,
+			DefaultHTTPVersions: spec.DefaultHTTPVersions,
+			Timeouts:            spec.Timeouts,
+			DNSLookupFamily:     objcontour.DNSLookupFamily(contour),
+			NumTrustedHops:      spec.NumTrustedHops,
+		},
+	}
+	if spec.TLS != nil {
+		cfg.Contour.MinimumProtocolVersion = spec.TLS.MinimumProtocolVersion
+		cfg.Contour.CipherSuites = spec.TLS.CipherSuites
+		cfg.Contour.FallbackCertificate = spec.TLS.FallbackCertificate
+	}
+	return cfg
 }
 
 // NewCfgForContour returns a ConfigMap Config with default fields set for contour.
 func NewCfgForContour(contour *operatorv1alpha1.Contour) *Config {
-	cfg := NewConfig()
+	cfg := NewConfig(contour)
 	cfg.Namespace = contour.Spec.Namespace.Name
 	labels := objcontour.OwnerLabels(contour)
 	cfg.Labels = labels
 	return cfg
 }
 
-// NewCfgForGateway returns a ConfigMap Config with default fields set for gw.
-func NewCfgForGateway(gw *gatewayv1alpha1.Gateway) *Config {
-	cfg := NewConfig()
+// NewCfgForGateway returns a ConfigMap Config with default fields set for gw
+// managed by contour.
+func NewCfgForGateway(contour *operatorv1alpha1.Contour, gw *gatewayv1alpha1.Gateway) *Config {
+	cfg := NewConfig(contour)
 	cfg.Namespace = gw.Namespace
 	labels := objgw.OwnerLabels(gw)
 	cfg.Labels = labels
@@ -230,6 +293,24 @@ func Delete(ctx context.Context, cli client.Client, cfg *Config) error {
 	return nil
 }
 
+// Checksum returns a hex-encoded SHA-256 checksum of the data of cm. The
+// checksum is stable across map iteration order.
+func Checksum(cm *corev1.ConfigMap) string {
+	keys := make([]string, 0, len(cm.Data))
+	for k := range cm.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		h.Write([]byte(cm.Data[k]))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 // current gets the ConfigMap for the provided cfg from the api server.
 func current(ctx context.Context, cli client.Client, cfg *Config) (*corev1.ConfigMap, error) {
 	current := &corev1.ConfigMap{}
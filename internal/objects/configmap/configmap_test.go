@@ -15,11 +15,13 @@ package configmap
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
 	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
 
+	corev1 "k8s.io/api/core/v1"
 	gatewayv1alpha1 "sigs.k8s.io/gateway-api/apis/v1alpha1"
 )
 
@@ -66,8 +68,8 @@ tls:
 #   name: envoy-client-cert-secret-name
 #   namespace: projectcontour
 # The following config shows the defaults for the leader election.
-# leaderelection:
-#   configmap-name: leader-elect
+leaderelection:
+  configmap-name: test-contour-configmap-leader-elect
 #   configmap-namespace: projectcontour
 ### Logging options
 # Default setting
@@ -186,8 +188,8 @@ tls:
 #   name: envoy-client-cert-secret-name
 #   namespace: projectcontour
 # The following config shows the defaults for the leader election.
-# leaderelection:
-#   configmap-name: leader-elect
+leaderelection:
+  configmap-name: test-contour-gateway-leader-elect
 #   configmap-namespace: projectcontour
 ### Logging options
 # Default setting
@@ -246,7 +248,13 @@ accesslog-format: envoy
 #   right side of the x-forwarded-for HTTP header to trust.
 #   num-trusted-hops: 0
 `
-	gwCfg := NewCfgForGateway(&gatewayv1alpha1.Gateway{})
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "test-contour-gateway",
+		Namespace:   "test-contour-gateway-ns",
+		SpecNs:      "bar",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	gwCfg := NewCfgForGateway(cntr, &gatewayv1alpha1.Gateway{})
 	gwCfg.Contour.GatewayNamespace = "bar"
 	gwCfg.Contour.GatewayName = "foo"
 	if cm, err := desired(gwCfg); err != nil {
@@ -255,3 +263,151 @@ accesslog-format: envoy
 		t.Errorf("unexpected contour.yaml; got:\n%s\nexpected:\n%s\n", cm.Data["contour.yaml"], expected)
 	}
 }
+
+func TestDesiredConfigmapWithConfig(t *testing.T) {
+	name := "test-contour-config"
+	cntr := objcontour.New(objcontour.Config{
+		Name:        name,
+		Namespace:   fmt.Sprintf("%s-ns", name),
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	hops := int32(1)
+	cntr.Spec.Config = operatorv1alpha1.ContourConfig{
+		TLS: &operatorv1alpha1.TLSConfig{
+			MinimumProtocolVersion: "1.3",
+			CipherSuites: []string{
+				"[ECDHE-ECDSA-AES128-GCM-SHA256|ECDHE-ECDSA-CHACHA20-POLY1305]",
+				"ECDHE-RSA-AES256-GCM-SHA384",
+			},
+			FallbackCertificate: &operatorv1alpha1.NamespacedName{
+				Name:      "fallback-secret",
+				Namespace: "fallback-ns",
+			},
+		},
+		AccessLogFormat:     operatorv1alpha1.JSONAccessLogFormat,
+		JSONFields:          []string{"@timestamp", "method", "x_trace_id=%REQ(X-TRACE-ID)%"},
+		DefaultHTTPVersions: []operatorv1alpha1.HTTPVersion{operatorv1alpha1.HTTPVersion1},
+		Timeouts: &operatorv1alpha1.TimeoutConfig{
+			RequestTimeout:        "30s",
+			MaxConnectionDuration: "infinity",
+		},
+		DNSLookupFamily: operatorv1alpha1.IPv4DNSLookupFamily,
+		NumTrustedHops:  &hops,
+	}
+	expected := []string{`
+tls:
+# minimum TLS version that Contour will negotiate
+  minimum-protocol-version: "1.3"
+# TLS ciphers to be supported by Envoy TLS listeners when negotiating
+# TLS 1.2.
+  cipher-suites:
+  - "[ECDHE-ECDSA-AES128-GCM-SHA256|ECDHE-ECDSA-CHACHA20-POLY1305]"
+  - "ECDHE-RSA-AES256-GCM-SHA384"
+`, `
+  fallback-certificate:
+    name: fallback-secret
+    namespace: fallback-ns
+`, `
+accesslog-format: json
+`, `
+json-fields:
+  - "@timestamp"
+  - "method"
+  - "x_trace_id=%REQ(X-TRACE-ID)%"
+#
+default-http-versions:
+- "HTTP/1.1"
+#
+`, `
+timeouts:
+  request-timeout: 30s
+  max-connection-duration: infinity
+#
+`, `
+cluster:
+#   configure the cluster dns lookup family
+#   valid options are: auto (default), v4, v6
+  dns-lookup-family: v4
+`, `
+network:
+#   Configure the number of additional ingress proxy hops from the
+#   right side of the x-forwarded-for HTTP header to trust.
+  num-trusted-hops: 1
+`}
+
+	gw := &gatewayv1alpha1.Gateway{}
+	gw.Namespace = "test-gateway-ns"
+	gw.Name = "test-gateway"
+	for _, cmCfg := range []*Config{NewCfgForContour(cntr), NewCfgForGateway(cntr, gw)} {
+		cm, err := desired(cmCfg)
+		if err != nil {
+			t.Fatalf("invalid configmap: %v", err)
+		}
+		for _, e := range expected {
+			if !strings.Contains(cm.Data["contour.yaml"], e) {
+				t.Errorf("contour.yaml of configmap %s/%s is missing:\n%s\ngot:\n%s\n", cm.Namespace, cm.Name, e,
+					cm.Data["contour.yaml"])
+			}
+		}
+	}
+}
+
+func TestDesiredConfigmapDNSLookupFamily(t *testing.T) {
+	singleStack := corev1.IPFamilyPolicySingleStack
+	dualStack := corev1.IPFamilyPolicyPreferDualStack
+	testCases := []struct {
+		description string
+		policy      *corev1.IPFamilyPolicyType
+		families    []corev1.IPFamily
+		family      operatorv1alpha1.DNSLookupFamily
+		expected    string
+	}{
+		{
+			description: "unspecified ip families",
+			expected:    "#   dns-lookup-family: auto\n",
+		},
+		{
+			description: "ipv6 single-stack",
+			policy:      &singleStack,
+			families:    []corev1.IPFamily{corev1.IPv6Protocol},
+			expected:    "  dns-lookup-family: v6\n",
+		},
+		{
+			description: "ipv4 ip family",
+			families:    []corev1.IPFamily{corev1.IPv4Protocol},
+			expected:    "  dns-lookup-family: v4\n",
+		},
+		{
+			description: "dual-stack",
+			policy:      &dualStack,
+			families:    []corev1.IPFamily{corev1.IPv6Protocol},
+			expected:    "#   dns-lookup-family: auto\n",
+		},
+		{
+			description: "user-specified dns lookup family",
+			families:    []corev1.IPFamily{corev1.IPv6Protocol},
+			family:      operatorv1alpha1.AutoDNSLookupFamily,
+			expected:    "  dns-lookup-family: auto\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		cntr := objcontour.New(objcontour.Config{
+			Name:        "test-dns-lookup-family",
+			Namespace:   "test-dns-lookup-family-ns",
+			SpecNs:      "projectcontour",
+			NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+		})
+		cntr.Spec.IPFamilyPolicy = tc.policy
+		cntr.Spec.IPFamilies = tc.families
+		cntr.Spec.Config.DNSLookupFamily = tc.family
+		cm, err := desired(NewCfgForContour(cntr))
+		if err != nil {
+			t.Fatalf("%q: failed to build configmap: %v", tc.description, err)
+		}
+		if !strings.Contains(cm.Data["contour.yaml"], tc.expected) {
+			t.Errorf("%q: expected configmap data to contain %q, got:\n%s", tc.description, tc.expected, cm.Data["contour.yaml"])
+		}
+	}
+}
@@ -16,9 +16,12 @@ package contour
 import (
 	"context"
 	"fmt"
+	"time"
 
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	objcfg "github.com/projectcontour/contour-operator/internal/objects/sharedconfig"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
@@ -35,6 +38,11 @@ type Config struct {
 	NetworkType  operatorv1alpha1.NetworkPublishingType
 	NodePorts    []operatorv1alpha1.NodePort
 	GatewayClass *string
+	// EnvoyWorkloadType is the type of workload used to run Envoy.
+	EnvoyWorkloadType operatorv1alpha1.EnvoyWorkloadType
+	// EnvoyReplicas is the number of Envoy replicas when EnvoyWorkloadType
+	// is "Deployment".
+	EnvoyReplicas int32
 }
 
 // New makes a Contour object using the provided ns/name for the object's
@@ -57,16 +65,20 @@ func New(cfg Config) *operatorv1alpha1.Contour {
 					Type: cfg.NetworkType,
 					ContainerPorts: []operatorv1alpha1.ContainerPort{
 						{
-							Name:       "http",
-							PortNumber: int32(8080),
+							Name:       DefaultHTTPPortName,
+							PortNumber: objcfg.EnvoyInsecureContainerPort,
 						},
 						{
-							Name:       "https",
-							PortNumber: int32(8443),
+							Name:       DefaultHTTPSPortName,
+							PortNumber: objcfg.EnvoySecureContainerPort,
 						},
 					},
 				},
 			},
+			Envoy: operatorv1alpha1.EnvoySettings{
+				WorkloadType: cfg.EnvoyWorkloadType,
+				Replicas:     cfg.EnvoyReplicas,
+			},
 		},
 	}
 	if cfg.NetworkType == operatorv1alpha1.NodePortServicePublishingType && len(cfg.NodePorts) > 0 {
@@ -91,6 +103,14 @@ func CurrentContour(ctx context.Context, cli client.Client, ns, name string) (*o
 	return cntr, nil
 }
 
+// InNamespace returns a copy of contour with spec.namespace.name set to ns,
+// e.g. to manage the resources of contour in a previously applied namespace.
+func InNamespace(contour *operatorv1alpha1.Contour, ns string) *operatorv1alpha1.Contour {
+	cntr := contour.DeepCopy()
+	cntr.Spec.Namespace.Name = ns
+	return cntr
+}
+
 // OtherContoursExist lists Contour objects in all namespaces, returning the list
 // and true if any exist other than contour.
 func OtherContoursExist(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) (bool, *operatorv1alpha1.ContourList, error) {
@@ -125,6 +145,94 @@ func OtherContoursExistInSpecNs(ctx context.Context, cli client.Client, contour
 	return false, nil
 }
 
+// ContourImage returns the Contour container image reference of contour,
+// using defaultImage if spec.contour.image is unset.
+func ContourImage(contour *operatorv1alpha1.Contour, defaultImage string) string {
+	if len(contour.Spec.Contour.Image) > 0 {
+		return contour.Spec.Contour.Image
+	}
+	return defaultImage
+}
+
+// EnvoyImage returns the Envoy container image reference of contour,
+// using defaultImage if spec.envoy.image is unset.
+func EnvoyImage(contour *operatorv1alpha1.Contour, defaultImage string) string {
+	if len(contour.Spec.Envoy.Image) > 0 {
+		return contour.Spec.Envoy.Image
+	}
+	return defaultImage
+}
+
+// CertificateLifetime returns the lifetime of the xDS TLS certificates issued
+// by the operator for contour, using DefaultCertificateLifetime if
+// spec.xdsTLS.certificateLifetime is unset.
+func CertificateLifetime(contour *operatorv1alpha1.Contour) time.Duration {
+	if lifetime := contour.Spec.XDSTLS.CertificateLifetime; lifetime != nil {
+		return lifetime.Duration
+	}
+	return DefaultCertificateLifetime
+}
+
+// IPv6Enabled returns true if the Services of contour may use the IPv6 family,
+// i.e. spec.ipFamilies contains IPv6 or spec.ipFamilyPolicy is dual-stack.
+func IPv6Enabled(contour *operatorv1alpha1.Contour) bool {
+	for _, family := range contour.Spec.IPFamilies {
+		if family == corev1.IPv6Protocol {
+			return true
+		}
+	}
+	policy := contour.Spec.IPFamilyPolicy
+	return policy != nil && *policy != corev1.IPFamilyPolicySingleStack
+}
+
+// BindAddress returns the wildcard address Contour and Envoy listen on for
+// contour. The IPv6 wildcard address also accepts IPv4 connections.
+func BindAddress(contour *operatorv1alpha1.Contour) string {
+	if IPv6Enabled(contour) {
+		return "::"
+	}
+	return "0.0.0.0"
+}
+
+// DNSLookupFamily returns the DNS lookup family of Envoy clusters for
+// contour, using spec.config.dnsLookupFamily if set. Otherwise, the family
+// is derived from single-stack spec.ipFamilies. An empty string is
+// returned to use Contour's default.
+func DNSLookupFamily(contour *operatorv1alpha1.Contour) operatorv1alpha1.DNSLookupFamily {
+	if len(contour.Spec.Config.DNSLookupFamily) > 0 {
+		return contour.Spec.Config.DNSLookupFamily
+	}
+	if policy := contour.Spec.IPFamilyPolicy; policy != nil && *policy != corev1.IPFamilyPolicySingleStack {
+		return ""
+	}
+	if len(contour.Spec.IPFamilies) != 1 {
+		return ""
+	}
+	if contour.Spec.IPFamilies[0] == corev1.IPv6Protocol {
+		return operatorv1alpha1.IPv6DNSLookupFamily
+	}
+	return operatorv1alpha1.IPv4DNSLookupFamily
+}
+
+// OtherContoursWithNameExistInSpecNs lists Contour objects, returning true if any
+// Contour other than contour has the same name and spec.namespace.name as contour.
+func OtherContoursWithNameExistInSpecNs(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) (bool, error) {
+	contours := &operatorv1alpha1.ContourList{}
+	if err := cli.List(ctx, contours); err != nil {
+		return false, fmt.Errorf("failed to list contours: %w", err)
+	}
+	for _, c := range contours.Items {
+		if c.Namespace == contour.Namespace {
+			// Contour names are unique within a namespace.
+			continue
+		}
+		if c.Name == contour.Name && c.Spec.Namespace.Name == contour.Spec.Namespace.Name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // OwningSelector returns a label selector using "contour.operator.projectcontour.io/owning-contour-name"
 // and "contour.operator.projectcontour.io/owning-contour-namespace" labels.
 func OwningSelector(contour *operatorv1alpha1.Contour) *metav1.LabelSelector {
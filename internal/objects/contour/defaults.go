@@ -0,0 +1,96 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contour
+
+import (
+	"time"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	objcfg "github.com/projectcontour/contour-operator/internal/objects/sharedconfig"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// DefaultReplicas is the default number of Contour replicas.
+	DefaultReplicas = int32(2)
+	// DefaultEnvoyReplicas is the default number of Envoy replicas when
+	// Envoy runs as a Deployment.
+	DefaultEnvoyReplicas = int32(2)
+	// DefaultNamespace is the default namespace of Contour and Envoy resources.
+	DefaultNamespace = "projectcontour"
+	// DefaultHTTPPortName is the default name of Envoy's insecure container port.
+	DefaultHTTPPortName = "http"
+	// DefaultHTTPSPortName is the default name of Envoy's secure container port.
+	DefaultHTTPSPortName = "https"
+	// DefaultCertificateLifetime is the default lifetime of the xDS TLS
+	// certificates issued by the operator.
+	DefaultCertificateLifetime = 365 * 24 * time.Hour
+)
+
+// Default sets unset fields of contour's spec to their default values. The
+// defaults match the defaults of the Contour CRD schema, so the result is
+// the spec the operator deploys contour with.
+func Default(contour *operatorv1alpha1.Contour) {
+	spec := &contour.Spec
+	if spec.Replicas == 0 {
+		spec.Replicas = DefaultReplicas
+	}
+	if len(spec.Namespace.Name) == 0 {
+		spec.Namespace.Name = DefaultNamespace
+	}
+	if len(spec.Envoy.WorkloadType) == 0 {
+		spec.Envoy.WorkloadType = operatorv1alpha1.DaemonSetEnvoyWorkloadType
+	}
+	if spec.Envoy.Replicas == 0 {
+		spec.Envoy.Replicas = DefaultEnvoyReplicas
+	}
+	if len(spec.XDSTLS.Provider) == 0 {
+		spec.XDSTLS.Provider = operatorv1alpha1.CertGenXDSTLSProvider
+	}
+	if issuer := spec.XDSTLS.IssuerRef; issuer != nil && len(issuer.Kind) == 0 {
+		issuer.Kind = operatorv1alpha1.IssuerKindIssuer
+	}
+
+	envoy := &spec.NetworkPublishing.Envoy
+	if len(envoy.Type) == 0 {
+		envoy.Type = operatorv1alpha1.LoadBalancerServicePublishingType
+	}
+	if len(envoy.ContainerPorts) == 0 {
+		envoy.ContainerPorts = []operatorv1alpha1.ContainerPort{
+			{
+				Name:       DefaultHTTPPortName,
+				PortNumber: objcfg.EnvoyInsecureContainerPort,
+			},
+			{
+				Name:       DefaultHTTPSPortName,
+				PortNumber: objcfg.EnvoySecureContainerPort,
+			},
+		}
+	}
+	if len(envoy.SessionAffinity) == 0 {
+		envoy.SessionAffinity = corev1.ServiceAffinityNone
+	}
+
+	lb := &envoy.LoadBalancer
+	if len(lb.Scope) == 0 {
+		lb.Scope = operatorv1alpha1.ExternalLoadBalancer
+	}
+	if len(lb.ProviderParameters.Type) == 0 {
+		lb.ProviderParameters.Type = operatorv1alpha1.AWSLoadBalancerProvider
+	}
+	if aws := lb.ProviderParameters.AWS; aws != nil && len(aws.Type) == 0 {
+		aws.Type = operatorv1alpha1.AWSClassicLoadBalancer
+	}
+}
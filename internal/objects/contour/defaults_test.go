@@ -0,0 +1,129 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contour
+
+import (
+	"testing"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+)
+
+func TestDefault(t *testing.T) {
+	defaulted := operatorv1alpha1.ContourSpec{
+		Replicas: int32(2),
+		Namespace: operatorv1alpha1.NamespaceSpec{
+			Name: "projectcontour",
+		},
+		NetworkPublishing: operatorv1alpha1.NetworkPublishing{
+			Envoy: operatorv1alpha1.EnvoyNetworkPublishing{
+				Type: operatorv1alpha1.LoadBalancerServicePublishingType,
+				LoadBalancer: operatorv1alpha1.LoadBalancerStrategy{
+					Scope: operatorv1alpha1.ExternalLoadBalancer,
+					ProviderParameters: operatorv1alpha1.ProviderLoadBalancerParameters{
+						Type: operatorv1alpha1.AWSLoadBalancerProvider,
+					},
+				},
+				ContainerPorts: []operatorv1alpha1.ContainerPort{
+					{Name: "http", PortNumber: int32(8080)},
+					{Name: "https", PortNumber: int32(8443)},
+				},
+				SessionAffinity: corev1.ServiceAffinityNone,
+			},
+		},
+		Envoy: operatorv1alpha1.EnvoySettings{
+			WorkloadType: operatorv1alpha1.DaemonSetEnvoyWorkloadType,
+			Replicas:     int32(2),
+		},
+		XDSTLS: operatorv1alpha1.XDSTLSSettings{
+			Provider: operatorv1alpha1.CertGenXDSTLSProvider,
+		},
+	}
+
+	testCases := []struct {
+		description string
+		mutate      func(spec *operatorv1alpha1.ContourSpec)
+		expect      func(spec *operatorv1alpha1.ContourSpec)
+	}{
+		{
+			description: "empty spec",
+			mutate:      func(_ *operatorv1alpha1.ContourSpec) {},
+			expect:      func(_ *operatorv1alpha1.ContourSpec) {},
+		},
+		{
+			description: "user-provided values are preserved",
+			mutate: func(spec *operatorv1alpha1.ContourSpec) {
+				spec.Replicas = int32(3)
+				spec.Namespace.Name = "test-ns"
+				spec.NetworkPublishing.Envoy.Type = operatorv1alpha1.NodePortServicePublishingType
+				spec.NetworkPublishing.Envoy.ContainerPorts = []operatorv1alpha1.ContainerPort{
+					{Name: "http", PortNumber: int32(8081)},
+					{Name: "https", PortNumber: int32(8444)},
+				}
+				spec.Envoy.WorkloadType = operatorv1alpha1.DeploymentEnvoyWorkloadType
+			},
+			expect: func(spec *operatorv1alpha1.ContourSpec) {
+				spec.Replicas = int32(3)
+				spec.Namespace.Name = "test-ns"
+				spec.NetworkPublishing.Envoy.Type = operatorv1alpha1.NodePortServicePublishingType
+				spec.NetworkPublishing.Envoy.ContainerPorts = []operatorv1alpha1.ContainerPort{
+					{Name: "http", PortNumber: int32(8081)},
+					{Name: "https", PortNumber: int32(8444)},
+				}
+				spec.Envoy.WorkloadType = operatorv1alpha1.DeploymentEnvoyWorkloadType
+			},
+		},
+		{
+			description: "aws load balancer type",
+			mutate: func(spec *operatorv1alpha1.ContourSpec) {
+				spec.NetworkPublishing.Envoy.LoadBalancer.ProviderParameters.AWS = &operatorv1alpha1.AWSLoadBalancerParameters{}
+			},
+			expect: func(spec *operatorv1alpha1.ContourSpec) {
+				spec.NetworkPublishing.Envoy.LoadBalancer.ProviderParameters.AWS = &operatorv1alpha1.AWSLoadBalancerParameters{
+					Type: operatorv1alpha1.AWSClassicLoadBalancer,
+				}
+			},
+		},
+		{
+			description: "cert-manager issuer kind",
+			mutate: func(spec *operatorv1alpha1.ContourSpec) {
+				spec.XDSTLS.IssuerRef = &operatorv1alpha1.IssuerReference{Name: "xds-ca"}
+			},
+			expect: func(spec *operatorv1alpha1.ContourSpec) {
+				spec.XDSTLS.IssuerRef = &operatorv1alpha1.IssuerReference{
+					Name: "xds-ca",
+					Kind: operatorv1alpha1.IssuerKindIssuer,
+				}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		cntr := &operatorv1alpha1.Contour{}
+		tc.mutate(&cntr.Spec)
+		Default(cntr)
+		expected := defaulted.DeepCopy()
+		tc.expect(expected)
+		if !apiequality.Semantic.DeepEqual(cntr.Spec, *expected) {
+			t.Errorf("%q: expected spec %+v, got %+v", tc.description, *expected, cntr.Spec)
+		}
+		// Defaulting a defaulted spec is a no-op.
+		Default(cntr)
+		if !apiequality.Semantic.DeepEqual(cntr.Spec, *expected) {
+			t.Errorf("%q: expected defaulting to be idempotent", tc.description)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contour
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+)
+
+// The names of resources managed for a Contour are derived from the name of
+// the Contour so multiple instances of Contour can share a spec.namespace.name.
+
+// ContourName returns the name of the Contour Deployment, Service and
+// ServiceAccount for the provided contour.
+func ContourName(contour *operatorv1alpha1.Contour) string {
+	return fmt.Sprintf("%s-contour", contour.Name)
+}
+
+// EnvoyName returns the name of the Envoy DaemonSet, Service and ServiceAccount
+// for the provided contour.
+func EnvoyName(contour *operatorv1alpha1.Contour) string {
+	return fmt.Sprintf("%s-envoy", contour.Name)
+}
+
+// CertGenName returns the name of the certgen ServiceAccount, Role and
+// RoleBinding for the provided contour.
+func CertGenName(contour *operatorv1alpha1.Contour) string {
+	return fmt.Sprintf("%s-certgen", contour.Name)
+}
+
+// CertGenJobName returns the name of the certgen Job for the provided contour
+// and certgen container image. The name ends with a hash of image of a fixed
+// length, so a new Job is created when the image changes.
+func CertGenJobName(contour *operatorv1alpha1.Contour, image string) string {
+	return CertGenName(contour) + "-" + ImageHash(image)
+}
+
+// ImageHash returns a short hash of image that is a valid name segment and
+// label value for any image reference, including references by digest.
+func ImageHash(image string) string {
+	sum := sha256.Sum256([]byte(image))
+	return hex.EncodeToString(sum[:])[:10]
+}
+
+// ConfigMapName returns the name of the Contour ConfigMap for the provided contour.
+func ConfigMapName(contour *operatorv1alpha1.Contour) string {
+	return ContourName(contour)
+}
+
+// LeaderElectionName returns the name of the resource used by the provided
+// contour for leader election.
+func LeaderElectionName(contour *operatorv1alpha1.Contour) string {
+	return fmt.Sprintf("%s-leader-elect", contour.Name)
+}
+
+// ClusterRbacName returns the name of the ClusterRole and ClusterRoleBinding for
+// the provided contour. Cluster-scoped resources include the spec.namespace.name
+// of contour to allow ownership from individual instances of Contour, and a hash
+// of the namespace/name pair since joining both with "-" is ambiguous, e.g. for
+// namespace "x-a" with name "b" and namespace "x" with name "a-b".
+func ClusterRbacName(contour *operatorv1alpha1.Contour) string {
+	ns := contour.Spec.Namespace.Name
+	sum := sha256.Sum256([]byte(ns + "/" + contour.Name))
+	return fmt.Sprintf("contour-%s-%s-%s", ns, contour.Name, hex.EncodeToString(sum[:])[:8])
+}
+
+// CertsSecretNameSuffix returns the suffix certgen appends to the names of the
+// xDS TLS secrets generated for the provided contour.
+func CertsSecretNameSuffix(contour *operatorv1alpha1.Contour) string {
+	return fmt.Sprintf("-%s", contour.Name)
+}
+
+// ContourCertsSecretName returns the name of the secret containing the xDS TLS
+// certificate used by Contour of the provided contour.
+func ContourCertsSecretName(contour *operatorv1alpha1.Contour) string {
+	return "contourcert" + CertsSecretNameSuffix(contour)
+}
+
+// CACertsSecretName returns the name of the secret containing the CA that
+// issues the xDS TLS certificates of the provided contour, when the
+// certificates are issued by the operator.
+func CACertsSecretName(contour *operatorv1alpha1.Contour) string {
+	return "cacert" + CertsSecretNameSuffix(contour)
+}
+
+// EnvoyCertsSecretName returns the name of the secret containing the xDS TLS
+// certificate used by Envoy of the provided contour.
+func EnvoyCertsSecretName(contour *operatorv1alpha1.Contour) string {
+	return "envoycert" + CertsSecretNameSuffix(contour)
+}
+
+// ContourXDSSecretName returns the name of the secret mounted by the Contour
+// pods of the provided contour, i.e. spec.xdsTLS.secretRefs.contour if set,
+// otherwise the name of the secret provisioned by the operator.
+func ContourXDSSecretName(contour *operatorv1alpha1.Contour) string {
+	if refs := contour.Spec.XDSTLS.SecretRefs; refs != nil {
+		return refs.Contour
+	}
+	return ContourCertsSecretName(contour)
+}
+
+// EnvoyXDSSecretName returns the name of the secret mounted by the Envoy pods
+// of the provided contour, i.e. spec.xdsTLS.secretRefs.envoy if set, otherwise
+// the name of the secret provisioned by the operator.
+func EnvoyXDSSecretName(contour *operatorv1alpha1.Contour) string {
+	if refs := contour.Spec.XDSTLS.SecretRefs; refs != nil {
+		return refs.Envoy
+	}
+	return EnvoyCertsSecretName(contour)
+}
@@ -0,0 +1,37 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contour
+
+import (
+	"testing"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+)
+
+func TestClusterRbacName(t *testing.T) {
+	contour := func(specNs, name string) *operatorv1alpha1.Contour {
+		c := &operatorv1alpha1.Contour{}
+		c.Name = name
+		c.Spec.Namespace.Name = specNs
+		return c
+	}
+	a := ClusterRbacName(contour("x-a", "b"))
+	b := ClusterRbacName(contour("x", "a-b"))
+	if a == b {
+		t.Errorf("expected distinct names for x-a/b and x/a-b, got %q", a)
+	}
+	if a != ClusterRbacName(contour("x-a", "b")) {
+		t.Errorf("expected name for x-a/b to be stable")
+	}
+}
@@ -23,7 +23,9 @@ import (
 	opintstr "github.com/projectcontour/contour-operator/internal/intstr"
 	objutil "github.com/projectcontour/contour-operator/internal/objects"
 	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+	objsecret "github.com/projectcontour/contour-operator/internal/objects/secret"
 	objcfg "github.com/projectcontour/contour-operator/internal/objects/sharedconfig"
+	"github.com/projectcontour/contour-operator/internal/operator/metrics/drift"
 	"github.com/projectcontour/contour-operator/pkg/labels"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -37,10 +39,6 @@ import (
 )
 
 const (
-	// envoyDaemonSetName is the name of Envoy's DaemonSet resource.
-	// [TODO] danehans: Remove and use contour.Name + "-envoy" when
-	// https://github.com/projectcontour/contour/issues/2122 is fixed.
-	envoyDaemonSetName = "envoy"
 	// EnvoyContainerName is the name of the Envoy container.
 	EnvoyContainerName = "envoy"
 	// ShutdownContainerName is the name of the Shutdown Manager container.
@@ -55,8 +53,6 @@ const (
 	envoyCertsVolName = "envoycert"
 	// envoyCertsVolMntDir is the directory name of the Envoy certificates volume.
 	envoyCertsVolMntDir = "certs"
-	// envoyCertsSecretName is the name of the secret used as the certificate volume source.
-	envoyCertsSecretName = envoyCertsVolName
 	// envoyCfgVolName is the name of the Envoy configuration volume.
 	envoyCfgVolName = "envoy-config"
 	// envoyCfgVolMntDir is the directory name of the Envoy configuration volume.
@@ -67,9 +63,17 @@ const (
 	xdsResourceVersion = "v3"
 )
 
-// EnsureDaemonSet ensures a DaemonSet exists for the given contour.
+// EnsureDaemonSet ensures a DaemonSet exists for the given contour, using
+// contourImage as the shutdown-manager/envoy-initconfig container images and
+// envoyImage as Envoy's container image, unless spec.contour.image or
+// spec.envoy.image are set.
 func EnsureDaemonSet(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, contourImage, envoyImage string) error {
-	desired := DesiredDaemonSet(contour, contourImage, envoyImage)
+	annotations, err := objsecret.CertAnnotations(ctx, cli, contour, objcontour.EnvoyXDSSecretName(contour))
+	if err != nil {
+		return err
+	}
+	desired := DesiredDaemonSet(contour, objcontour.ContourImage(contour, contourImage),
+		objcontour.EnvoyImage(contour, envoyImage), annotations)
 	current, err := CurrentDaemonSet(ctx, cli, contour)
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -110,8 +114,9 @@ func EnsureDaemonSetDeleted(ctx context.Context, cli client.Client, contour *ope
 
 // DesiredDaemonSet returns the desired DaemonSet for the provided contour using
 // contourImage as the shutdown-manager/envoy-initconfig container images and
-// envoyImage as Envoy's container image.
-func DesiredDaemonSet(contour *operatorv1alpha1.Contour, contourImage, envoyImage string) *appsv1.DaemonSet {
+// envoyImage as Envoy's container image. The provided annotations are added to
+// the pod template, so a change of their values triggers a rolling update.
+func DesiredDaemonSet(contour *operatorv1alpha1.Contour, contourImage, envoyImage string, annotations map[string]string) *appsv1.DaemonSet {
 	labels := map[string]string{
 		"app.kubernetes.io/name":       "contour",
 		"app.kubernetes.io/instance":   contour.Name,
@@ -122,6 +127,34 @@ func DesiredDaemonSet(contour *operatorv1alpha1.Contour, contourImage, envoyImag
 		operatorv1alpha1.OwningContourNameLabel: contour.Name,
 	}
 
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: contour.Spec.Namespace.Name,
+			Name:      objcontour.EnvoyName(contour),
+			Labels:    labels,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			RevisionHistoryLimit: pointer.Int32Ptr(int32(10)),
+			// Ensure the deamonset adopts only its own pods.
+			Selector: EnvoyPodSelector(contour),
+			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+				Type: appsv1.RollingUpdateDaemonSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+					MaxUnavailable: opintstr.PointerTo(intstr.FromString("10%")),
+				},
+			},
+			Template: DesiredEnvoyPodTemplate(contour, contourImage, envoyImage, annotations),
+		},
+	}
+
+	return ds
+}
+
+// DesiredEnvoyPodTemplate returns the desired pod template used by the Envoy
+// DaemonSet or Deployment of the provided contour, using contourImage as the
+// shutdown-manager/envoy-initconfig container images and envoyImage as Envoy's
+// container image. The provided annotations are added to the pod template.
+func DesiredEnvoyPodTemplate(contour *operatorv1alpha1.Contour, contourImage, envoyImage string, annotations map[string]string) corev1.PodTemplateSpec {
 	var ports []corev1.ContainerPort
 	for _, port := range contour.Spec.NetworkPublishing.Envoy.ContainerPorts {
 		p := corev1.ContainerPort{
@@ -137,6 +170,7 @@ func DesiredDaemonSet(contour *operatorv1alpha1.Contour, contourImage, envoyImag
 			Name:            ShutdownContainerName,
 			Image:           contourImage,
 			ImagePullPolicy: corev1.PullIfNotPresent,
+			Resources:       contour.Spec.Envoy.ShutdownManagerResources,
 			Command: []string{
 				"/bin/contour",
 			},
@@ -172,6 +206,7 @@ func DesiredDaemonSet(contour *operatorv1alpha1.Contour, contourImage, envoyImag
 			Name:            EnvoyContainerName,
 			Image:           envoyImage,
 			ImagePullPolicy: corev1.PullIfNotPresent,
+			Resources:       contour.Spec.Envoy.Resources,
 			Command: []string{
 				"envoy",
 			},
@@ -248,13 +283,14 @@ func DesiredDaemonSet(contour *operatorv1alpha1.Contour, contourImage, envoyImag
 			Name:            envoyInitContainerName,
 			Image:           contourImage,
 			ImagePullPolicy: corev1.PullIfNotPresent,
+			Resources:       contour.Spec.Envoy.ShutdownManagerResources,
 			Command: []string{
 				"contour",
 			},
 			Args: []string{
 				"bootstrap",
 				filepath.Join("/", envoyCfgVolMntDir, envoyCfgFileName),
-				"--xds-address=contour",
+				fmt.Sprintf("--xds-address=%s", objcontour.ContourName(contour)),
 				fmt.Sprintf("--xds-port=%d", objcfg.XDSPort),
 				fmt.Sprintf("--xds-resource-version=%s", xdsResourceVersion),
 				fmt.Sprintf("--resources-dir=%s", filepath.Join("/", envoyCfgVolMntDir, "resources")),
@@ -289,68 +325,59 @@ func DesiredDaemonSet(contour *operatorv1alpha1.Contour, contourImage, envoyImag
 			TerminationMessagePath:   "/dev/termination-log",
 		},
 	}
+	// Resolve the Contour xDS service using the IP family of the cluster.
+	if family := objcontour.DNSLookupFamily(contour); len(family) > 0 {
+		initContainers[0].Args = append(initContainers[0].Args, fmt.Sprintf("--dns-lookup-family=%s", family))
+	}
 
-	ds := &appsv1.DaemonSet{
+	template := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: contour.Spec.Namespace.Name,
-			Name:      envoyDaemonSetName,
-			Labels:    labels,
+			Annotations: map[string]string{},
+			Labels:      EnvoyPodSelector(contour).MatchLabels,
 		},
-		Spec: appsv1.DaemonSetSpec{
-			RevisionHistoryLimit: pointer.Int32Ptr(int32(10)),
-			// Ensure the deamonset adopts only its own pods.
-			Selector: EnvoyDaemonSetPodSelector(),
-			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
-				Type: appsv1.RollingUpdateDaemonSetStrategyType,
-				RollingUpdate: &appsv1.RollingUpdateDaemonSet{
-					MaxUnavailable: opintstr.PointerTo(intstr.FromString("10%")),
-				},
-			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					// TODO [danehans]: Remove the prometheus annotations when Contour is updated to
-					// show how the Prometheus Operator is used to scrape Contour/Envoy metrics.
-					Annotations: map[string]string{
-						"prometheus.io/scrape": "true",
-						"prometheus.io/port":   "8002",
-						"prometheus.io/path":   "/stats/prometheus",
+		Spec: corev1.PodSpec{
+			Containers:     containers,
+			InitContainers: initContainers,
+			Volumes: []corev1.Volume{
+				{
+					Name: envoyCertsVolName,
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{
+							DefaultMode: pointer.Int32Ptr(int32(420)),
+							SecretName:  objcontour.EnvoyXDSSecretName(contour),
+						},
 					},
-					Labels: EnvoyDaemonSetPodSelector().MatchLabels,
 				},
-				Spec: corev1.PodSpec{
-					Containers:     containers,
-					InitContainers: initContainers,
-					Volumes: []corev1.Volume{
-						{
-							Name: envoyCertsVolName,
-							VolumeSource: corev1.VolumeSource{
-								Secret: &corev1.SecretVolumeSource{
-									DefaultMode: pointer.Int32Ptr(int32(420)),
-									SecretName:  envoyCertsSecretName,
-								},
-							},
-						},
-						{
-							Name: envoyCfgVolName,
-							VolumeSource: corev1.VolumeSource{
-								EmptyDir: &corev1.EmptyDirVolumeSource{},
-							},
-						},
+				{
+					Name: envoyCfgVolName,
+					VolumeSource: corev1.VolumeSource{
+						EmptyDir: &corev1.EmptyDirVolumeSource{},
 					},
-					ServiceAccountName:            objutil.EnvoyRbacName,
-					DeprecatedServiceAccount:      EnvoyContainerName,
-					AutomountServiceAccountToken:  pointer.BoolPtr(false),
-					TerminationGracePeriodSeconds: pointer.Int64Ptr(int64(300)),
-					SecurityContext:               &corev1.PodSecurityContext{},
-					DNSPolicy:                     corev1.DNSClusterFirst,
-					RestartPolicy:                 corev1.RestartPolicyAlways,
-					SchedulerName:                 "default-scheduler",
 				},
 			},
+			ServiceAccountName:            objcontour.EnvoyName(contour),
+			DeprecatedServiceAccount:      EnvoyContainerName,
+			AutomountServiceAccountToken:  pointer.BoolPtr(false),
+			TerminationGracePeriodSeconds: pointer.Int64Ptr(int64(300)),
+			SecurityContext:               &corev1.PodSecurityContext{},
+			DNSPolicy:                     corev1.DNSClusterFirst,
+			RestartPolicy:                 corev1.RestartPolicyAlways,
+			SchedulerName:                 "default-scheduler",
 		},
 	}
+	// The metrics are scraped by the PodMonitor of contour instead of
+	// annotation-based scrape configs when PodMonitors are enabled.
+	if !contour.Spec.Monitoring.PodMonitors {
+		template.Annotations["prometheus.io/scrape"] = "true"
+		template.Annotations["prometheus.io/port"] = "8002"
+		template.Annotations["prometheus.io/path"] = "/stats/prometheus"
+	}
+	for k, v := range annotations {
+		template.Annotations[k] = v
+	}
+	objutil.ApplyNodePlacement(&template.Spec, contour.Spec.Envoy.NodePlacement)
 
-	return ds
+	return template
 }
 
 // CurrentDaemonSet returns the current DaemonSet resource for the provided contour.
@@ -358,7 +385,7 @@ func CurrentDaemonSet(ctx context.Context, cli client.Client, contour *operatorv
 	ds := &appsv1.DaemonSet{}
 	key := types.NamespacedName{
 		Namespace: contour.Spec.Namespace.Name,
-		Name:      envoyDaemonSetName,
+		Name:      objcontour.EnvoyName(contour),
 	}
 	if err := cli.Get(ctx, key, ds); err != nil {
 		return nil, err
@@ -383,21 +410,21 @@ func updateDaemonSetIfNeeded(ctx context.Context, cli client.Client, contour *op
 			if err := cli.Update(ctx, ds); err != nil {
 				return fmt.Errorf("failed to update daemonset %s/%s: %w", ds.Namespace, ds.Name, err)
 			}
+			drift.ObserveCorrection(cli, ds)
 			return nil
 		}
 	}
 	return nil
 }
 
-// EnvoyDaemonSetPodSelector returns a label selector using "app: envoy" as the
-// key/value pair.
-//
-// TODO [danehans]: Update to use "contour.operator.projectcontour.io/daemonset-envoy"
-// when https://github.com/projectcontour/contour/issues/1821 is fixed.
-func EnvoyDaemonSetPodSelector() *metav1.LabelSelector {
+// EnvoyPodSelector returns a label selector using "app: envoy" and
+// "contour.operator.projectcontour.io/envoy: <contour name>" as the
+// key/value pairs, scoping the selected pods to the provided contour.
+func EnvoyPodSelector(contour *operatorv1alpha1.Contour) *metav1.LabelSelector {
 	return &metav1.LabelSelector{
 		MatchLabels: map[string]string{
-			"app": "envoy",
+			"app":                          "envoy",
+			operatorv1alpha1.EnvoyPodLabel: contour.Name,
 		},
 	}
 }
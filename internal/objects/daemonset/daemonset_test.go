@@ -24,8 +24,17 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
+func checkContainerHasResources(t *testing.T, container *corev1.Container, expected corev1.ResourceRequirements) {
+	t.Helper()
+
+	if !apiequality.Semantic.DeepEqual(container.Resources, expected) {
+		t.Errorf("container %q has unexpected resources %v", container.Name, container.Resources)
+	}
+}
+
 func checkDaemonSetHasEnvVar(t *testing.T, ds *appsv1.DaemonSet, container, name string) {
 	t.Helper()
 
@@ -128,15 +137,40 @@ func TestDesiredDaemonSet(t *testing.T) {
 		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
 	}
 	cntr := objcontour.New(cfg)
+	cntr.Spec.Envoy.Resources = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("250m"),
+			corev1.ResourceMemory: resource.MustParse("256Mi"),
+		},
+	}
+	cntr.Spec.Envoy.ShutdownManagerResources = corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("64Mi"),
+		},
+	}
+	cntr.Spec.Envoy.NodePlacement = &operatorv1alpha1.NodePlacement{
+		NodeSelector: map[string]string{"node-role.kubernetes.io/edge": ""},
+		Tolerations: []corev1.Toleration{
+			{
+				Key:      "node-role.kubernetes.io/edge",
+				Operator: corev1.TolerationOpExists,
+				Effect:   corev1.TaintEffectNoSchedule,
+			},
+		},
+		PriorityClassName: "system-node-critical",
+	}
 	testContourImage := config.DefaultContourImage
 	testEnvoyImage := config.DefaultEnvoyImage
-	ds := DesiredDaemonSet(cntr, testContourImage, testEnvoyImage)
+	ds := DesiredDaemonSet(cntr, testContourImage, testEnvoyImage, nil)
 	container := checkDaemonSetHasContainer(t, ds, EnvoyContainerName, true)
 	checkContainerHasImage(t, container, testEnvoyImage)
+	checkContainerHasResources(t, container, cntr.Spec.Envoy.Resources)
 	container = checkDaemonSetHasContainer(t, ds, ShutdownContainerName, true)
 	checkContainerHasImage(t, container, testContourImage)
+	checkContainerHasResources(t, container, cntr.Spec.Envoy.ShutdownManagerResources)
 	container = checkDaemonSetHasContainer(t, ds, envoyInitContainerName, true)
 	checkContainerHasImage(t, container, testContourImage)
+	checkContainerHasResources(t, container, cntr.Spec.Envoy.ShutdownManagerResources)
 	checkDaemonSetHasEnvVar(t, ds, EnvoyContainerName, envoyNsEnvVar)
 	checkDaemonSetHasEnvVar(t, ds, EnvoyContainerName, envoyPodEnvVar)
 	checkDaemonSetHasEnvVar(t, ds, envoyInitContainerName, envoyNsEnvVar)
@@ -144,4 +178,88 @@ func TestDesiredDaemonSet(t *testing.T) {
 	for _, port := range cntr.Spec.NetworkPublishing.Envoy.ContainerPorts {
 		checkContainerHasPort(t, ds, port.PortNumber)
 	}
+
+	podSpec := ds.Spec.Template.Spec
+	if !apiequality.Semantic.DeepEqual(podSpec.NodeSelector, cntr.Spec.Envoy.NodePlacement.NodeSelector) {
+		t.Errorf("daemonset has unexpected node selector %v", podSpec.NodeSelector)
+	}
+	if !apiequality.Semantic.DeepEqual(podSpec.Tolerations, cntr.Spec.Envoy.NodePlacement.Tolerations) {
+		t.Errorf("daemonset has unexpected tolerations %v", podSpec.Tolerations)
+	}
+	if podSpec.PriorityClassName != cntr.Spec.Envoy.NodePlacement.PriorityClassName {
+		t.Errorf("daemonset has unexpected priority class name %q", podSpec.PriorityClassName)
+	}
+
+	if ds.Name != objcontour.EnvoyName(cntr) {
+		t.Errorf("daemonset has unexpected name %q", ds.Name)
+	}
+	if ds.Spec.Selector.MatchLabels[operatorv1alpha1.EnvoyPodLabel] != cntr.Name {
+		t.Errorf("daemonset has unexpected selector %v", ds.Spec.Selector.MatchLabels)
+	}
+	xdsArg := fmt.Sprintf("--xds-address=%s", objcontour.ContourName(cntr))
+	found := false
+	for _, arg := range container.Args {
+		if arg == xdsArg {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("container %q is missing argument %q", container.Name, xdsArg)
+	}
+}
+
+func TestDesiredDaemonSetPrometheusAnnotations(t *testing.T) {
+	testCases := []struct {
+		description string
+		podMonitors bool
+		expected    bool
+	}{
+		{
+			description: "pod monitors disabled",
+			expected:    true,
+		},
+		{
+			description: "pod monitors enabled",
+			podMonitors: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		cntr := objcontour.New(objcontour.Config{
+			Name:        "ds-annotations-test",
+			Namespace:   "ds-annotations-test-ns",
+			SpecNs:      "projectcontour",
+			NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+		})
+		cntr.Spec.Monitoring.PodMonitors = tc.podMonitors
+		ds := DesiredDaemonSet(cntr, config.DefaultContourImage, config.DefaultEnvoyImage, nil)
+		for _, k := range []string{"prometheus.io/scrape", "prometheus.io/port", "prometheus.io/path"} {
+			if _, ok := ds.Spec.Template.Annotations[k]; ok != tc.expected {
+				t.Errorf("%q: expected annotation %s to exist: %t, got annotations %v", tc.description, k,
+					tc.expected, ds.Spec.Template.Annotations)
+			}
+		}
+	}
+}
+
+func TestDesiredDaemonSetIPv6(t *testing.T) {
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "ds-ipv6-test",
+		Namespace:   "ds-ipv6-test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	cntr.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv6Protocol}
+
+	ds := DesiredDaemonSet(cntr, config.DefaultContourImage, config.DefaultEnvoyImage, nil)
+	initContainer := ds.Spec.Template.Spec.InitContainers[0]
+	found := false
+	for _, arg := range initContainer.Args {
+		if arg == "--dns-lookup-family=v6" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("container %q is missing argument %q", initContainer.Name, "--dns-lookup-family=v6")
+	}
 }
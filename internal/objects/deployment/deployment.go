@@ -17,6 +17,7 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"sort"
 
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
 	"github.com/projectcontour/contour-operator/internal/equality"
@@ -24,7 +25,10 @@ import (
 	objutil "github.com/projectcontour/contour-operator/internal/objects"
 	objcm "github.com/projectcontour/contour-operator/internal/objects/configmap"
 	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+	objlegacy "github.com/projectcontour/contour-operator/internal/objects/legacy"
+	objsecret "github.com/projectcontour/contour-operator/internal/objects/secret"
 	objcfg "github.com/projectcontour/contour-operator/internal/objects/sharedconfig"
+	"github.com/projectcontour/contour-operator/internal/operator/metrics/drift"
 	"github.com/projectcontour/contour-operator/pkg/labels"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -38,12 +42,8 @@ import (
 )
 
 const (
-	// contourDeploymentName is the name of Contour's Deployment resource.
-	// [TODO] danehans: Remove and use contour.Name + "-contour" when
-	// https://github.com/projectcontour/contour/issues/2122 is fixed.
-	contourDeploymentName = "contour"
-	// contourContainerName is the name of the Contour container.
-	contourContainerName = "contour"
+	// ContourContainerName is the name of the Contour container.
+	ContourContainerName = "contour"
 	// contourNsEnvVar is the name of the contour namespace environment variable.
 	contourNsEnvVar = "CONTOUR_NAMESPACE"
 	// contourPodEnvVar is the name of the contour pod name environment variable.
@@ -52,8 +52,6 @@ const (
 	contourCertsVolName = "contourcert"
 	// contourCertsVolMntDir is the directory name of the contour certificates volume.
 	contourCertsVolMntDir = "certs"
-	// contourCertsSecretName is the name of the secret used as the certificate volume source.
-	contourCertsSecretName = contourCertsVolName
 	// contourCfgVolName is the name of the contour configuration volume.
 	contourCfgVolName = "contour-config"
 	// contourCfgVolMntDir is the directory name of the contour configuration volume.
@@ -64,11 +62,35 @@ const (
 	metricsPort = 8000
 	// debugPort is the network port number of Contour's debug service.
 	debugPort = 6060
+	// ConfigChecksumAnnotation is the pod template annotation containing the
+	// checksum of the Contour configuration. A change of the checksum rolls
+	// the Contour pods so the new configuration takes effect.
+	ConfigChecksumAnnotation = "contour.operator.projectcontour.io/config-checksum"
 )
 
-// EnsureDeployment ensures a deployment using image exists for the given contour.
+// EnsureDeployment ensures a deployment exists for the given contour, using
+// image as Contour's container image unless spec.contour.image is set.
 func EnsureDeployment(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, image string) error {
-	desired := DesiredDeployment(contour, image)
+	annotations, err := configAnnotations(ctx, cli, contour)
+	if err != nil {
+		return err
+	}
+	certAnnotations, err := objsecret.CertAnnotations(ctx, cli, contour, objcontour.ContourXDSSecretName(contour))
+	if err != nil {
+		return err
+	}
+	for k, v := range certAnnotations {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[k] = v
+	}
+	desired := DesiredDeployment(contour, objcontour.ContourImage(contour, image), annotations)
+	envoySvcName, err := objlegacy.EnvoyServiceName(ctx, cli, contour)
+	if err != nil {
+		return err
+	}
+	setEnvoyServiceName(desired, contour, envoySvcName)
 	current, err := CurrentDeployment(ctx, cli, contour)
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -110,19 +132,66 @@ func EnsureDeploymentDeleted(ctx context.Context, cli client.Client, contour *op
 	return nil
 }
 
+// setEnvoyServiceName sets the name of the Envoy Service, whose addresses Contour
+// writes to the status of ingress resources, to name in deploy, the desired
+// deployment of the provided contour.
+func setEnvoyServiceName(deploy *appsv1.Deployment, contour *operatorv1alpha1.Contour, name string) {
+	arg := fmt.Sprintf("--envoy-service-name=%s", objcontour.EnvoyName(contour))
+	for i := range deploy.Spec.Template.Spec.Containers {
+		container := &deploy.Spec.Template.Spec.Containers[i]
+		for j := range container.Args {
+			if container.Args[j] == arg {
+				container.Args[j] = fmt.Sprintf("--envoy-service-name=%s", name)
+			}
+		}
+	}
+}
+
+// configAnnotations returns the pod template annotations that track the
+// configuration mounted by the Contour pods of the provided contour. No
+// annotations are returned if the configuration does not yet exist.
+func configAnnotations(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) (map[string]string, error) {
+	cm := &corev1.ConfigMap{}
+	key := types.NamespacedName{
+		Namespace: contour.Spec.Namespace.Name,
+		Name:      objcontour.ConfigMapName(contour),
+	}
+	if err := cli.Get(ctx, key, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get configmap %s/%s: %w", key.Namespace, key.Name, err)
+	}
+	return map[string]string{ConfigChecksumAnnotation: objcm.Checksum(cm)}, nil
+}
+
 // DesiredDeployment returns the desired deployment for the provided contour using
-// image as Contour's container image.
-func DesiredDeployment(contour *operatorv1alpha1.Contour, image string) *appsv1.Deployment {
+// image as Contour's container image. The provided annotations are added to the
+// pod template, so a change of their values triggers a rolling update.
+func DesiredDeployment(contour *operatorv1alpha1.Contour, image string, annotations map[string]string) *appsv1.Deployment {
 	xdsPort := objcfg.XDSPort
+	bindAddress := objcontour.BindAddress(contour)
 	args := []string{
 		"serve",
 		"--incluster",
-		"--xds-address=0.0.0.0",
+		fmt.Sprintf("--xds-address=%s", bindAddress),
 		fmt.Sprintf("--xds-port=%d", xdsPort),
 		fmt.Sprintf("--contour-cafile=%s", filepath.Join("/", contourCertsVolMntDir, "ca.crt")),
 		fmt.Sprintf("--contour-cert-file=%s", filepath.Join("/", contourCertsVolMntDir, "tls.crt")),
 		fmt.Sprintf("--contour-key-file=%s", filepath.Join("/", contourCertsVolMntDir, "tls.key")),
 		fmt.Sprintf("--config-path=%s", filepath.Join("/", contourCfgVolMntDir, contourCfgFileName)),
+		fmt.Sprintf("--envoy-service-name=%s", objcontour.EnvoyName(contour)),
+	}
+	// Contour's metrics/health listeners and the Envoy listeners it configures
+	// default to the IPv4 wildcard address.
+	if objcontour.IPv6Enabled(contour) {
+		args = append(args,
+			fmt.Sprintf("--http-address=%s", bindAddress),
+			fmt.Sprintf("--health-address=%s", bindAddress),
+			fmt.Sprintf("--stats-address=%s", bindAddress),
+			fmt.Sprintf("--envoy-service-http-address=%s", bindAddress),
+			fmt.Sprintf("--envoy-service-https-address=%s", bindAddress),
+		)
 	}
 	// Pass the insecure/secure flags to Contour if using non-default ports.
 	for _, port := range contour.Spec.NetworkPublishing.Envoy.ContainerPorts {
@@ -137,9 +206,10 @@ func DesiredDeployment(contour *operatorv1alpha1.Contour, image string) *appsv1.
 		args = append(args, fmt.Sprintf("--ingress-class-name=%s", *contour.Spec.IngressClassName))
 	}
 	container := corev1.Container{
-		Name:            contourContainerName,
+		Name:            ContourContainerName,
 		Image:           image,
 		ImagePullPolicy: corev1.PullIfNotPresent,
+		Resources:       contour.Spec.Contour.Resources,
 		Command:         []string{"contour"},
 		Args:            args,
 		Env: []corev1.EnvVar{
@@ -224,7 +294,7 @@ func DesiredDeployment(contour *operatorv1alpha1.Contour, image string) *appsv1.
 	deploy := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: contour.Spec.Namespace.Name,
-			Name:      contourDeploymentName,
+			Name:      objcontour.ContourName(contour),
 			Labels:    makeDeploymentLabels(contour),
 		},
 		Spec: appsv1.DeploymentSpec{
@@ -232,7 +302,7 @@ func DesiredDeployment(contour *operatorv1alpha1.Contour, image string) *appsv1.
 			Replicas:                &contour.Spec.Replicas,
 			RevisionHistoryLimit:    pointer.Int32Ptr(int32(10)),
 			// Ensure the deployment adopts only its own pods.
-			Selector: ContourDeploymentPodSelector(),
+			Selector: ContourDeploymentPodSelector(contour),
 			Strategy: appsv1.DeploymentStrategy{
 				Type: appsv1.RollingUpdateDeploymentStrategyType,
 				RollingUpdate: &appsv1.RollingUpdateDeployment{
@@ -242,13 +312,8 @@ func DesiredDeployment(contour *operatorv1alpha1.Contour, image string) *appsv1.
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					// TODO [danehans]: Remove the prometheus annotations when Contour is updated to
-					// show how the Prometheus Operator is used to scrape Contour/Envoy metrics.
-					Annotations: map[string]string{
-						"prometheus.io/scrape": "true",
-						"prometheus.io/port":   fmt.Sprintf("%d", metricsPort),
-					},
-					Labels: ContourDeploymentPodSelector().MatchLabels,
+					Annotations: map[string]string{},
+					Labels:      ContourDeploymentPodSelector(contour).MatchLabels,
 				},
 				Spec: corev1.PodSpec{
 					// TODO [danehans]: Readdress anti-affinity when https://github.com/projectcontour/contour/issues/2997
@@ -261,7 +326,7 @@ func DesiredDeployment(contour *operatorv1alpha1.Contour, image string) *appsv1.
 									PodAffinityTerm: corev1.PodAffinityTerm{
 										TopologyKey: "kubernetes.io/hostname",
 										LabelSelector: &metav1.LabelSelector{
-											MatchLabels: ContourDeploymentPodSelector().MatchLabels,
+											MatchLabels: ContourDeploymentPodSelector(contour).MatchLabels,
 										},
 									},
 								},
@@ -275,7 +340,7 @@ func DesiredDeployment(contour *operatorv1alpha1.Contour, image string) *appsv1.
 							VolumeSource: corev1.VolumeSource{
 								Secret: &corev1.SecretVolumeSource{
 									DefaultMode: pointer.Int32Ptr(int32(420)),
-									SecretName:  contourCertsSecretName,
+									SecretName:  objcontour.ContourXDSSecretName(contour),
 								},
 							},
 						},
@@ -284,9 +349,7 @@ func DesiredDeployment(contour *operatorv1alpha1.Contour, image string) *appsv1.
 							VolumeSource: corev1.VolumeSource{
 								ConfigMap: &corev1.ConfigMapVolumeSource{
 									LocalObjectReference: corev1.LocalObjectReference{
-										// [TODO] danehans: Update to contour.Name when
-										// projectcontour/contour/issues/2122 is fixed.
-										Name: objcm.ContourCfgMapName,
+										Name: objcontour.ConfigMapName(contour),
 									},
 									Items: []corev1.KeyToPath{
 										{
@@ -300,8 +363,8 @@ func DesiredDeployment(contour *operatorv1alpha1.Contour, image string) *appsv1.
 						},
 					},
 					DNSPolicy:                     corev1.DNSClusterFirst,
-					DeprecatedServiceAccount:      objutil.ContourRbacName,
-					ServiceAccountName:            objutil.ContourRbacName,
+					DeprecatedServiceAccount:      objcontour.ContourName(contour),
+					ServiceAccountName:            objcontour.ContourName(contour),
 					RestartPolicy:                 corev1.RestartPolicyAlways,
 					SchedulerName:                 "default-scheduler",
 					SecurityContext:               objutil.NewUnprivilegedPodSecurity(),
@@ -310,6 +373,16 @@ func DesiredDeployment(contour *operatorv1alpha1.Contour, image string) *appsv1.
 			},
 		},
 	}
+	// The metrics are scraped by the PodMonitor of contour instead of
+	// annotation-based scrape configs when PodMonitors are enabled.
+	if !contour.Spec.Monitoring.PodMonitors {
+		deploy.Spec.Template.Annotations["prometheus.io/scrape"] = "true"
+		deploy.Spec.Template.Annotations["prometheus.io/port"] = fmt.Sprintf("%d", metricsPort)
+	}
+	for k, v := range annotations {
+		deploy.Spec.Template.Annotations[k] = v
+	}
+	objutil.ApplyNodePlacement(&deploy.Spec.Template.Spec, contour.Spec.Contour.NodePlacement)
 	return deploy
 }
 
@@ -318,7 +391,7 @@ func CurrentDeployment(ctx context.Context, cli client.Client, contour *operator
 	deploy := &appsv1.Deployment{}
 	key := types.NamespacedName{
 		Namespace: contour.Spec.Namespace.Name,
-		Name:      contourDeploymentName,
+		Name:      objcontour.ContourName(contour),
 	}
 	if err := cli.Get(ctx, key, deploy); err != nil {
 		return nil, err
@@ -326,6 +399,26 @@ func CurrentDeployment(ctx context.Context, cli client.Client, contour *operator
 	return deploy, nil
 }
 
+// OwnedNamespaces returns the sorted names of the namespaces containing a
+// Deployment owned by the provided contour, i.e. the namespaces the resources
+// of contour run in.
+func OwnedNamespaces(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) ([]string, error) {
+	deploys := &appsv1.DeploymentList{}
+	if err := cli.List(ctx, deploys, client.MatchingLabels(objcontour.OwnerLabels(contour))); err != nil {
+		return nil, fmt.Errorf("failed to list deployments for contour %s/%s: %w", contour.Namespace, contour.Name, err)
+	}
+	found := map[string]struct{}{}
+	var namespaces []string
+	for _, deploy := range deploys.Items {
+		if _, ok := found[deploy.Namespace]; !ok {
+			found[deploy.Namespace] = struct{}{}
+			namespaces = append(namespaces, deploy.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
 // createDeployment creates a Deployment resource for the provided deploy.
 func createDeployment(ctx context.Context, cli client.Client, deploy *appsv1.Deployment) error {
 	if err := cli.Create(ctx, deploy); err != nil {
@@ -343,6 +436,7 @@ func updateDeploymentIfNeeded(ctx context.Context, cli client.Client, contour *o
 			if err := cli.Update(ctx, deploy); err != nil {
 				return fmt.Errorf("failed to update deployment %s/%s: %w", deploy.Namespace, deploy.Name, err)
 			}
+			drift.ObserveCorrection(cli, deploy)
 		}
 	}
 	return nil
@@ -361,15 +455,14 @@ func makeDeploymentLabels(contour *operatorv1alpha1.Contour) map[string]string {
 	}
 }
 
-// ContourDeploymentPodSelector returns a label selector using "app: contour" as the
-// key/value pair.
-//
-// TODO [danehans]: Update to use "contour.operator.projectcontour.io/deployment-contour"
-// when https://github.com/projectcontour/contour/issues/1821 is fixed.
-func ContourDeploymentPodSelector() *metav1.LabelSelector {
+// ContourDeploymentPodSelector returns a label selector using "app: contour" and
+// "contour.operator.projectcontour.io/deployment-contour: <contour name>" as the
+// key/value pairs, scoping the selected pods to the provided contour.
+func ContourDeploymentPodSelector(contour *operatorv1alpha1.Contour) *metav1.LabelSelector {
 	return &metav1.LabelSelector{
 		MatchLabels: map[string]string{
-			"app": "contour",
+			"app":                                   "contour",
+			operatorv1alpha1.ContourDeploymentLabel: contour.Name,
 		},
 	}
 }
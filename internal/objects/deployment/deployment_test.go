@@ -14,17 +14,24 @@
 package deployment
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	objcm "github.com/projectcontour/contour-operator/internal/objects/configmap"
 	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+	objds "github.com/projectcontour/contour-operator/internal/objects/daemonset"
 	objcfg "github.com/projectcontour/contour-operator/internal/objects/sharedconfig"
 	"github.com/projectcontour/contour-operator/internal/operator/config"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func checkDeploymentHasEnvVar(t *testing.T, deploy *appsv1.Deployment, name string) {
@@ -101,6 +108,12 @@ func TestDesiredDeployment(t *testing.T) {
 	cntr := objcontour.New(cfg)
 	icName := "test-ic"
 	cntr.Spec.IngressClassName = &icName
+	cntr.Spec.Contour.Resources = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		},
+	}
 	// Change the default ports to test Envoy service port args.
 	insecurePort := objcfg.EnvoyInsecureContainerPort
 	securePort := objcfg.EnvoySecureContainerPort
@@ -113,14 +126,50 @@ func TestDesiredDeployment(t *testing.T) {
 		}
 	}
 
+	cntr.Spec.Contour.NodePlacement = &operatorv1alpha1.NodePlacement{
+		NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+		Affinity: &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{
+						{
+							MatchExpressions: []corev1.NodeSelectorRequirement{
+								{
+									Key:      "topology.kubernetes.io/zone",
+									Operator: corev1.NodeSelectorOpIn,
+									Values:   []string{"zone-a", "zone-b"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
+			{
+				MaxSkew:           int32(1),
+				TopologyKey:       "topology.kubernetes.io/zone",
+				WhenUnsatisfiable: corev1.ScheduleAnyway,
+				LabelSelector:     ContourDeploymentPodSelector(cntr),
+			},
+		},
+	}
+
 	testContourImage := config.DefaultContourImage
-	deploy := DesiredDeployment(cntr, testContourImage)
+	annotations := map[string]string{ConfigChecksumAnnotation: "abc123"}
+	deploy := DesiredDeployment(cntr, testContourImage, annotations)
 
-	container := checkDeploymentHasContainer(t, deploy, contourContainerName, true)
+	container := checkDeploymentHasContainer(t, deploy, ContourContainerName, true)
 	checkContainerHasImage(t, container, testContourImage)
+	if !apiequality.Semantic.DeepEqual(container.Resources, cntr.Spec.Contour.Resources) {
+		t.Errorf("container %q has unexpected resources %v", container.Name, container.Resources)
+	}
 	checkDeploymentHasEnvVar(t, deploy, contourNsEnvVar)
 	checkDeploymentHasEnvVar(t, deploy, contourPodEnvVar)
 	checkDeploymentHasLabels(t, deploy, deploy.Labels)
+	if deploy.Spec.Template.Annotations[ConfigChecksumAnnotation] != "abc123" {
+		t.Errorf("deployment has unexpected pod template annotations %v", deploy.Spec.Template.Annotations)
+	}
 
 	for _, port := range container.Ports {
 		if port.Name == "http" && port.ContainerPort != insecurePort {
@@ -135,4 +184,323 @@ func TestDesiredDeployment(t *testing.T) {
 
 	arg := fmt.Sprintf("--ingress-class-name=%s", *cntr.Spec.IngressClassName)
 	checkContainerHasArg(t, container, arg)
+
+	arg = fmt.Sprintf("--envoy-service-name=%s", objcontour.EnvoyName(cntr))
+	checkContainerHasArg(t, container, arg)
+
+	podSpec := deploy.Spec.Template.Spec
+	if !apiequality.Semantic.DeepEqual(podSpec.NodeSelector, cntr.Spec.Contour.NodePlacement.NodeSelector) {
+		t.Errorf("deployment has unexpected node selector %v", podSpec.NodeSelector)
+	}
+	if !apiequality.Semantic.DeepEqual(podSpec.Affinity, cntr.Spec.Contour.NodePlacement.Affinity) {
+		t.Errorf("deployment has unexpected affinity %v", podSpec.Affinity)
+	}
+	if !apiequality.Semantic.DeepEqual(podSpec.TopologySpreadConstraints, cntr.Spec.Contour.NodePlacement.TopologySpreadConstraints) {
+		t.Errorf("deployment has unexpected topology spread constraints %v", podSpec.TopologySpreadConstraints)
+	}
+
+	if deploy.Name != objcontour.ContourName(cntr) {
+		t.Errorf("deployment has unexpected name %q", deploy.Name)
+	}
+	if deploy.Spec.Selector.MatchLabels[operatorv1alpha1.ContourDeploymentLabel] != cntr.Name {
+		t.Errorf("deployment has unexpected selector %v", deploy.Spec.Selector.MatchLabels)
+	}
+	for _, vol := range deploy.Spec.Template.Spec.Volumes {
+		switch {
+		case vol.Secret != nil && vol.Secret.SecretName != objcontour.ContourCertsSecretName(cntr):
+			t.Errorf("deployment has unexpected secret volume %q", vol.Secret.SecretName)
+		case vol.ConfigMap != nil && vol.ConfigMap.Name != objcontour.ConfigMapName(cntr):
+			t.Errorf("deployment has unexpected configmap volume %q", vol.ConfigMap.Name)
+		}
+	}
+}
+
+func TestDesiredDeploymentIPv6(t *testing.T) {
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "ipv6-test",
+		Namespace:   "ipv6-test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+
+	deploy := DesiredDeployment(cntr, config.DefaultContourImage, nil)
+	container := checkDeploymentHasContainer(t, deploy, ContourContainerName, true)
+	checkContainerHasArg(t, container, "--xds-address=0.0.0.0")
+	for _, arg := range container.Args {
+		if strings.Contains(arg, "::") {
+			t.Errorf("container has unexpected argument %q", arg)
+		}
+	}
+
+	cntr.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv6Protocol}
+	deploy = DesiredDeployment(cntr, config.DefaultContourImage, nil)
+	container = checkDeploymentHasContainer(t, deploy, ContourContainerName, true)
+	for _, arg := range []string{
+		"--xds-address=::",
+		"--http-address=::",
+		"--health-address=::",
+		"--stats-address=::",
+		"--envoy-service-http-address=::",
+		"--envoy-service-https-address=::",
+	} {
+		checkContainerHasArg(t, container, arg)
+	}
+}
+
+func TestDesiredDeploymentPrometheusAnnotations(t *testing.T) {
+	testCases := []struct {
+		description string
+		podMonitors bool
+		expected    bool
+	}{
+		{
+			description: "pod monitors disabled",
+			expected:    true,
+		},
+		{
+			description: "pod monitors enabled",
+			podMonitors: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		cntr := objcontour.New(objcontour.Config{
+			Name:        "annotations-test",
+			Namespace:   "annotations-test-ns",
+			SpecNs:      "projectcontour",
+			NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+		})
+		cntr.Spec.Monitoring.PodMonitors = tc.podMonitors
+		deploy := DesiredDeployment(cntr, config.DefaultContourImage, nil)
+		for _, k := range []string{"prometheus.io/scrape", "prometheus.io/port"} {
+			if _, ok := deploy.Spec.Template.Annotations[k]; ok != tc.expected {
+				t.Errorf("%q: expected annotation %s to exist: %t, got annotations %v", tc.description, k,
+					tc.expected, deploy.Spec.Template.Annotations)
+			}
+		}
+	}
+}
+
+func TestDesiredEnvoyDeployment(t *testing.T) {
+	name := "envoy-deploy-test"
+	cfg := objcontour.Config{
+		Name:              name,
+		Namespace:         fmt.Sprintf("%s-ns", name),
+		SpecNs:            "projectcontour",
+		NetworkType:       operatorv1alpha1.LoadBalancerServicePublishingType,
+		EnvoyWorkloadType: operatorv1alpha1.DeploymentEnvoyWorkloadType,
+		EnvoyReplicas:     int32(3),
+	}
+	cntr := objcontour.New(cfg)
+
+	testContourImage := config.DefaultContourImage
+	testEnvoyImage := config.DefaultEnvoyImage
+	deploy := DesiredEnvoyDeployment(cntr, testContourImage, testEnvoyImage, nil)
+
+	container := checkDeploymentHasContainer(t, deploy, objds.EnvoyContainerName, true)
+	checkContainerHasImage(t, container, testEnvoyImage)
+	container = checkDeploymentHasContainer(t, deploy, objds.ShutdownContainerName, true)
+	checkContainerHasImage(t, container, testContourImage)
+	checkDeploymentHasLabels(t, deploy, deploy.Labels)
+
+	if deploy.Name != objcontour.EnvoyName(cntr) {
+		t.Errorf("deployment has unexpected name %q", deploy.Name)
+	}
+	if deploy.Spec.Replicas == nil || *deploy.Spec.Replicas != cfg.EnvoyReplicas {
+		t.Errorf("deployment has unexpected replicas %v", deploy.Spec.Replicas)
+	}
+	if deploy.Spec.Selector.MatchLabels[operatorv1alpha1.EnvoyPodLabel] != cntr.Name {
+		t.Errorf("deployment has unexpected selector %v", deploy.Spec.Selector.MatchLabels)
+	}
+	if len(deploy.Spec.Template.Spec.InitContainers) != 1 {
+		t.Errorf("deployment has unexpected init containers %v", deploy.Spec.Template.Spec.InitContainers)
+	}
+	if deploy.Spec.Template.Spec.ServiceAccountName != objcontour.EnvoyName(cntr) {
+		t.Errorf("deployment has unexpected service account %q", deploy.Spec.Template.Spec.ServiceAccountName)
+	}
+	if deploy.Spec.Template.Spec.Affinity == nil || deploy.Spec.Template.Spec.Affinity.PodAntiAffinity == nil {
+		t.Errorf("deployment is missing pod anti-affinity")
+	}
+
+	// A user-specified affinity replaces the default pod anti-affinity.
+	cntr.Spec.Envoy.NodePlacement = &operatorv1alpha1.NodePlacement{
+		Affinity: &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
+					{
+						TopologyKey:   "kubernetes.io/hostname",
+						LabelSelector: objds.EnvoyPodSelector(cntr),
+					},
+				},
+			},
+		},
+	}
+	deploy = DesiredEnvoyDeployment(cntr, testContourImage, testEnvoyImage, nil)
+	if !apiequality.Semantic.DeepEqual(deploy.Spec.Template.Spec.Affinity, cntr.Spec.Envoy.NodePlacement.Affinity) {
+		t.Errorf("deployment has unexpected affinity %v", deploy.Spec.Template.Spec.Affinity)
+	}
+}
+
+func TestEnsureDeploymentImage(t *testing.T) {
+	testCases := []struct {
+		description string
+		image       string
+		expected    string
+	}{
+		{
+			description: "unspecified contour image",
+			expected:    config.DefaultContourImage,
+		},
+		{
+			description: "user-specified contour image",
+			image:       "docker.io/projectcontour/contour:main",
+			expected:    "docker.io/projectcontour/contour:main",
+		},
+	}
+
+	for _, tc := range testCases {
+		cntr := objcontour.New(objcontour.Config{
+			Name:        "image-test",
+			Namespace:   "image-test-ns",
+			SpecNs:      "projectcontour",
+			NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+		})
+		cntr.Spec.Contour.Image = tc.image
+		cl := fake.NewClientBuilder().Build()
+		if err := EnsureDeployment(context.TODO(), cl, cntr, config.DefaultContourImage); err != nil {
+			t.Fatalf("%q: failed to ensure deployment: %v", tc.description, err)
+		}
+		deploy, err := CurrentDeployment(context.TODO(), cl, cntr)
+		if err != nil {
+			t.Fatalf("%q: failed to get deployment: %v", tc.description, err)
+		}
+		container := checkDeploymentHasContainer(t, deploy, ContourContainerName, true)
+		checkContainerHasImage(t, container, tc.expected)
+	}
+}
+
+func TestEnsureDeploymentConfigChecksum(t *testing.T) {
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "checksum-test",
+		Namespace:   "checksum-test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	cl := fake.NewClientBuilder().Build()
+
+	checksum := func() string {
+		t.Helper()
+		if err := EnsureDeployment(context.TODO(), cl, cntr, config.DefaultContourImage); err != nil {
+			t.Fatalf("failed to ensure deployment: %v", err)
+		}
+		deploy, err := CurrentDeployment(context.TODO(), cl, cntr)
+		if err != nil {
+			t.Fatalf("failed to get deployment: %v", err)
+		}
+		return deploy.Spec.Template.Annotations[ConfigChecksumAnnotation]
+	}
+
+	// No checksum is added until the configmap exists.
+	if sum := checksum(); sum != "" {
+		t.Errorf("expected no config checksum, got %q", sum)
+	}
+
+	if err := objcm.Ensure(context.TODO(), cl, objcm.NewCfgForContour(cntr)); err != nil {
+		t.Fatalf("failed to ensure configmap: %v", err)
+	}
+	initial := checksum()
+	if initial == "" {
+		t.Fatal("expected a config checksum, got none")
+	}
+	if sum := checksum(); sum != initial {
+		t.Errorf("expected config checksum %q to be unchanged, got %q", initial, sum)
+	}
+
+	cntr.Spec.Config.AccessLogFormat = operatorv1alpha1.JSONAccessLogFormat
+	if err := objcm.Ensure(context.TODO(), cl, objcm.NewCfgForContour(cntr)); err != nil {
+		t.Fatalf("failed to ensure configmap: %v", err)
+	}
+	if sum := checksum(); sum == initial {
+		t.Errorf("expected config checksum to change after a configuration change, got %q", sum)
+	}
+}
+
+func TestOwnedNamespaces(t *testing.T) {
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "owned-test",
+		Namespace:   "owned-test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	other := objcontour.New(objcontour.Config{
+		Name:        "owned-test",
+		Namespace:   "owned-test-ns-2",
+		SpecNs:      "projectcontour-other",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	cl := fake.NewClientBuilder().Build()
+	for _, c := range []*operatorv1alpha1.Contour{cntr, objcontour.InNamespace(cntr, "projectcontour-old"), other} {
+		if err := EnsureDeployment(context.TODO(), cl, c, config.DefaultContourImage); err != nil {
+			t.Fatalf("failed to ensure deployment: %v", err)
+		}
+		if err := EnsureEnvoyDeployment(context.TODO(), cl, c, config.DefaultContourImage, config.DefaultEnvoyImage); err != nil {
+			t.Fatalf("failed to ensure envoy deployment: %v", err)
+		}
+	}
+
+	namespaces, err := OwnedNamespaces(context.TODO(), cl, cntr)
+	if err != nil {
+		t.Fatalf("failed to get owned namespaces: %v", err)
+	}
+	expected := []string{"projectcontour", "projectcontour-old"}
+	if !apiequality.Semantic.DeepEqual(namespaces, expected) {
+		t.Errorf("expected namespaces %v, got %v", expected, namespaces)
+	}
+}
+
+func TestEnsureEnvoyWorkloadTypeChange(t *testing.T) {
+	ctx := context.TODO()
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "workload-test",
+		Namespace:   "workload-test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	cl := fake.NewClientBuilder().Build()
+	if kept, err := EnsureEnvoyWorkload(ctx, cl, cntr, config.DefaultContourImage, config.DefaultEnvoyImage); err != nil || kept {
+		t.Fatalf("failed to ensure envoy daemonset: kept %t, %v", kept, err)
+	}
+
+	// The daemonset is kept until the deployment is available.
+	cntr.Spec.Envoy.WorkloadType = operatorv1alpha1.DeploymentEnvoyWorkloadType
+	kept, err := EnsureEnvoyWorkload(ctx, cl, cntr, config.DefaultContourImage, config.DefaultEnvoyImage)
+	if err != nil {
+		t.Fatalf("failed to ensure envoy deployment: %v", err)
+	}
+	if !kept {
+		t.Errorf("expected the envoy daemonset to be kept")
+	}
+	if _, err := objds.CurrentDaemonSet(ctx, cl, cntr); err != nil {
+		t.Errorf("expected the envoy daemonset to exist until the deployment is available: %v", err)
+	}
+	deploy, err := CurrentEnvoyDeployment(ctx, cl, cntr)
+	if err != nil {
+		t.Fatalf("failed to get envoy deployment: %v", err)
+	}
+
+	// The daemonset is deleted once the deployment is available.
+	deploy.Status.AvailableReplicas = int32(1)
+	if err := cl.Status().Update(ctx, deploy); err != nil {
+		t.Fatalf("failed to update envoy deployment status: %v", err)
+	}
+	kept, err = EnsureEnvoyWorkload(ctx, cl, cntr, config.DefaultContourImage, config.DefaultEnvoyImage)
+	if err != nil {
+		t.Fatalf("failed to ensure envoy deployment: %v", err)
+	}
+	if kept {
+		t.Errorf("expected the envoy daemonset not to be kept")
+	}
+	if _, err := objds.CurrentDaemonSet(ctx, cl, cntr); !errors.IsNotFound(err) {
+		t.Errorf("expected the envoy daemonset to be deleted, got %v", err)
+	}
 }
@@ -0,0 +1,206 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	"github.com/projectcontour/contour-operator/internal/equality"
+	opintstr "github.com/projectcontour/contour-operator/internal/intstr"
+	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+	objds "github.com/projectcontour/contour-operator/internal/objects/daemonset"
+	objsecret "github.com/projectcontour/contour-operator/internal/objects/secret"
+	"github.com/projectcontour/contour-operator/pkg/labels"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EnsureEnvoyDeployment ensures an Envoy deployment exists for the given contour,
+// using contourImage as the shutdown-manager/envoy-initconfig container images
+// and envoyImage as Envoy's container image, unless spec.contour.image or
+// spec.envoy.image are set.
+func EnsureEnvoyDeployment(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, contourImage, envoyImage string) error {
+	annotations, err := objsecret.CertAnnotations(ctx, cli, contour, objcontour.EnvoyXDSSecretName(contour))
+	if err != nil {
+		return err
+	}
+	desired := DesiredEnvoyDeployment(contour, objcontour.ContourImage(contour, contourImage),
+		objcontour.EnvoyImage(contour, envoyImage), annotations)
+	current, err := CurrentEnvoyDeployment(ctx, cli, contour)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return createDeployment(ctx, cli, desired)
+		}
+		return fmt.Errorf("failed to get deployment %s/%s: %w", desired.Namespace, desired.Name, err)
+	}
+	differ := equality.DeploymentSelectorsDiffer(current, desired)
+	if differ {
+		return EnsureEnvoyDeploymentDeleted(ctx, cli, contour)
+	}
+	if err := updateDeploymentIfNeeded(ctx, cli, contour, current, desired); err != nil {
+		return fmt.Errorf("failed to update deployment %s/%s: %w", desired.Namespace, desired.Name, err)
+	}
+	return nil
+}
+
+// EnsureEnvoyWorkload ensures the Envoy DaemonSet or Deployment of the provided
+// contour, as specified by spec.envoy.workloadType, using contourImage and
+// envoyImage as described by EnsureEnvoyDeployment. The Envoy workload of the
+// other type is deleted only once the ensured workload has available replicas,
+// so Envoy keeps serving traffic while the workload type changes. Returns true
+// if the workload of the other type is kept until then.
+func EnsureEnvoyWorkload(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, contourImage, envoyImage string) (bool, error) {
+	switch contour.Spec.Envoy.WorkloadType {
+	case operatorv1alpha1.DeploymentEnvoyWorkloadType:
+		if err := EnsureEnvoyDeployment(ctx, cli, contour, contourImage, envoyImage); err != nil {
+			return false, err
+		}
+		deploy, err := CurrentEnvoyDeployment(ctx, cli, contour)
+		if err != nil && !errors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to get envoy deployment for contour %s/%s: %w", contour.Namespace,
+				contour.Name, err)
+		}
+		if deploy == nil || deploy.Status.AvailableReplicas == 0 {
+			_, err := objds.CurrentDaemonSet(ctx, cli, contour)
+			switch {
+			case err == nil:
+				return true, nil
+			case errors.IsNotFound(err):
+				return false, nil
+			default:
+				return false, fmt.Errorf("failed to get daemonset for contour %s/%s: %w", contour.Namespace,
+					contour.Name, err)
+			}
+		}
+		return false, objds.EnsureDaemonSetDeleted(ctx, cli, contour)
+	default:
+		if err := objds.EnsureDaemonSet(ctx, cli, contour, contourImage, envoyImage); err != nil {
+			return false, err
+		}
+		ds, err := objds.CurrentDaemonSet(ctx, cli, contour)
+		if err != nil && !errors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to get daemonset for contour %s/%s: %w", contour.Namespace,
+				contour.Name, err)
+		}
+		if ds == nil || ds.Status.NumberAvailable == 0 {
+			_, err := CurrentEnvoyDeployment(ctx, cli, contour)
+			switch {
+			case err == nil:
+				return true, nil
+			case errors.IsNotFound(err):
+				return false, nil
+			default:
+				return false, fmt.Errorf("failed to get envoy deployment for contour %s/%s: %w", contour.Namespace,
+					contour.Name, err)
+			}
+		}
+		return false, EnsureEnvoyDeploymentDeleted(ctx, cli, contour)
+	}
+}
+
+// EnsureEnvoyDeploymentDeleted ensures the Envoy deployment for the provided
+// contour is deleted if Contour owner labels exist.
+func EnsureEnvoyDeploymentDeleted(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
+	deploy, err := CurrentEnvoyDeployment(ctx, cli, contour)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if labels.Exist(deploy, objcontour.OwnerLabels(contour)) {
+		if err := cli.Delete(ctx, deploy); err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// DesiredEnvoyDeployment returns the desired Envoy deployment for the provided
+// contour using contourImage as the shutdown-manager/envoy-initconfig container
+// images and envoyImage as Envoy's container image. The pods of the deployment
+// are identical to the pods of the Envoy DaemonSet. The provided annotations are
+// added to the pod template, so a change of their values triggers a rolling update.
+func DesiredEnvoyDeployment(contour *operatorv1alpha1.Contour, contourImage, envoyImage string, annotations map[string]string) *appsv1.Deployment {
+	template := objds.DesiredEnvoyPodTemplate(contour, contourImage, envoyImage, annotations)
+	// Spread Envoy pods across nodes, since a Deployment does not schedule
+	// a pod to every node like a DaemonSet, unless the user specified an
+	// affinity through node placement.
+	if template.Spec.Affinity == nil {
+		template.Spec.Affinity = &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+					{
+						Weight: int32(100),
+						PodAffinityTerm: corev1.PodAffinityTerm{
+							TopologyKey: "kubernetes.io/hostname",
+							LabelSelector: &metav1.LabelSelector{
+								MatchLabels: objds.EnvoyPodSelector(contour).MatchLabels,
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: contour.Spec.Namespace.Name,
+			Name:      objcontour.EnvoyName(contour),
+			Labels:    makeDeploymentLabels(contour),
+		},
+		Spec: appsv1.DeploymentSpec{
+			ProgressDeadlineSeconds: pointer.Int32Ptr(int32(600)),
+			Replicas:                &contour.Spec.Envoy.Replicas,
+			RevisionHistoryLimit:    pointer.Int32Ptr(int32(10)),
+			// Ensure the deployment adopts only its own pods.
+			Selector: objds.EnvoyPodSelector(contour),
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{
+					MaxSurge:       opintstr.PointerTo(intstr.FromString("10%")),
+					MaxUnavailable: opintstr.PointerTo(intstr.FromString("10%")),
+				},
+			},
+			Template: template,
+		},
+	}
+	return deploy
+}
+
+// CurrentEnvoyDeployment returns the Envoy Deployment resource for the provided contour.
+func CurrentEnvoyDeployment(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) (*appsv1.Deployment, error) {
+	deploy := &appsv1.Deployment{}
+	key := types.NamespacedName{
+		Namespace: contour.Spec.Namespace.Name,
+		Name:      objcontour.EnvoyName(contour),
+	}
+	if err := cli.Get(ctx, key, deploy); err != nil {
+		return nil, err
+	}
+	return deploy, nil
+}
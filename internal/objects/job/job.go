@@ -16,20 +16,25 @@ package job
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
 	"github.com/projectcontour/contour-operator/internal/equality"
 	objutil "github.com/projectcontour/contour-operator/internal/objects"
 	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
-	"github.com/projectcontour/contour-operator/internal/operator/config"
+	objsecret "github.com/projectcontour/contour-operator/internal/objects/secret"
+	"github.com/projectcontour/contour-operator/internal/operator/metrics/drift"
+	retryable "github.com/projectcontour/contour-operator/internal/retryableerror"
 	labels "github.com/projectcontour/contour-operator/pkg/labels"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
+	utilclock "k8s.io/apimachinery/pkg/util/clock"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -38,66 +43,104 @@ import (
 const (
 	jobContainerName = "contour"
 	jobNsEnvVar      = "CONTOUR_NAMESPACE"
+	// jobAppName is the app.kubernetes.io/name label of the certgen Job, used
+	// with the Contour owner labels to select the certgen Jobs of a contour.
+	jobAppName = "contour-certgen"
+	// imageHashLabel is the label of the certgen Job containing the hash of
+	// the certgen container image of the Job.
+	imageHashLabel = "contour.operator.projectcontour.io/certgen-image-hash"
+	// attemptAnnotation is the annotation of the certgen Job that counts the
+	// attempts to run the Job since its spec last changed.
+	attemptAnnotation = "contour.operator.projectcontour.io/certgen-attempt"
+	// initialBackoff is the period after which the first failed attempt to
+	// run the certgen Job is retried. The period doubles with each attempt,
+	// up to maxBackoff.
+	initialBackoff = 10 * time.Second
+	maxBackoff     = 5 * time.Minute
 )
 
-var (
-	// certgenJobName is the name of Certgen's Job resource.
-	// [TODO] danehans: Remove and use contour.Name + "-certgen" when
-	// https://github.com/projectcontour/contour/issues/2122 is fixed.
-	certgenJobName = "contour-certgen-" + objutil.TagFromImage(config.DefaultContourImage)
-)
+// clock is to enable unit testing
+var clock utilclock.Clock = utilclock.RealClock{}
+
+// startFailureReasons are the reasons of a waiting container that prevent the
+// container from ever starting without user intervention.
+var startFailureReasons = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+}
 
-// EnsureJob ensures that a Job exists for the given contour.
+// EnsureJob ensures that a Job exists for the given contour, using image as
+// the certgen container image unless spec.contour.image is set. A failed Job
+// is recreated once the backoff period of its attempt has elapsed; until then,
+// a retryable error describing the failure is returned. The certgen Jobs of
+// contour for other images, e.g. the default image of a previous version of
+// the operator, are deleted. Once the Job has succeeded, the secrets generated
+// by certgen are labeled as described by objsecret.EnsureCertsLabeled.
 // TODO [danehans]: The real dependency is whether the TLS secrets are present.
 // The method should first check for the secrets, then use certgen as a secret
 // generating strategy.
 func EnsureJob(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, image string) error {
-	desired := DesiredJob(contour, image)
-	current, err := currentJob(ctx, cli, contour)
-	if err != nil {
+	desired := DesiredJob(contour, objcontour.ContourImage(contour, image))
+	if err := ensureStaleJobsDeleted(ctx, cli, contour, desired.Name); err != nil {
+		return err
+	}
+	current := &batchv1.Job{}
+	if err := cli.Get(ctx, client.ObjectKeyFromObject(desired), current); err != nil {
 		if errors.IsNotFound(err) {
 			return createJob(ctx, cli, desired)
 		}
 		return fmt.Errorf("failed to get job %s/%s: %w", desired.Namespace, desired.Name, err)
 	}
-	if err := recreateJobIfNeeded(ctx, cli, contour, current, desired); err != nil {
+	recreated, err := recreateJobIfNeeded(ctx, cli, contour, current, desired)
+	if err != nil {
 		return fmt.Errorf("failed to recreate job %s/%s: %w", desired.Namespace, desired.Name, err)
 	}
-	return nil
+	if recreated {
+		return nil
+	}
+	if current.Status.Succeeded > 0 {
+		return objsecret.EnsureCertsLabeled(ctx, cli, contour)
+	}
+	return retryJobIfFailed(ctx, cli, contour, current, desired)
 }
 
-// EnsureJobDeleted ensures the Job for the provided contour is deleted if
-// Contour owner labels exist.
+// EnsureJobDeleted ensures the certgen Jobs for the provided contour are
+// deleted if Contour owner labels exist.
 func EnsureJobDeleted(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
-	job, err := currentJob(ctx, cli, contour)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return nil
-		}
-		return err
-	}
-	if labels.Exist(job, objcontour.OwnerLabels(contour)) {
-		if err := cli.Delete(ctx, job); err != nil {
-			if !errors.IsNotFound(err) {
-				return err
-			}
-			return nil
-		}
-	}
-	return nil
+	return ensureStaleJobsDeleted(ctx, cli, contour, "")
 }
 
-// currentJob returns the current Job resource named name for the provided contour.
-func currentJob(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) (*batchv1.Job, error) {
-	current := &batchv1.Job{}
-	key := types.NamespacedName{
-		Namespace: contour.Spec.Namespace.Name,
-		Name:      certgenJobName,
+// CurrentJobs returns the certgen Jobs containing the Contour owner labels of
+// the provided contour, including Jobs for previous images.
+func CurrentJobs(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) ([]batchv1.Job, error) {
+	selector := objcontour.OwnerLabels(contour)
+	selector["app.kubernetes.io/name"] = jobAppName
+	jobs := &batchv1.JobList{}
+	ns := contour.Spec.Namespace.Name
+	if err := cli.List(ctx, jobs, client.InNamespace(ns), client.MatchingLabels(selector)); err != nil {
+		return nil, fmt.Errorf("failed to list jobs in namespace %s: %w", ns, err)
 	}
-	err := cli.Get(ctx, key, current)
+	return jobs.Items, nil
+}
+
+// CurrentJob returns the most recently created certgen Job resource for the
+// provided contour, or a NotFound error if the contour has no certgen Job.
+func CurrentJob(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) (*batchv1.Job, error) {
+	jobs, err := CurrentJobs(ctx, cli, contour)
 	if err != nil {
 		return nil, err
 	}
+	var current *batchv1.Job
+	for i := range jobs {
+		if current == nil || current.CreationTimestamp.Before(&jobs[i].CreationTimestamp) {
+			current = &jobs[i]
+		}
+	}
+	if current == nil {
+		return nil, errors.NewNotFound(batchv1.Resource("jobs"), objcontour.CertGenName(contour))
+	}
 	return current, nil
 }
 
@@ -116,6 +159,7 @@ func DesiredJob(contour *operatorv1alpha1.Contour, image string) *batchv1.Job {
 		Name:            jobContainerName,
 		Image:           image,
 		ImagePullPolicy: corev1.PullAlways,
+		Resources:       contour.Spec.CertGen.Resources,
 		Command: []string{
 			"contour",
 			"certgen",
@@ -123,16 +167,18 @@ func DesiredJob(contour *operatorv1alpha1.Contour, image string) *batchv1.Job {
 			"--incluster",
 			"--overwrite",
 			"--secrets-format=compact",
+			fmt.Sprintf("--secrets-name-suffix=%s", objcontour.CertsSecretNameSuffix(contour)),
 			fmt.Sprintf("--namespace=$(%s)", jobNsEnvVar),
 		},
-		Env:                      []corev1.EnvVar{env},
-		TerminationMessagePath:   "/dev/termination-log",
-		TerminationMessagePolicy: "File",
+		Env:                    []corev1.EnvVar{env},
+		TerminationMessagePath: "/dev/termination-log",
+		// Surface the certgen error log as the termination message.
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 	}
 	spec := corev1.PodSpec{
 		Containers:                    []corev1.Container{container},
-		DeprecatedServiceAccount:      objutil.CertGenRbacName,
-		ServiceAccountName:            objutil.CertGenRbacName,
+		DeprecatedServiceAccount:      objcontour.CertGenName(contour),
+		ServiceAccountName:            objcontour.CertGenName(contour),
 		SecurityContext:               objutil.NewUnprivilegedPodSecurity(),
 		RestartPolicy:                 corev1.RestartPolicyNever,
 		DNSPolicy:                     corev1.DNSClusterFirst,
@@ -142,7 +188,7 @@ func DesiredJob(contour *operatorv1alpha1.Contour, image string) *batchv1.Job {
 	// TODO [danehans] certgen needs to be updated to match these labels.
 	// See https://github.com/projectcontour/contour/issues/1821 for details.
 	labels := map[string]string{
-		"app.kubernetes.io/name":       "contour-certgen",
+		"app.kubernetes.io/name":       jobAppName,
 		"app.kubernetes.io/instance":   contour.Name,
 		"app.kubernetes.io/component":  "ingress-controller",
 		"app.kubernetes.io/part-of":    "project-contour",
@@ -150,10 +196,11 @@ func DesiredJob(contour *operatorv1alpha1.Contour, image string) *batchv1.Job {
 		// associate the job with the provided contour.
 		operatorv1alpha1.OwningContourNameLabel: contour.Name,
 		operatorv1alpha1.OwningContourNsLabel:   contour.Namespace,
+		imageHashLabel:                          objcontour.ImageHash(image),
 	}
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      certgenJobName,
+			Name:      objcontour.CertGenJobName(contour, image),
 			Namespace: contour.Spec.Namespace.Name,
 			Labels:    labels,
 		},
@@ -161,8 +208,6 @@ func DesiredJob(contour *operatorv1alpha1.Contour, image string) *batchv1.Job {
 			Parallelism:  pointer.Int32Ptr(int32(1)),
 			Completions:  pointer.Int32Ptr(int32(1)),
 			BackoffLimit: pointer.Int32Ptr(int32(1)),
-			// Make job eligible to for immediate deletion (feature gate dependent).
-			TTLSecondsAfterFinished: pointer.Int32Ptr(int32(0)),
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: objcontour.OwningSelector(contour).MatchLabels,
@@ -174,22 +219,175 @@ func DesiredJob(contour *operatorv1alpha1.Contour, image string) *batchv1.Job {
 	return job
 }
 
+// Failed returns true if job has failed, i.e. exceeded its backoff limit.
+func Failed(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// Failure returns a message describing the failure of job and true if job has
+// failed or a container of its pods can't be started, e.g. due to an image pull
+// error. The message includes the termination messages of the failed containers
+// of the pods of job.
+func Failure(ctx context.Context, cli client.Client, job *batchv1.Job) (string, bool, error) {
+	var msgs []string
+	seen := map[string]bool{}
+	addMsg := func(msg string) {
+		if !seen[msg] {
+			seen[msg] = true
+			msgs = append(msgs, msg)
+		}
+	}
+	failed := false
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			failed = true
+			addMsg(fmt.Sprintf("%s: %s", cond.Reason, cond.Message))
+		}
+	}
+	pods, err := currentPods(ctx, cli, job)
+	if err != nil {
+		return "", false, err
+	}
+	for _, pod := range pods {
+		for _, status := range pod.Status.ContainerStatuses {
+			switch {
+			case status.State.Waiting != nil && startFailureReasons[status.State.Waiting.Reason]:
+				failed = true
+				addMsg(fmt.Sprintf("container %s: %s: %s", status.Name, status.State.Waiting.Reason,
+					status.State.Waiting.Message))
+			case status.State.Terminated != nil && status.State.Terminated.ExitCode != 0:
+				msg := strings.TrimSpace(status.State.Terminated.Message)
+				if len(msg) == 0 {
+					msg = fmt.Sprintf("exited with code %d", status.State.Terminated.ExitCode)
+				}
+				addMsg(fmt.Sprintf("container %s: %s", status.Name, msg))
+			}
+		}
+	}
+	if !failed {
+		return "", false, nil
+	}
+	return strings.Join(msgs, "; "), true, nil
+}
+
+// currentPods returns the current pods of job, using the selector the API
+// server sets on job.
+func currentPods(ctx context.Context, cli client.Client, job *batchv1.Job) ([]corev1.Pod, error) {
+	if job.Spec.Selector == nil {
+		return nil, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector of job %s/%s: %w", job.Namespace, job.Name, err)
+	}
+	pods := &corev1.PodList{}
+	if err := cli.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list pods of job %s/%s: %w", job.Namespace, job.Name, err)
+	}
+	return pods.Items, nil
+}
+
+// attempt returns the attempt to run job, starting at 1.
+func attempt(job *batchv1.Job) int {
+	n, err := strconv.Atoi(job.Annotations[attemptAnnotation])
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+// backoff returns the period after the creation of the Job of attempt after
+// which the Job is retried if it failed.
+func backoff(attempt int) time.Duration {
+	d := initialBackoff
+	for i := 1; i < attempt && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		return maxBackoff
+	}
+	return d
+}
+
 // recreateJobIfNeeded recreates a Job if current doesn't match desired,
-// using contour to verify the existence of owner labels.
-func recreateJobIfNeeded(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, current, desired *batchv1.Job) error {
+// using contour to verify the existence of owner labels. Returns true if
+// the Job was recreated.
+func recreateJobIfNeeded(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, current, desired *batchv1.Job) (bool, error) {
 	if labels.Exist(current, objcontour.OwnerLabels(contour)) {
 		updated, changed := equality.JobConfigChanged(current, desired)
 		if !changed {
-			return nil
+			return false, nil
 		}
 		if err := cli.Delete(ctx, updated); err != nil {
 			if !errors.IsNotFound(err) {
-				return err
+				return false, err
 			}
 		}
 		// Retry is needed since the object may still be getting deleted.
 		if err := retryJobCreate(ctx, cli, updated, time.Second*3); err != nil {
-			return err
+			return false, err
+		}
+		drift.ObserveCorrection(cli, updated)
+		return true, nil
+	}
+	return false, nil
+}
+
+// retryJobIfFailed recreates current, the certgen Job of contour, from desired
+// as the next attempt if current failed and the backoff period of its attempt
+// has elapsed. Returns a retryable error describing the failure while waiting
+// for the backoff period to elapse.
+func retryJobIfFailed(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, current, desired *batchv1.Job) error {
+	if !labels.Exist(current, objcontour.OwnerLabels(contour)) {
+		return nil
+	}
+	msg, failed, err := Failure(ctx, cli, current)
+	if err != nil || !failed {
+		return err
+	}
+	n := attempt(current)
+	if remaining := backoff(n) - clock.Since(current.CreationTimestamp.Time); remaining > 0 {
+		return retryable.New(fmt.Errorf("job %s/%s failed attempt %d: %s; retrying in %s", current.Namespace,
+			current.Name, n, msg, remaining.Round(time.Second)), remaining)
+	}
+	if err := deleteJob(ctx, cli, current); err != nil {
+		return err
+	}
+	retry := desired.DeepCopy()
+	retry.Annotations = map[string]string{attemptAnnotation: strconv.Itoa(n + 1)}
+	// Retry is needed since the object may still be getting deleted.
+	return retryJobCreate(ctx, cli, retry, time.Second*3)
+}
+
+// ensureStaleJobsDeleted ensures the certgen Jobs of the provided contour other
+// than the Job named name are deleted.
+func ensureStaleJobsDeleted(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, name string) error {
+	jobs, err := CurrentJobs(ctx, cli, contour)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i := range jobs {
+		if jobs[i].Name == name {
+			continue
+		}
+		if err := deleteJob(ctx, cli, &jobs[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// deleteJob deletes the provided job along with its pods.
+func deleteJob(ctx context.Context, cli client.Client, job *batchv1.Job) error {
+	if err := cli.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete job %s/%s: %w", job.Namespace, job.Name, err)
 		}
 	}
 	return nil
@@ -14,15 +14,26 @@
 package job
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
 	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
 	operatorconfig "github.com/projectcontour/contour-operator/internal/operator/config"
+	retryable "github.com/projectcontour/contour-operator/internal/retryableerror"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilclock "k8s.io/apimachinery/pkg/util/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func checkJobHasEnvVar(t *testing.T, job *batchv1.Job, name string) {
@@ -57,6 +68,17 @@ func checkContainerHasImage(t *testing.T, container *corev1.Container, image str
 	t.Errorf("container is missing image %q", image)
 }
 
+func checkContainerHasCommand(t *testing.T, container *corev1.Container, cmd string) {
+	t.Helper()
+
+	for _, c := range container.Command {
+		if c == cmd {
+			return
+		}
+	}
+	t.Errorf("container is missing command %q", cmd)
+}
+
 func TestDesiredJob(t *testing.T) {
 	name := "job-test"
 	cfg := objcontour.Config{
@@ -67,8 +89,347 @@ func TestDesiredJob(t *testing.T) {
 		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
 	}
 	cntr := objcontour.New(cfg)
+	cntr.Spec.CertGen.Resources = corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("100m"),
+		},
+	}
 	job := DesiredJob(cntr, operatorconfig.DefaultContourImage)
 	container := checkJobHasContainer(t, job, jobContainerName)
 	checkContainerHasImage(t, container, operatorconfig.DefaultContourImage)
 	checkJobHasEnvVar(t, job, jobNsEnvVar)
+	checkContainerHasCommand(t, container, fmt.Sprintf("--secrets-name-suffix=-%s", name))
+	if !apiequality.Semantic.DeepEqual(container.Resources, cntr.Spec.CertGen.Resources) {
+		t.Errorf("job container has unexpected resources %v", container.Resources)
+	}
+	if job.Spec.Template.Spec.ServiceAccountName != objcontour.CertGenName(cntr) {
+		t.Errorf("job has unexpected service account %q", job.Spec.Template.Spec.ServiceAccountName)
+	}
+}
+
+func TestCertgenJobName(t *testing.T) {
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "job-test",
+		Namespace:   "job-test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	images := []string{
+		operatorconfig.DefaultContourImage,
+		"docker.io/projectcontour/contour:v1.15.1",
+		"registry.example.com:5000/contour:main",
+		"docker.io/projectcontour/contour@sha256:1111111111111111111111111111111111111111111111111111111111111111",
+		"docker.io/projectcontour/contour@sha256:2222222222222222222222222222222222222222222222222222222222222222",
+	}
+	names := map[string]string{}
+	for _, image := range images {
+		job := DesiredJob(cntr, image)
+		if !strings.HasPrefix(job.Name, objcontour.CertGenName(cntr)+"-") {
+			t.Errorf("job for image %q has unexpected name %q", image, job.Name)
+		}
+		if strings.ContainsAny(job.Name, ":@/.") || len(job.Name) > 63 {
+			t.Errorf("job for image %q has invalid name %q", image, job.Name)
+		}
+		if job.Labels[imageHashLabel] != objcontour.ImageHash(image) {
+			t.Errorf("job for image %q has unexpected labels %v", image, job.Labels)
+		}
+		if other, ok := names[job.Name]; ok {
+			t.Errorf("images %q and %q have the same job name %q", other, image, job.Name)
+		}
+		names[job.Name] = image
+		if DesiredJob(cntr, image).Name != job.Name {
+			t.Errorf("expected job name for image %q to be stable", image)
+		}
+	}
+}
+
+func TestEnsureJobDeletesStaleJobs(t *testing.T) {
+	ctx := context.Background()
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "job-test",
+		Namespace:   "job-test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	ns := cntr.Spec.Namespace.Name
+	// A job of a previous operator version, named from the tag of its default image.
+	previous := DesiredJob(cntr, "docker.io/projectcontour/contour:v1.14.0")
+	previous.Name = objcontour.CertGenName(cntr) + "-v1.14.0"
+	delete(previous.Labels, imageHashLabel)
+	// A job of another contour of the same name.
+	other := DesiredJob(cntr, "docker.io/projectcontour/contour:v1.14.0")
+	other.Labels[operatorv1alpha1.OwningContourNsLabel] = "other-ns"
+	other.Name = "other-certgen"
+	// A job unrelated to certgen.
+	unrelated := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ns,
+			Name:      "unrelated",
+			Labels:    objcontour.OwnerLabels(cntr),
+		},
+	}
+	cli := fake.NewClientBuilder().WithObjects(previous, other, unrelated).Build()
+
+	image := "docker.io/projectcontour/contour@sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	if err := EnsureJob(ctx, cli, cntr, image); err != nil {
+		t.Fatalf("failed to ensure job: %v", err)
+	}
+	list := &batchv1.JobList{}
+	if err := cli.List(ctx, list, client.InNamespace(ns)); err != nil {
+		t.Fatalf("failed to list jobs: %v", err)
+	}
+	names := map[string]bool{}
+	for _, job := range list.Items {
+		names[job.Name] = true
+	}
+	expected := map[string]bool{objcontour.CertGenJobName(cntr, image): true, other.Name: true, unrelated.Name: true}
+	if !apiequality.Semantic.DeepEqual(names, expected) {
+		t.Errorf("expected jobs %v, got %v", expected, names)
+	}
+	job, err := CurrentJob(ctx, cli, cntr)
+	if err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if job.Name != objcontour.CertGenJobName(cntr, image) {
+		t.Errorf("expected current job %s, got %s", objcontour.CertGenJobName(cntr, image), job.Name)
+	}
+
+	// Deleting the jobs of the contour only deletes its certgen jobs.
+	if err := EnsureJobDeleted(ctx, cli, cntr); err != nil {
+		t.Fatalf("failed to delete jobs: %v", err)
+	}
+	if _, err := CurrentJob(ctx, cli, cntr); !errors.IsNotFound(err) {
+		t.Errorf("expected certgen jobs to be deleted, got %v", err)
+	}
+	if err := cli.List(ctx, list, client.InNamespace(ns)); err != nil {
+		t.Fatalf("failed to list jobs: %v", err)
+	}
+	if len(list.Items) != 2 {
+		t.Errorf("expected the jobs of other owners to be kept, got %d jobs", len(list.Items))
+	}
+}
+
+// failedJob returns the desired certgen Job of contour, created at created and
+// failed, with its selector set as by the API server.
+func failedJob(contour *operatorv1alpha1.Contour, created time.Time) *batchv1.Job {
+	job := DesiredJob(contour, objcontour.ContourImage(contour, operatorconfig.DefaultContourImage))
+	job.CreationTimestamp = metav1.NewTime(created)
+	job.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"controller-uid": "test-uid"}}
+	job.Status.Conditions = []batchv1.JobCondition{{
+		Type:    batchv1.JobFailed,
+		Status:  corev1.ConditionTrue,
+		Reason:  "BackoffLimitExceeded",
+		Message: "Job has reached the specified backoff limit",
+	}}
+	return job
+}
+
+func TestFailure(t *testing.T) {
+	ctx := context.Background()
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "job-test",
+		Namespace:   "job-test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	pod := func(status corev1.ContainerStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cntr.Spec.Namespace.Name,
+				Name:      fmt.Sprintf("certgen-%s", strings.ToLower(status.Name)),
+				Labels:    map[string]string{"controller-uid": "test-uid"},
+			},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{status}},
+		}
+	}
+	running := failedJob(cntr, time.Now())
+	running.Status.Conditions = nil
+
+	testCases := []struct {
+		description   string
+		job           *batchv1.Job
+		pods          []client.Object
+		expectFailed  bool
+		expectMessage []string
+	}{
+		{
+			description: "running job",
+			job:         running,
+		},
+		{
+			description: "failed job",
+			job:         failedJob(cntr, time.Now()),
+			pods: []client.Object{pod(corev1.ContainerStatus{
+				Name: jobContainerName,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 1,
+					Message:  "secrets is forbidden\n",
+				}},
+			})},
+			expectFailed:  true,
+			expectMessage: []string{"BackoffLimitExceeded", "container contour: secrets is forbidden"},
+		},
+		{
+			description: "failed job without termination message",
+			job:         failedJob(cntr, time.Now()),
+			pods: []client.Object{pod(corev1.ContainerStatus{
+				Name:  jobContainerName,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 2}},
+			})},
+			expectFailed:  true,
+			expectMessage: []string{"exited with code 2"},
+		},
+		{
+			description: "image pull error",
+			job:         running,
+			pods: []client.Object{pod(corev1.ContainerStatus{
+				Name: jobContainerName,
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+					Reason:  "ImagePullBackOff",
+					Message: "Back-off pulling image",
+				}},
+			})},
+			expectFailed:  true,
+			expectMessage: []string{"container contour: ImagePullBackOff: Back-off pulling image"},
+		},
+		{
+			description: "container creating",
+			job:         running,
+			pods: []client.Object{pod(corev1.ContainerStatus{
+				Name:  jobContainerName,
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+			})},
+		},
+	}
+
+	for _, tc := range testCases {
+		cli := fake.NewClientBuilder().WithObjects(tc.pods...).Build()
+		msg, failed, err := Failure(ctx, cli, tc.job)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.description, err)
+		}
+		if failed != tc.expectFailed {
+			t.Errorf("%q: expected failed %t, got %t", tc.description, tc.expectFailed, failed)
+		}
+		for _, expected := range tc.expectMessage {
+			if !strings.Contains(msg, expected) {
+				t.Errorf("%q: expected message %q to contain %q", tc.description, msg, expected)
+			}
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	expected := map[int]time.Duration{
+		1:  initialBackoff,
+		2:  2 * initialBackoff,
+		3:  4 * initialBackoff,
+		10: maxBackoff,
+		64: maxBackoff,
+	}
+	for attempt, d := range expected {
+		if got := backoff(attempt); got != d {
+			t.Errorf("expected backoff %s for attempt %d, got %s", d, attempt, got)
+		}
+	}
+}
+
+func TestEnsureJobRetriesFailedJob(t *testing.T) {
+	// Inject a fake clock and don't forget to reset it
+	start := time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)
+	fakeClock := utilclock.NewFakeClock(start)
+	clock = fakeClock
+	defer func() {
+		clock = utilclock.RealClock{}
+	}()
+
+	ctx := context.Background()
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "job-test",
+		Namespace:   "job-test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	cli := fake.NewClientBuilder().WithObjects(failedJob(cntr, start)).Build()
+
+	// The failed job is kept until the backoff period of its attempt elapsed.
+	fakeClock.Step(initialBackoff / 2)
+	err := EnsureJob(ctx, cli, cntr, operatorconfig.DefaultContourImage)
+	e, ok := err.(retryable.Error)
+	if !ok {
+		t.Fatalf("expected a retryable error, got %v", err)
+	}
+	if e.After() != initialBackoff/2 {
+		t.Errorf("expected retry after %s, got %s", initialBackoff/2, e.After())
+	}
+	if !strings.Contains(err.Error(), "BackoffLimitExceeded") {
+		t.Errorf("expected error to describe the failure, got %v", err)
+	}
+
+	// The failed job is recreated as the next attempt.
+	fakeClock.Step(initialBackoff / 2)
+	if err := EnsureJob(ctx, cli, cntr, operatorconfig.DefaultContourImage); err != nil {
+		t.Fatalf("failed to retry job: %v", err)
+	}
+	job, err := CurrentJob(ctx, cli, cntr)
+	if err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if Failed(job) {
+		t.Errorf("expected the failed job to be replaced")
+	}
+	if attempt(job) != 2 {
+		t.Errorf("expected attempt 2, got %d", attempt(job))
+	}
+
+	// A job that did not fail is left alone.
+	if err := EnsureJob(ctx, cli, cntr, operatorconfig.DefaultContourImage); err != nil {
+		t.Errorf("failed to ensure job: %v", err)
+	}
+}
+
+func TestEnsureJobLabelsSecrets(t *testing.T) {
+	ctx := context.Background()
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "job-test",
+		Namespace:   "job-test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	ns := cntr.Spec.Namespace.Name
+	name := objcontour.ContourCertsSecretName(cntr)
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name}}
+	cli := fake.NewClientBuilder().WithObjects(secret).Build()
+	key := client.ObjectKeyFromObject(secret)
+
+	// The secrets are not labeled until the job succeeded.
+	if err := EnsureJob(ctx, cli, cntr, operatorconfig.DefaultContourImage); err != nil {
+		t.Fatalf("failed to ensure job: %v", err)
+	}
+	if err := cli.Get(ctx, key, secret); err != nil {
+		t.Fatalf("failed to get secret %s: %v", name, err)
+	}
+	if len(secret.Labels) != 0 {
+		t.Errorf("expected secret %s not to be labeled, got %v", name, secret.Labels)
+	}
+
+	job, err := CurrentJob(ctx, cli, cntr)
+	if err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	job.Status.Succeeded = 1
+	if err := cli.Status().Update(ctx, job); err != nil {
+		t.Fatalf("failed to update job: %v", err)
+	}
+	if err := EnsureJob(ctx, cli, cntr, operatorconfig.DefaultContourImage); err != nil {
+		t.Fatalf("failed to ensure job: %v", err)
+	}
+	if err := cli.Get(ctx, key, secret); err != nil {
+		t.Fatalf("failed to get secret %s: %v", name, err)
+	}
+	for k, v := range objcontour.OwnerLabels(cntr) {
+		if secret.Labels[k] != v {
+			t.Errorf("expected secret %s to contain label %s=%s, got %v", name, k, v, secret.Labels)
+		}
+	}
 }
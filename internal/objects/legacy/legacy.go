@@ -0,0 +1,163 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package legacy removes resources created by previous versions of the operator
+// that used fixed names, allowing only a single Contour per spec.namespace.name.
+// Resources are now named from the name of the owning Contour, so the fixed-name
+// resources are deleted once their replacements are available. The fixed-name
+// Envoy Service is adopted instead, keeping the address of its load balancer.
+package legacy
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+	objgw "github.com/projectcontour/contour-operator/internal/objects/gateway"
+	"github.com/projectcontour/contour-operator/pkg/labels"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1alpha1 "sigs.k8s.io/gateway-api/apis/v1alpha1"
+)
+
+const (
+	// contourName is the legacy name of the Contour Deployment, Service,
+	// ConfigMap and ServiceAccount, and the certgen RoleBinding.
+	contourName = "contour"
+	// envoyName is the legacy name of the Envoy DaemonSet, Service and
+	// ServiceAccount.
+	envoyName = "envoy"
+	// certGenName is the legacy name of the certgen ServiceAccount and Role.
+	certGenName = "contour-certgen"
+)
+
+// EnsureDeleted ensures the resources using legacy names for the provided
+// contour are deleted if Contour owner labels exist. Since the legacy Contour
+// Deployment and Envoy DaemonSet serve traffic until their replacements are
+// available, callers that keep contour running should only call EnsureDeleted
+// once the Contour Deployment and Envoy workload of contour are available. The
+// Envoy Service using the legacy name is kept, since it is adopted by contour
+// as described by EnvoyServiceName. The xDS TLS secrets
+// generated by certgen are not deleted since they do not contain owner labels.
+// The legacy certgen Job is deleted along with the other stale certgen Jobs of
+// the contour when the certgen Job is ensured. Legacy names that equal a name
+// of the current resources of contour, e.g. the certgen name of a contour named
+// "contour", are skipped.
+func EnsureDeleted(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
+	ns := contour.Spec.Namespace.Name
+	clusterName := fmt.Sprintf("%s-%s", contourName, ns)
+	objects := []client.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: contourName}},
+		&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: envoyName}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: contourName}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: contourName}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: contourName}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: envoyName}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: certGenName}},
+		&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: certGenName}},
+		&rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: contourName}},
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: clusterName}},
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: clusterName}},
+	}
+	current := map[string]bool{
+		objcontour.ContourName(contour):     true,
+		objcontour.EnvoyName(contour):       true,
+		objcontour.CertGenName(contour):     true,
+		objcontour.ClusterRbacName(contour): true,
+	}
+	var errs []error
+	for _, obj := range objects {
+		if current[obj.GetName()] {
+			continue
+		}
+		if err := ensureObjectDeleted(ctx, cli, obj, objcontour.OwnerLabels(contour)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// WorkloadsExist returns true if the Contour Deployment or Envoy DaemonSet using
+// legacy names exist for the provided contour.
+func WorkloadsExist(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) (bool, error) {
+	ns := contour.Spec.Namespace.Name
+	objects := []client.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: contourName}},
+		&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: envoyName}},
+	}
+	for _, obj := range objects {
+		exists, err := objectExists(ctx, cli, obj, objcontour.OwnerLabels(contour))
+		if err != nil || exists {
+			return exists, err
+		}
+	}
+	return false, nil
+}
+
+// EnvoyServiceName returns the name of the Envoy Service of the provided
+// contour. The Envoy Service using the legacy name is adopted if it exists
+// with Contour owner labels, so a load balancer provisioned for it keeps its
+// address. Otherwise, the name is derived from the name of contour.
+func EnvoyServiceName(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) (string, error) {
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: contour.Spec.Namespace.Name, Name: envoyName}}
+	exists, err := objectExists(ctx, cli, svc, objcontour.OwnerLabels(contour))
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return envoyName, nil
+	}
+	return objcontour.EnvoyName(contour), nil
+}
+
+// EnsureGatewayConfigMapDeleted ensures the ConfigMap using the legacy name for
+// the provided gw is deleted if Gateway owner labels exist.
+func EnsureGatewayConfigMapDeleted(ctx context.Context, cli client.Client, gw *gatewayv1alpha1.Gateway) error {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: gw.Namespace, Name: contourName}}
+	return ensureObjectDeleted(ctx, cli, cm, objgw.OwnerLabels(gw))
+}
+
+// objectExists gets obj using the namespace/name of obj and returns true if it
+// exists with ownerLabels.
+func objectExists(ctx context.Context, cli client.Client, obj client.Object, ownerLabels map[string]string) (bool, error) {
+	key := client.ObjectKeyFromObject(obj)
+	if err := cli.Get(ctx, key, obj); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get %T %s: %w", obj, key, err)
+	}
+	return labels.Exist(obj, ownerLabels), nil
+}
+
+// ensureObjectDeleted gets obj using the namespace/name of obj and deletes it
+// if ownerLabels exist.
+func ensureObjectDeleted(ctx context.Context, cli client.Client, obj client.Object, ownerLabels map[string]string) error {
+	exists, err := objectExists(ctx, cli, obj, ownerLabels)
+	if err != nil || !exists {
+		return err
+	}
+	key := client.ObjectKeyFromObject(obj)
+	propagation := client.PropagationPolicy("Background")
+	if err := cli.Delete(ctx, obj, propagation); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete %T %s: %w", obj, key, err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package legacy
+
+import (
+	"context"
+	"testing"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEnsureDeleted(t *testing.T) {
+	ctx := context.Background()
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "contour",
+		Namespace:   "contour-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	ns := cntr.Spec.Namespace.Name
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: ns, Name: name, Labels: objcontour.OwnerLabels(cntr)}
+	}
+	// The certgen name of a contour named "contour" equals the legacy
+	// certgen name, so its certgen resources must be kept. The legacy Envoy
+	// service is adopted by the contour.
+	kept := []client.Object{
+		&corev1.ServiceAccount{ObjectMeta: meta(objcontour.CertGenName(cntr))},
+		&rbacv1.Role{ObjectMeta: meta(objcontour.CertGenName(cntr))},
+		&corev1.Service{ObjectMeta: meta(envoyName)},
+	}
+	deleted := []client.Object{
+		&corev1.ServiceAccount{ObjectMeta: meta(contourName)},
+		&corev1.ConfigMap{ObjectMeta: meta(contourName)},
+	}
+	cli := fake.NewClientBuilder().WithObjects(append(kept, deleted...)...).Build()
+
+	if err := EnsureDeleted(ctx, cli, cntr); err != nil {
+		t.Fatalf("failed to delete legacy resources: %v", err)
+	}
+	for _, obj := range kept {
+		if err := cli.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			t.Errorf("expected %T %s to be kept, got %v", obj, obj.GetName(), err)
+		}
+	}
+	for _, obj := range deleted {
+		if err := cli.Get(ctx, client.ObjectKeyFromObject(obj), obj); !errors.IsNotFound(err) {
+			t.Errorf("expected %T %s to be deleted, got %v", obj, obj.GetName(), err)
+		}
+	}
+}
+
+func TestEnvoyServiceName(t *testing.T) {
+	ctx := context.Background()
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "test-contour",
+		Namespace:   "test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	ns := cntr.Spec.Namespace.Name
+	testCases := []struct {
+		description string
+		objects     []client.Object
+		expected    string
+	}{
+		{
+			description: "no legacy envoy service",
+			expected:    objcontour.EnvoyName(cntr),
+		},
+		{
+			description: "legacy envoy service with owner labels",
+			objects: []client.Object{&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: envoyName,
+				Labels: objcontour.OwnerLabels(cntr)}}},
+			expected: envoyName,
+		},
+		{
+			description: "legacy envoy service without owner labels",
+			objects:     []client.Object{&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: envoyName}}},
+			expected:    objcontour.EnvoyName(cntr),
+		},
+	}
+
+	for _, tc := range testCases {
+		cli := fake.NewClientBuilder().WithObjects(tc.objects...).Build()
+		name, err := EnvoyServiceName(ctx, cli, cntr)
+		if err != nil {
+			t.Fatalf("%q: failed to get envoy service name: %v", tc.description, err)
+		}
+		if name != tc.expected {
+			t.Errorf("%q: expected envoy service name %s, got %s", tc.description, tc.expected, name)
+		}
+	}
+}
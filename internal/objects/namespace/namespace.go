@@ -20,6 +20,7 @@ import (
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
 	"github.com/projectcontour/contour-operator/internal/equality"
 	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+	"github.com/projectcontour/contour-operator/internal/operator/metrics/drift"
 	"github.com/projectcontour/contour-operator/pkg/labels"
 
 	corev1 "k8s.io/api/core/v1"
@@ -132,6 +133,7 @@ func updateNamespaceIfNeeded(ctx context.Context, cli client.Client, contour *op
 			if err := cli.Update(ctx, ns); err != nil {
 				return fmt.Errorf("failed to update namespace %s: %w", ns.Name, err)
 			}
+			drift.ObserveCorrection(cli, ns)
 			return nil
 		}
 	}
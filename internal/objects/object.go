@@ -14,7 +14,7 @@
 package objects
 
 import (
-	"strings"
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
 
 	corev1 "k8s.io/api/core/v1"
 )
@@ -32,12 +32,17 @@ func NewUnprivilegedPodSecurity() *corev1.PodSecurityContext {
 	}
 }
 
-// TagFromImage returns the tag from the provided image or an
-// empty string if the image does not contain a tag.
-func TagFromImage(image string) string {
-	if strings.Contains(image, ":") {
-		parsed := strings.Split(image, ":")
-		return parsed[1]
+// ApplyNodePlacement sets the node scheduling configuration of placement to
+// spec. The affinity of spec is only replaced if placement specifies one.
+func ApplyNodePlacement(spec *corev1.PodSpec, placement *operatorv1alpha1.NodePlacement) {
+	if placement == nil {
+		return
 	}
-	return ""
+	spec.NodeSelector = placement.NodeSelector
+	spec.Tolerations = placement.Tolerations
+	if placement.Affinity != nil {
+		spec.Affinity = placement.Affinity
+	}
+	spec.TopologySpreadConstraints = placement.TopologySpreadConstraints
+	spec.PriorityClassName = placement.PriorityClassName
 }
@@ -0,0 +1,187 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podmonitor
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	"github.com/projectcontour/contour-operator/internal/equality"
+	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+	objds "github.com/projectcontour/contour-operator/internal/objects/daemonset"
+	objdeploy "github.com/projectcontour/contour-operator/internal/objects/deployment"
+	"github.com/projectcontour/contour-operator/internal/operator/metrics/drift"
+	"github.com/projectcontour/contour-operator/pkg/labels"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// contourMetricsPortName is the name of the metrics port of the Contour
+	// container.
+	contourMetricsPortName = "metrics"
+	// envoyMetricsPort is the port of the Envoy admin listener that serves
+	// the Envoy metrics.
+	envoyMetricsPort = int64(8002)
+	// envoyMetricsPath is the path of the Envoy metrics.
+	envoyMetricsPath = "/stats/prometheus"
+)
+
+var (
+	// GroupVersionKind is the group, version and kind of a PodMonitor of
+	// the Prometheus Operator.
+	GroupVersionKind = schema.GroupVersionKind{
+		Group:   "monitoring.coreos.com",
+		Version: "v1",
+		Kind:    "PodMonitor",
+	}
+	// GroupVersionResource is the group, version and resource of a PodMonitor
+	// of the Prometheus Operator.
+	GroupVersionResource = GroupVersionKind.GroupVersion().WithResource("podmonitors")
+)
+
+// EnsureContourPodMonitor ensures a PodMonitor exists that scrapes the metrics
+// of the Contour pods of the provided contour.
+func EnsureContourPodMonitor(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
+	return ensurePodMonitor(ctx, cli, contour, DesiredContourPodMonitor(contour))
+}
+
+// EnsureEnvoyPodMonitor ensures a PodMonitor exists that scrapes the metrics
+// of the Envoy pods of the provided contour.
+func EnsureEnvoyPodMonitor(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
+	return ensurePodMonitor(ctx, cli, contour, DesiredEnvoyPodMonitor(contour))
+}
+
+// EnsurePodMonitorsDeleted ensures the Contour and Envoy PodMonitors for the
+// provided contour are deleted if Contour owner labels exist.
+func EnsurePodMonitorsDeleted(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
+	for _, desired := range []*unstructured.Unstructured{DesiredContourPodMonitor(contour), DesiredEnvoyPodMonitor(contour)} {
+		current, err := CurrentPodMonitor(ctx, cli, desired.GetNamespace(), desired.GetName())
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if labels.Exist(current, objcontour.OwnerLabels(contour)) {
+			if err := cli.Delete(ctx, current); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DesiredContourPodMonitor returns the desired PodMonitor that scrapes the
+// metrics port of the Contour pods of the provided contour.
+func DesiredContourPodMonitor(contour *operatorv1alpha1.Contour) *unstructured.Unstructured {
+	endpoint := map[string]interface{}{
+		"port": contourMetricsPortName,
+	}
+	return desiredPodMonitor(contour, objcontour.ContourName(contour),
+		objdeploy.ContourDeploymentPodSelector(contour).MatchLabels, endpoint)
+}
+
+// DesiredEnvoyPodMonitor returns the desired PodMonitor that scrapes the
+// admin listener of the Envoy pods of the provided contour.
+func DesiredEnvoyPodMonitor(contour *operatorv1alpha1.Contour) *unstructured.Unstructured {
+	endpoint := map[string]interface{}{
+		"targetPort": envoyMetricsPort,
+		"path":       envoyMetricsPath,
+	}
+	return desiredPodMonitor(contour, objcontour.EnvoyName(contour), objds.EnvoyPodSelector(contour).MatchLabels, endpoint)
+}
+
+// desiredPodMonitor returns a PodMonitor named name in the namespace of the
+// provided contour that scrapes endpoint of the pods matching selector.
+func desiredPodMonitor(contour *operatorv1alpha1.Contour, name string, selector map[string]string,
+	endpoint map[string]interface{}) *unstructured.Unstructured {
+	matchLabels := map[string]interface{}{}
+	for k, v := range selector {
+		matchLabels[k] = v
+	}
+	pm := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"selector": map[string]interface{}{
+					"matchLabels": matchLabels,
+				},
+				"podMetricsEndpoints": []interface{}{endpoint},
+			},
+		},
+	}
+	pm.SetGroupVersionKind(GroupVersionKind)
+	pm.SetNamespace(contour.Spec.Namespace.Name)
+	pm.SetName(name)
+	pm.SetLabels(objcontour.OwnerLabels(contour))
+	return pm
+}
+
+// CurrentPodMonitor returns the current PodMonitor for the provided ns/name.
+func CurrentPodMonitor(ctx context.Context, cli client.Client, ns, name string) (*unstructured.Unstructured, error) {
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(GroupVersionKind)
+	key := types.NamespacedName{
+		Namespace: ns,
+		Name:      name,
+	}
+	if err := cli.Get(ctx, key, current); err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+// ensurePodMonitor ensures the desired PodMonitor exists for the provided
+// contour.
+func ensurePodMonitor(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, desired *unstructured.Unstructured) error {
+	current, err := CurrentPodMonitor(ctx, cli, desired.GetNamespace(), desired.GetName())
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return createPodMonitor(ctx, cli, desired)
+		}
+		return fmt.Errorf("failed to get pod monitor %s/%s: %w", desired.GetNamespace(), desired.GetName(), err)
+	}
+	if err := updatePodMonitorIfNeeded(ctx, cli, contour, current, desired); err != nil {
+		return fmt.Errorf("failed to update pod monitor for contour %s/%s: %w", contour.Namespace, contour.Name, err)
+	}
+	return nil
+}
+
+// createPodMonitor creates a PodMonitor resource for the provided pm.
+func createPodMonitor(ctx context.Context, cli client.Client, pm *unstructured.Unstructured) error {
+	if err := cli.Create(ctx, pm); err != nil {
+		return fmt.Errorf("failed to create pod monitor %s/%s: %w", pm.GetNamespace(), pm.GetName(), err)
+	}
+	return nil
+}
+
+// updatePodMonitorIfNeeded updates a PodMonitor if current does not match
+// desired, using contour to verify the existence of owner labels.
+func updatePodMonitorIfNeeded(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, current, desired *unstructured.Unstructured) error {
+	if labels.Exist(current, objcontour.OwnerLabels(contour)) {
+		pm, updated := equality.PodMonitorConfigChanged(current, desired)
+		if updated {
+			if err := cli.Update(ctx, pm); err != nil {
+				return fmt.Errorf("failed to update pod monitor %s/%s: %w", pm.GetNamespace(), pm.GetName(), err)
+			}
+			drift.ObserveCorrection(cli, pm)
+		}
+	}
+	return nil
+}
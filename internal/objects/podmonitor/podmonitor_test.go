@@ -0,0 +1,139 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podmonitor
+
+import (
+	"context"
+	"testing"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func testContour() *operatorv1alpha1.Contour {
+	return objcontour.New(objcontour.Config{
+		Name:        "test-pm",
+		Namespace:   "test-pm-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+}
+
+func TestDesiredPodMonitors(t *testing.T) {
+	cntr := testContour()
+
+	testCases := []struct {
+		description string
+		pm          *unstructured.Unstructured
+		name        string
+		selector    map[string]interface{}
+		endpoint    map[string]interface{}
+	}{
+		{
+			description: "contour pod monitor",
+			pm:          DesiredContourPodMonitor(cntr),
+			name:        "test-pm-contour",
+			selector: map[string]interface{}{
+				"app":                                   "contour",
+				operatorv1alpha1.ContourDeploymentLabel: "test-pm",
+			},
+			endpoint: map[string]interface{}{"port": "metrics"},
+		},
+		{
+			description: "envoy pod monitor",
+			pm:          DesiredEnvoyPodMonitor(cntr),
+			name:        "test-pm-envoy",
+			selector: map[string]interface{}{
+				"app":                          "envoy",
+				operatorv1alpha1.EnvoyPodLabel: "test-pm",
+			},
+			endpoint: map[string]interface{}{"targetPort": int64(8002), "path": "/stats/prometheus"},
+		},
+	}
+
+	for _, tc := range testCases {
+		if tc.pm.GroupVersionKind() != GroupVersionKind {
+			t.Errorf("%q: unexpected group version kind %s", tc.description, tc.pm.GroupVersionKind())
+		}
+		if tc.pm.GetNamespace() != "projectcontour" || tc.pm.GetName() != tc.name {
+			t.Errorf("%q: unexpected name %s/%s", tc.description, tc.pm.GetNamespace(), tc.pm.GetName())
+		}
+		if !apiequality.Semantic.DeepEqual(tc.pm.GetLabels(), objcontour.OwnerLabels(cntr)) {
+			t.Errorf("%q: unexpected labels %v", tc.description, tc.pm.GetLabels())
+		}
+		selector, _, _ := unstructured.NestedMap(tc.pm.Object, "spec", "selector", "matchLabels")
+		if !apiequality.Semantic.DeepEqual(selector, tc.selector) {
+			t.Errorf("%q: expected selector %v, got %v", tc.description, tc.selector, selector)
+		}
+		endpoints, _, _ := unstructured.NestedSlice(tc.pm.Object, "spec", "podMetricsEndpoints")
+		if len(endpoints) != 1 || !apiequality.Semantic.DeepEqual(endpoints[0], tc.endpoint) {
+			t.Errorf("%q: expected endpoints [%v], got %v", tc.description, tc.endpoint, endpoints)
+		}
+	}
+}
+
+func TestEnsurePodMonitors(t *testing.T) {
+	ctx := context.TODO()
+	cntr := testContour()
+	cli := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+
+	if err := EnsureContourPodMonitor(ctx, cli, cntr); err != nil {
+		t.Fatalf("failed to ensure contour pod monitor: %v", err)
+	}
+	if err := EnsureEnvoyPodMonitor(ctx, cli, cntr); err != nil {
+		t.Fatalf("failed to ensure envoy pod monitor: %v", err)
+	}
+
+	// Drift of a managed pod monitor is corrected.
+	desired := DesiredEnvoyPodMonitor(cntr)
+	current, err := CurrentPodMonitor(ctx, cli, desired.GetNamespace(), desired.GetName())
+	if err != nil {
+		t.Fatalf("failed to get envoy pod monitor: %v", err)
+	}
+	if err := unstructured.SetNestedField(current.Object, "/metrics", "spec", "podMetricsEndpoints"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.Update(ctx, current); err != nil {
+		t.Fatalf("failed to update envoy pod monitor: %v", err)
+	}
+	if err := EnsureEnvoyPodMonitor(ctx, cli, cntr); err != nil {
+		t.Fatalf("failed to ensure envoy pod monitor: %v", err)
+	}
+	current, err = CurrentPodMonitor(ctx, cli, desired.GetNamespace(), desired.GetName())
+	if err != nil {
+		t.Fatalf("failed to get envoy pod monitor: %v", err)
+	}
+	if !apiequality.Semantic.DeepEqual(current.Object["spec"], desired.Object["spec"]) {
+		t.Errorf("expected spec %v, got %v", desired.Object["spec"], current.Object["spec"])
+	}
+
+	if err := EnsurePodMonitorsDeleted(ctx, cli, cntr); err != nil {
+		t.Fatalf("failed to delete pod monitors: %v", err)
+	}
+	for _, pm := range []*unstructured.Unstructured{DesiredContourPodMonitor(cntr), desired} {
+		if _, err := CurrentPodMonitor(ctx, cli, pm.GetNamespace(), pm.GetName()); !errors.IsNotFound(err) {
+			t.Errorf("expected pod monitor %s/%s to be deleted, got %v", pm.GetNamespace(), pm.GetName(), err)
+		}
+	}
+	// Deleting missing pod monitors is a no-op.
+	if err := EnsurePodMonitorsDeleted(ctx, cli, cntr); err != nil {
+		t.Errorf("failed to delete missing pod monitors: %v", err)
+	}
+}
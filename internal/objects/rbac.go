@@ -26,54 +26,36 @@ import (
 	objsa "github.com/projectcontour/contour-operator/internal/objects/serviceaccount"
 	"github.com/projectcontour/contour-operator/pkg/labels"
 
-	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const (
-	// ContourRbacName is the name used for Contour RBAC resources.
-	ContourRbacName = "contour"
-	// EnvoyRbacName is the name used for Envoy RBAC resources.
-	EnvoyRbacName = "envoy"
-	// CertGenRbacName is the name used for Contour certificate
-	// generation RBAC resources.
-	CertGenRbacName = "contour-certgen"
-)
-
 // EnsureRBAC ensures all the necessary RBAC resources exist for the
 // provided contour.
 func EnsureRBAC(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
 	ns := contour.Spec.Namespace.Name
-	names := []string{ContourRbacName, EnvoyRbacName, CertGenRbacName}
-	certSvcAct := &corev1.ServiceAccount{}
+	names := []string{objcontour.ContourName(contour), objcontour.EnvoyName(contour), objcontour.CertGenName(contour)}
 	for _, name := range names {
-		svcAct, err := objsa.EnsureServiceAccount(ctx, cli, name, contour)
-		if err != nil {
+		if _, err := objsa.EnsureServiceAccount(ctx, cli, name, contour); err != nil {
 			return fmt.Errorf("failed to ensure service account %s/%s: %w", ns, name, err)
 		}
-		if svcAct.Name == CertGenRbacName {
-			certSvcAct = svcAct
-		}
 	}
-	// ClusterRole and ClusterRoleBinding resources are namespace-named to allow ownership
-	// from individual instances of Contour.
-	nsName := fmt.Sprintf("%s-%s", ContourRbacName, contour.Spec.Namespace.Name)
-	cr, err := objcr.EnsureClusterRole(ctx, cli, nsName, contour)
+	crName := objcontour.ClusterRbacName(contour)
+	cr, err := objcr.EnsureClusterRole(ctx, cli, crName, contour)
 	if err != nil {
-		return fmt.Errorf("failed to ensure cluster role %s: %w", ContourRbacName, err)
+		return fmt.Errorf("failed to ensure cluster role %s: %w", crName, err)
 	}
-	if err := objcrb.EnsureClusterRoleBinding(ctx, cli, nsName, cr.Name, ContourRbacName, contour); err != nil {
-		return fmt.Errorf("failed to ensure cluster role binding %s: %w", ContourRbacName, err)
+	if err := objcrb.EnsureClusterRoleBinding(ctx, cli, crName, cr.Name, objcontour.ContourName(contour), contour); err != nil {
+		return fmt.Errorf("failed to ensure cluster role binding %s: %w", crName, err)
 	}
-	certRole, err := objrole.EnsureRole(ctx, cli, CertGenRbacName, contour)
+	certName := objcontour.CertGenName(contour)
+	certRole, err := objrole.EnsureRole(ctx, cli, certName, contour)
 	if err != nil {
-		return fmt.Errorf("failed to ensure role %s/%s: %w", ns, CertGenRbacName, err)
+		return fmt.Errorf("failed to ensure role %s/%s: %w", ns, certName, err)
 	}
-	if err := objrb.EnsureRoleBinding(ctx, cli, ContourRbacName, certSvcAct.Name, certRole.Name, contour); err != nil {
-		return fmt.Errorf("failed to ensure role binding %s/%s: %w", ns, ContourRbacName, err)
+	if err := objrb.EnsureRoleBinding(ctx, cli, certName, certName, certRole.Name, contour); err != nil {
+		return fmt.Errorf("failed to ensure role binding %s/%s: %w", ns, certName, err)
 	}
 	return nil
 }
@@ -81,83 +63,86 @@ func EnsureRBAC(ctx context.Context, cli client.Client, contour *operatorv1alpha
 // EnsureRBACDeleted ensures all the necessary RBAC resources for the provided
 // contour are deleted if Contour owner labels exist.
 func EnsureRBACDeleted(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
-	var errs []error
-	ns := contour.Spec.Namespace.Name
-	objectsToDelete := []client.Object{}
-	contoursExist, err := objcontour.OtherContoursExistInSpecNs(ctx, cli, contour)
+	objectsToDelete, err := currentNamespacedRBAC(ctx, cli, contour)
 	if err != nil {
-		return fmt.Errorf("failed to verify if contours contoursExist in namespace %s: %w",
-			contour.Spec.Namespace.Name, err)
+		return err
 	}
-	if !contoursExist {
-		cntrRoleBind, err := objrb.CurrentRoleBinding(ctx, cli, ns, ContourRbacName)
-		if err != nil {
-			if !errors.IsNotFound(err) {
-				return err
-			}
-		}
-		if cntrRoleBind != nil {
-			objectsToDelete = append(objectsToDelete, cntrRoleBind)
-		}
-		cntrRole, err := objrole.CurrentRole(ctx, cli, ns, ContourRbacName)
-		if err != nil {
-			if !errors.IsNotFound(err) {
-				return err
-			}
-		}
-		if cntrRole != nil {
-			objectsToDelete = append(objectsToDelete, cntrRole)
-		}
-		certRole, err := objrole.CurrentRole(ctx, cli, ns, CertGenRbacName)
-		if err != nil {
-			if !errors.IsNotFound(err) {
-				return err
-			}
-		}
-		if certRole != nil {
-			objectsToDelete = append(objectsToDelete, certRole)
+	crName := objcontour.ClusterRbacName(contour)
+	crb, err := objcrb.CurrentClusterRoleBinding(ctx, cli, crName)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
 		}
-		names := []string{ContourRbacName, EnvoyRbacName, CertGenRbacName}
-		for _, name := range names {
-			svcAct, err := objsa.CurrentServiceAccount(ctx, cli, ns, name)
-			if err != nil {
-				if !errors.IsNotFound(err) {
-					return err
-				}
-			}
-			if svcAct != nil {
-				objectsToDelete = append(objectsToDelete, svcAct)
-			}
+	}
+	if crb != nil {
+		objectsToDelete = append(objectsToDelete, crb)
+	}
+	cr, err := objcr.CurrentClusterRole(ctx, cli, crName)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
 		}
 	}
-	contoursExist, _, err = objcontour.OtherContoursExist(ctx, cli, contour)
+	if cr != nil {
+		objectsToDelete = append(objectsToDelete, cr)
+	}
+	return deleteOwnedObjects(ctx, cli, contour, objectsToDelete)
+}
+
+// EnsureNamespacedRBACDeleted ensures the RBAC resources for the provided contour
+// in spec.namespace.name are deleted if Contour owner labels exist. Unlike
+// EnsureRBACDeleted, the cluster-scoped RBAC resources are kept.
+func EnsureNamespacedRBACDeleted(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
+	objectsToDelete, err := currentNamespacedRBAC(ctx, cli, contour)
 	if err != nil {
-		return fmt.Errorf("failed to verify if contours exist in any namespace: %w", err)
+		return err
 	}
-	if !contoursExist {
-		// ClusterRole and ClusterRoleBinding resources are namespace-named to allow ownership
-		// from individual instances of Contour.
-		nsName := fmt.Sprintf("%s-%s", ContourRbacName, contour.Spec.Namespace.Name)
-		crb, err := objcrb.CurrentClusterRoleBinding(ctx, cli, nsName)
-		if err != nil {
-			if !errors.IsNotFound(err) {
-				return err
-			}
+	return deleteOwnedObjects(ctx, cli, contour, objectsToDelete)
+}
+
+// currentNamespacedRBAC returns the current RBAC resources for the provided
+// contour in spec.namespace.name.
+func currentNamespacedRBAC(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) ([]client.Object, error) {
+	ns := contour.Spec.Namespace.Name
+	objects := []client.Object{}
+	certName := objcontour.CertGenName(contour)
+	certRoleBind, err := objrb.CurrentRoleBinding(ctx, cli, ns, certName)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
 		}
-		if crb != nil {
-			objectsToDelete = append(objectsToDelete, crb)
+	}
+	if certRoleBind != nil {
+		objects = append(objects, certRoleBind)
+	}
+	certRole, err := objrole.CurrentRole(ctx, cli, ns, certName)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
 		}
-		cr, err := objcr.CurrentClusterRole(ctx, cli, nsName)
+	}
+	if certRole != nil {
+		objects = append(objects, certRole)
+	}
+	names := []string{objcontour.ContourName(contour), objcontour.EnvoyName(contour), certName}
+	for _, name := range names {
+		svcAct, err := objsa.CurrentServiceAccount(ctx, cli, ns, name)
 		if err != nil {
 			if !errors.IsNotFound(err) {
-				return err
+				return nil, err
 			}
 		}
-		if cr != nil {
-			objectsToDelete = append(objectsToDelete, cr)
+		if svcAct != nil {
+			objects = append(objects, svcAct)
 		}
 	}
-	for _, object := range objectsToDelete {
+	return objects, nil
+}
+
+// deleteOwnedObjects deletes the objects that have the owner labels of the
+// provided contour.
+func deleteOwnedObjects(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, objects []client.Object) error {
+	for _, object := range objects {
 		kind := object.GetObjectKind().GroupVersionKind().Kind
 		namespace := object.(metav1.Object).GetNamespace()
 		name := object.(metav1.Object).GetName()
@@ -170,5 +155,5 @@ func EnsureRBACDeleted(ctx context.Context, cli client.Client, contour *operator
 			}
 		}
 	}
-	return utilerrors.NewAggregate(errs)
+	return nil
 }
@@ -20,6 +20,7 @@ import (
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
 	equality "github.com/projectcontour/contour-operator/internal/equality"
 	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+	"github.com/projectcontour/contour-operator/internal/operator/metrics/drift"
 	"github.com/projectcontour/contour-operator/pkg/labels"
 
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -109,6 +110,7 @@ func updateRoleIfNeeded(ctx context.Context, cli client.Client, contour *operato
 			if err := cli.Update(ctx, role); err != nil {
 				return nil, fmt.Errorf("failed to update cluster role %s/%s: %w", role.Namespace, role.Name, err)
 			}
+			drift.ObserveCorrection(cli, role)
 			return role, nil
 		}
 	}
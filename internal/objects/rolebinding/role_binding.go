@@ -20,6 +20,7 @@ import (
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
 	equality "github.com/projectcontour/contour-operator/internal/equality"
 	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+	"github.com/projectcontour/contour-operator/internal/operator/metrics/drift"
 	"github.com/projectcontour/contour-operator/pkg/labels"
 
 	corev1 "k8s.io/api/core/v1"
@@ -117,6 +118,7 @@ func updateRoleBindingIfNeeded(ctx context.Context, cli client.Client, contour *
 			if err := cli.Update(ctx, rb); err != nil {
 				return fmt.Errorf("failed to update role binding %s/%s: %w", rb.Namespace, rb.Name, err)
 			}
+			drift.ObserveCorrection(cli, rb)
 			return nil
 		}
 	}
@@ -0,0 +1,89 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+	"github.com/projectcontour/contour-operator/pkg/labels"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EnsureCertsDeleted ensures the xDS TLS secrets generated by certgen or issued
+// by the operator for the provided contour are deleted if Contour owner labels
+// exist. Secrets referenced by spec.xdsTLS.secretRefs are kept, since they are
+// owned by the user.
+func EnsureCertsDeleted(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
+	ns := contour.Spec.Namespace.Name
+	names := []string{objcontour.ContourCertsSecretName(contour), objcontour.EnvoyCertsSecretName(contour),
+		objcontour.CACertsSecretName(contour)}
+	var errs []error
+	for _, name := range names {
+		if refs := contour.Spec.XDSTLS.SecretRefs; refs != nil && (name == refs.Contour || name == refs.Envoy) {
+			continue
+		}
+		secret, err := CurrentSecret(ctx, cli, ns, name)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to get secret %s/%s: %w", ns, name, err))
+			}
+			continue
+		}
+		if !labels.Exist(secret, objcontour.OwnerLabels(contour)) {
+			continue
+		}
+		if err := cli.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete secret %s/%s: %w", ns, name, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// EnsureCertsLabeled ensures the xDS TLS secrets generated by certgen for the
+// provided contour contain Contour owner labels, since certgen does not label
+// the secrets it generates. It must only be called once the certgen Job of
+// contour has succeeded, so the secrets have been written by certgen.
+func EnsureCertsLabeled(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
+	ns := contour.Spec.Namespace.Name
+	var errs []error
+	for _, name := range []string{objcontour.ContourCertsSecretName(contour), objcontour.EnvoyCertsSecretName(contour)} {
+		secret, err := CurrentSecret(ctx, cli, ns, name)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to get secret %s/%s: %w", ns, name, err))
+			}
+			continue
+		}
+		if labels.Exist(secret, objcontour.OwnerLabels(contour)) {
+			continue
+		}
+		updated := secret.DeepCopy()
+		if updated.Labels == nil {
+			updated.Labels = map[string]string{}
+		}
+		for k, v := range objcontour.OwnerLabels(contour) {
+			updated.Labels[k] = v
+		}
+		if err := cli.Update(ctx, updated); err != nil {
+			errs = append(errs, fmt.Errorf("failed to update secret %s/%s: %w", ns, name, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
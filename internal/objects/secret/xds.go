@@ -0,0 +1,422 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	"github.com/projectcontour/contour-operator/internal/certificate"
+	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+	"github.com/projectcontour/contour-operator/pkg/labels"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilclock "k8s.io/apimachinery/pkg/util/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// caLifetimeFactor is the lifetime of the CA that issues the xDS TLS
+	// certificates of a contour, as a multiple of the certificate lifetime.
+	caLifetimeFactor = 5
+	// renewFraction is the fraction of the lifetime of a certificate that
+	// remains when the certificate is renewed.
+	renewFraction = 3
+	// contourCommonName is the common name of the certificate of Contour.
+	// Envoy verifies that the certificate of Contour is valid for this name.
+	contourCommonName = "contour"
+	// envoyCommonName is the common name of the certificate of Envoy.
+	envoyCommonName = "envoy"
+	// caCertKey is the key of the CA certificate in the secrets of the
+	// certificates of Contour and Envoy.
+	caCertKey = "ca.crt"
+	// nextCACertKey and nextCAKeyKey are the keys of the certificate and
+	// key of the CA that replaces the current CA, in the secret of the CA.
+	nextCACertKey = "next.crt"
+	nextCAKeyKey  = "next.key"
+	// previousCACertKey is the key of the certificate of the CA replaced by
+	// the current CA, in the secret of the CA.
+	previousCACertKey = "previous.crt"
+	// caTransitionPeriod is the time between issuing a new CA, which is then
+	// trusted by Contour and Envoy, and issuing certificates from it.
+	caTransitionPeriod = time.Hour
+)
+
+// CertChecksumAnnotation is the pod template annotation containing the checksum
+// of the xDS TLS certificate used by the pods, when the certificates are issued
+// by the operator or cert-manager, or provided by secret references. A change
+// of the checksum rolls the pods, so a rotated certificate takes effect.
+const CertChecksumAnnotation = "contour.operator.projectcontour.io/xds-certificate-checksum"
+
+// clock is to enable unit testing
+var clock utilclock.Clock = utilclock.RealClock{}
+
+// XDSCert describes the xDS TLS certificate of Contour or Envoy.
+type XDSCert struct {
+	// SecretName is the name of the secret containing the certificate.
+	SecretName string
+	// CommonName is the common name of the certificate.
+	CommonName string
+	// DNSNames are the DNS names the certificate is valid for.
+	DNSNames []string
+}
+
+// XDSCertsFor returns the xDS TLS certificates of Contour and Envoy for the
+// provided contour. Each certificate is valid for its common name and the
+// names of the service of its workload.
+func XDSCertsFor(contour *operatorv1alpha1.Contour) []XDSCert {
+	ns := contour.Spec.Namespace.Name
+	cert := func(secretName, commonName, service string) XDSCert {
+		return XDSCert{
+			SecretName: secretName,
+			CommonName: commonName,
+			DNSNames: []string{commonName, service, fmt.Sprintf("%s.%s", service, ns),
+				fmt.Sprintf("%s.%s.svc", service, ns)},
+		}
+	}
+	return []XDSCert{
+		cert(objcontour.ContourCertsSecretName(contour), contourCommonName, objcontour.ContourName(contour)),
+		cert(objcontour.EnvoyCertsSecretName(contour), envoyCommonName, objcontour.EnvoyName(contour)),
+	}
+}
+
+// authority is the CA that issues the xDS TLS certificates of a contour,
+// along with the CAs trusted while the CA is rotated.
+type authority struct {
+	// ca issues the certificates.
+	ca *certificate.KeyPair
+	// next is the CA that replaces ca once the transition period ends, or nil.
+	next *certificate.KeyPair
+	// previous is the CA replaced by ca, trusted until it expires, or nil.
+	previous *x509.Certificate
+}
+
+// trustedPEM returns the PEM-encoded certificates of the CAs of a.
+func (a *authority) trustedPEM() []byte {
+	trusted := a.ca.CertPEM()
+	if a.next != nil {
+		trusted = append(trusted, a.next.CertPEM()...)
+	}
+	if a.previous != nil {
+		trusted = append(trusted, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: a.previous.Raw})...)
+	}
+	return trusted
+}
+
+// renewAt returns the time the CAs of a are next due for renewal, replacement
+// or removal.
+func (a *authority) renewAt(lifetime time.Duration) time.Time {
+	if a.next != nil {
+		return a.next.Cert.NotBefore.Add(caTransitionPeriod)
+	}
+	renewAt := a.ca.Cert.NotAfter.Add(-lifetime)
+	if a.previous != nil && a.previous.NotAfter.Before(renewAt) {
+		renewAt = a.previous.NotAfter
+	}
+	return renewAt
+}
+
+// EnsureXDSCerts ensures the xDS TLS certificates of Contour and Envoy for the
+// provided contour are issued by a CA managed by the operator, returning the
+// time the CA or a certificate is next due for renewal. A certificate is renewed
+// once less than a third of its lifetime remains. The secrets are named like the
+// secrets generated by certgen, so certificates generated by certgen are replaced.
+//
+// The CA is rotated once it would expire before a renewed certificate. Since
+// Contour and Envoy only trust the CA certificates of their secret, a new CA is
+// first added to the CA certificates, rolling the pods while the certificates
+// are still issued by the current CA. The certificates are issued by the new CA
+// once caTransitionPeriod has elapsed, and the replaced CA is trusted until it
+// expires, so pods that have not yet rolled keep connecting. A pod of Contour or
+// Envoy that has not rolled within caTransitionPeriod, e.g. due to a stalled
+// rollout, does not trust the certificates of the new CA and loses its xDS
+// connection until it rolls. The CA is replaced without a transition if it is
+// invalid or would expire within caTransitionPeriod.
+func EnsureXDSCerts(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) (time.Time, error) {
+	now := clock.Now().UTC().Truncate(time.Second)
+	lifetime := objcontour.CertificateLifetime(contour)
+	auth, err := ensureCA(ctx, cli, contour, now, lifetime)
+	if err != nil {
+		return time.Time{}, err
+	}
+	renewAt := auth.renewAt(lifetime)
+	for _, c := range XDSCertsFor(contour) {
+		cert, err := ensureCert(ctx, cli, contour, auth, c.SecretName, c.CommonName, c.DNSNames, now, lifetime)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if certRenewAt := cert.Cert.NotAfter.Add(-lifetime / renewFraction); certRenewAt.Before(renewAt) {
+			renewAt = certRenewAt
+		}
+	}
+	return renewAt, nil
+}
+
+// CertAnnotations returns the pod template annotations that track the xDS TLS
+// certificate in the secret name of the provided contour. No annotations are
+// returned if the certificates are generated by certgen, which does not rotate
+// them, or if the secret does not yet exist.
+func CertAnnotations(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, name string) (map[string]string, error) {
+	xdsTLS := contour.Spec.XDSTLS
+	if xdsTLS.SecretRefs == nil && xdsTLS.Provider != operatorv1alpha1.OperatorXDSTLSProvider &&
+		xdsTLS.Provider != operatorv1alpha1.CertManagerXDSTLSProvider {
+		return nil, nil
+	}
+	ns := contour.Spec.Namespace.Name
+	secret, err := CurrentSecret(ctx, cli, ns, name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get secret %s/%s: %w", ns, name, err)
+	}
+	h := sha256.New()
+	for _, k := range []string{caCertKey, corev1.TLSCertKey} {
+		h.Write(secret.Data[k])
+		h.Write([]byte{0})
+	}
+	return map[string]string{CertChecksumAnnotation: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// ValidateSecretRefs validates the secrets of spec.xdsTLS.secretRefs of the
+// provided contour, returning an error if a secret does not exist, lacks the
+// CA certificate, certificate or key, or if the certificate of a secret does
+// not match its key or is not issued by the CA of both secrets.
+func ValidateSecretRefs(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
+	refs := contour.Spec.XDSTLS.SecretRefs
+	if refs == nil {
+		return nil
+	}
+	ns := contour.Spec.Namespace.Name
+	var secrets []*corev1.Secret
+	for _, name := range []string{refs.Contour, refs.Envoy} {
+		secret, err := CurrentSecret(ctx, cli, ns, name)
+		if err != nil {
+			return fmt.Errorf("failed to get secret %s/%s: %w", ns, name, err)
+		}
+		for _, k := range []string{caCertKey, corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+			if len(secret.Data[k]) == 0 {
+				return fmt.Errorf("secret %s/%s does not contain %s", ns, name, k)
+			}
+		}
+		secrets = append(secrets, secret)
+	}
+	for _, secret := range secrets {
+		for _, ca := range secrets {
+			err := certificate.Verify(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey], ca.Data[caCertKey])
+			if err != nil {
+				return fmt.Errorf("certificate of secret %s/%s is not valid for the ca of secret %s/%s: %w", ns,
+					secret.Name, ns, ca.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// EnsureCADeleted ensures the secret containing the CA that issues the xDS TLS
+// certificates of the provided contour is deleted if it contains the owner
+// labels of contour.
+func EnsureCADeleted(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
+	ns := contour.Spec.Namespace.Name
+	name := objcontour.CACertsSecretName(contour)
+	secret, err := CurrentSecret(ctx, cli, ns, name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get secret %s/%s: %w", ns, name, err)
+	}
+	if !labels.Exist(secret, objcontour.OwnerLabels(contour)) {
+		return nil
+	}
+	if err := cli.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete secret %s/%s: %w", ns, name, err)
+	}
+	return nil
+}
+
+// CurrentSecret returns the current Secret for the provided ns/name.
+func CurrentSecret(ctx context.Context, cli client.Client, ns, name string) (*corev1.Secret, error) {
+	current := &corev1.Secret{}
+	key := types.NamespacedName{
+		Namespace: ns,
+		Name:      name,
+	}
+	if err := cli.Get(ctx, key, current); err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+// ensureCA ensures the CA of the provided contour is valid for more than
+// lifetime, rotating the CA if needed, and returns the CAs of contour.
+func ensureCA(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, now time.Time,
+	lifetime time.Duration) (*authority, error) {
+	ns := contour.Spec.Namespace.Name
+	name := objcontour.CACertsSecretName(contour)
+	current, err := CurrentSecret(ctx, cli, ns, name)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get secret %s/%s: %w", ns, name, err)
+	}
+	auth := &authority{}
+	if current != nil {
+		auth.ca = parseCA(current.Data[corev1.TLSCertKey], current.Data[corev1.TLSPrivateKeyKey], now)
+		auth.next = parseCA(current.Data[nextCACertKey], current.Data[nextCAKeyKey], now)
+		if previous, err := certificate.ParseCert(current.Data[previousCACertKey]); err == nil && previous.NotAfter.After(now) {
+			auth.previous = previous
+		}
+	}
+	switch {
+	case auth.ca == nil:
+		// Without a valid CA, there is nothing to transition from.
+		auth.next, auth.previous = nil, nil
+	case auth.next != nil && now.Before(auth.next.Cert.NotBefore.Add(caTransitionPeriod)):
+		// The new CA is trusted, but does not issue certificates yet.
+	case auth.next != nil:
+		auth.ca, auth.next, auth.previous = auth.next, nil, auth.ca.Cert
+	case auth.ca.Cert.NotAfter.Sub(now) > lifetime:
+		// The CA is valid.
+	case auth.ca.Cert.NotAfter.Sub(now) <= caTransitionPeriod:
+		auth.ca, auth.previous = nil, nil
+	}
+	if auth.ca == nil || (auth.next == nil && auth.ca.Cert.NotAfter.Sub(now) <= lifetime) {
+		ca, err := certificate.NewCA(fmt.Sprintf("contour-%s-%s-ca", contour.Namespace, contour.Name), now,
+			caLifetimeFactor*lifetime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to issue ca for contour %s/%s: %w", contour.Namespace, contour.Name, err)
+		}
+		if auth.ca == nil {
+			auth.ca = ca
+		} else {
+			auth.next = ca
+		}
+	}
+	data := map[string][]byte{
+		corev1.TLSCertKey:       auth.ca.CertPEM(),
+		corev1.TLSPrivateKeyKey: auth.ca.KeyPEM(),
+	}
+	if auth.next != nil {
+		data[nextCACertKey] = auth.next.CertPEM()
+		data[nextCAKeyKey] = auth.next.KeyPEM()
+	}
+	if auth.previous != nil {
+		data[previousCACertKey] = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: auth.previous.Raw})
+	}
+	if current == nil || !apiequality.Semantic.DeepEqual(current.Data, data) {
+		if err := writeSecret(ctx, cli, contour, current, name, data); err != nil {
+			return nil, err
+		}
+	}
+	return auth, nil
+}
+
+// parseCA parses a CA from certPEM and keyPEM, returning nil if it is not a
+// CA or is not valid at now.
+func parseCA(certPEM, keyPEM []byte, now time.Time) *certificate.KeyPair {
+	ca, err := certificate.Parse(certPEM, keyPEM)
+	if err != nil || !ca.Cert.IsCA || now.Before(ca.Cert.NotBefore) || !ca.Cert.NotAfter.After(now) {
+		return nil
+	}
+	return ca
+}
+
+// ensureCert ensures the secret name of the provided contour contains a
+// certificate for commonName and dnsNames issued by the CA of auth, along with
+// the certificates of the CAs of auth, issuing a new certificate if the current
+// certificate is due for renewal, was issued for another lifetime or by another
+// CA.
+func ensureCert(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, auth *authority,
+	name, commonName string, dnsNames []string, now time.Time, lifetime time.Duration) (*certificate.KeyPair, error) {
+	ns := contour.Spec.Namespace.Name
+	current, err := CurrentSecret(ctx, cli, ns, name)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get secret %s/%s: %w", ns, name, err)
+	}
+	trusted := auth.trustedPEM()
+	if current != nil {
+		cert, err := certificate.Parse(current.Data[corev1.TLSCertKey], current.Data[corev1.TLSPrivateKeyKey])
+		if err == nil && cert.Cert.CheckSignatureFrom(auth.ca.Cert) == nil &&
+			cert.Cert.NotAfter.Sub(cert.Cert.NotBefore) == lifetime &&
+			cert.Cert.NotAfter.Sub(now) > lifetime/renewFraction {
+			if !bytes.Equal(current.Data[caCertKey], trusted) {
+				data := map[string][]byte{
+					caCertKey:               trusted,
+					corev1.TLSCertKey:       current.Data[corev1.TLSCertKey],
+					corev1.TLSPrivateKeyKey: current.Data[corev1.TLSPrivateKeyKey],
+				}
+				if err := writeSecret(ctx, cli, contour, current, name, data); err != nil {
+					return nil, err
+				}
+			}
+			return cert, nil
+		}
+	}
+	cert, err := certificate.NewCert(auth.ca, commonName, dnsNames, now, lifetime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue certificate for secret %s/%s: %w", ns, name, err)
+	}
+	data := map[string][]byte{
+		caCertKey:               trusted,
+		corev1.TLSCertKey:       cert.CertPEM(),
+		corev1.TLSPrivateKeyKey: cert.KeyPEM(),
+	}
+	if err := writeSecret(ctx, cli, contour, current, name, data); err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+// writeSecret creates the TLS secret name containing data for the provided
+// contour, or updates current if it exists.
+func writeSecret(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, current *corev1.Secret,
+	name string, data map[string][]byte) error {
+	if current == nil {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: contour.Spec.Namespace.Name,
+				Name:      name,
+				Labels:    objcontour.OwnerLabels(contour),
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: data,
+		}
+		if err := cli.Create(ctx, secret); err != nil {
+			return fmt.Errorf("failed to create secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+		return nil
+	}
+	updated := current.DeepCopy()
+	if updated.Labels == nil {
+		updated.Labels = map[string]string{}
+	}
+	for k, v := range objcontour.OwnerLabels(contour) {
+		updated.Labels[k] = v
+	}
+	updated.Data = data
+	if err := cli.Update(ctx, updated); err != nil {
+		return fmt.Errorf("failed to update secret %s/%s: %w", updated.Namespace, updated.Name, err)
+	}
+	return nil
+}
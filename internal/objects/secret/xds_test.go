@@ -0,0 +1,481 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"testing"
+	"time"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	"github.com/projectcontour/contour-operator/internal/certificate"
+	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilclock "k8s.io/apimachinery/pkg/util/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEnsureXDSCerts(t *testing.T) {
+	// Inject a fake clock and don't forget to reset it
+	start := time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)
+	fakeClock := utilclock.NewFakeClock(start)
+	clock = fakeClock
+	defer func() {
+		clock = utilclock.RealClock{}
+	}()
+
+	ctx := context.Background()
+	lifetime := 24 * time.Hour
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "test-contour",
+		Namespace:   "test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	cntr.Spec.XDSTLS = operatorv1alpha1.XDSTLSSettings{
+		Provider:            operatorv1alpha1.OperatorXDSTLSProvider,
+		CertificateLifetime: &metav1.Duration{Duration: lifetime},
+	}
+	cli := fake.NewClientBuilder().Build()
+	ns := cntr.Spec.Namespace.Name
+	caName := objcontour.CACertsSecretName(cntr)
+	contourName := objcontour.ContourCertsSecretName(cntr)
+	envoyName := objcontour.EnvoyCertsSecretName(cntr)
+
+	secrets := func() map[string]*corev1.Secret {
+		secrets := map[string]*corev1.Secret{}
+		for _, name := range []string{caName, contourName, envoyName} {
+			secret, err := CurrentSecret(ctx, cli, ns, name)
+			if err != nil {
+				t.Fatalf("failed to get secret %s: %v", name, err)
+			}
+			secrets[name] = secret
+		}
+		return secrets
+	}
+	ensure := func() time.Time {
+		renewAt, err := EnsureXDSCerts(ctx, cli, cntr)
+		if err != nil {
+			t.Fatalf("failed to ensure xds certificates: %v", err)
+		}
+		return renewAt
+	}
+	annotations := func(name string) map[string]string {
+		annotations, err := CertAnnotations(ctx, cli, cntr, name)
+		if err != nil {
+			t.Fatalf("failed to get certificate annotations: %v", err)
+		}
+		return annotations
+	}
+
+	// Issue the CA and the certificates.
+	renewAt := ensure()
+	if expected := start.Add(lifetime - lifetime/renewFraction); !renewAt.Equal(expected) {
+		t.Errorf("expected renewal at %s, got %s", expected, renewAt)
+	}
+	issued := secrets()
+	ca, err := certificate.ParseCert(issued[caName].Data[corev1.TLSCertKey])
+	if err != nil {
+		t.Fatalf("failed to parse ca: %v", err)
+	}
+	for _, name := range []string{contourName, envoyName} {
+		secret := issued[name]
+		if secret.Type != corev1.SecretTypeTLS {
+			t.Errorf("expected secret %s to be of type %s, got %s", name, corev1.SecretTypeTLS, secret.Type)
+		}
+		if string(secret.Data[caCertKey]) != string(issued[caName].Data[corev1.TLSCertKey]) {
+			t.Errorf("expected secret %s to contain the ca certificate", name)
+		}
+		cert, err := certificate.Parse(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			t.Fatalf("failed to parse certificate of secret %s: %v", name, err)
+		}
+		if err := cert.Cert.CheckSignatureFrom(ca); err != nil {
+			t.Errorf("expected certificate of secret %s to be issued by the ca: %v", name, err)
+		}
+	}
+	contourAnnotations := annotations(contourName)
+	if len(contourAnnotations[CertChecksumAnnotation]) == 0 {
+		t.Errorf("expected certificate annotations for secret %s", contourName)
+	}
+
+	// Valid certificates are kept.
+	fakeClock.SetTime(renewAt.Add(-time.Second))
+	ensure()
+	for name, secret := range secrets() {
+		if secret.ResourceVersion != issued[name].ResourceVersion {
+			t.Errorf("expected secret %s not to be updated", name)
+		}
+	}
+
+	// Certificates are renewed once due, the CA is kept.
+	fakeClock.SetTime(renewAt)
+	ensure()
+	renewed := secrets()
+	if renewed[caName].ResourceVersion != issued[caName].ResourceVersion {
+		t.Errorf("expected secret %s not to be updated", caName)
+	}
+	for _, name := range []string{contourName, envoyName} {
+		if string(renewed[name].Data[corev1.TLSCertKey]) == string(issued[name].Data[corev1.TLSCertKey]) {
+			t.Errorf("expected certificate of secret %s to be renewed", name)
+		}
+	}
+	if annotations(contourName)[CertChecksumAnnotation] == contourAnnotations[CertChecksumAnnotation] {
+		t.Errorf("expected certificate annotations for secret %s to change", contourName)
+	}
+
+	// A new CA is trusted once the CA would expire before a renewed
+	// certificate, while the certificates are still issued by the CA.
+	rotateAt := ca.NotAfter.Add(-lifetime)
+	fakeClock.SetTime(rotateAt)
+	renewAt = ensure()
+	if expected := rotateAt.Add(caTransitionPeriod); !renewAt.Equal(expected) {
+		t.Errorf("expected renewal at %s, got %s", expected, renewAt)
+	}
+	rotating := secrets()
+	if string(rotating[caName].Data[corev1.TLSCertKey]) != string(issued[caName].Data[corev1.TLSCertKey]) {
+		t.Errorf("expected secret %s to keep the ca", caName)
+	}
+	next, err := certificate.ParseCert(rotating[caName].Data[nextCACertKey])
+	if err != nil {
+		t.Fatalf("failed to parse next ca: %v", err)
+	}
+	bundle := string(issued[caName].Data[corev1.TLSCertKey]) + string(rotating[caName].Data[nextCACertKey])
+	for _, name := range []string{contourName, envoyName} {
+		secret := rotating[name]
+		if string(secret.Data[caCertKey]) != bundle {
+			t.Errorf("expected secret %s to contain the certificates of the ca and the next ca", name)
+		}
+		cert, err := certificate.ParseCert(secret.Data[corev1.TLSCertKey])
+		if err != nil {
+			t.Fatalf("failed to parse certificate of secret %s: %v", name, err)
+		}
+		if err := cert.CheckSignatureFrom(ca); err != nil {
+			t.Errorf("expected certificate of secret %s to be issued by the ca: %v", name, err)
+		}
+	}
+	rotatingAnnotations := annotations(contourName)
+	if rotatingAnnotations[CertChecksumAnnotation] == contourAnnotations[CertChecksumAnnotation] {
+		t.Errorf("expected certificate annotations for secret %s to change", contourName)
+	}
+
+	// The secrets are kept during the transition period.
+	fakeClock.SetTime(renewAt.Add(-time.Second))
+	ensure()
+	for name, secret := range secrets() {
+		if secret.ResourceVersion != rotating[name].ResourceVersion {
+			t.Errorf("expected secret %s not to be updated", name)
+		}
+	}
+
+	// The certificates are issued by the new CA once the transition period
+	// ends, and remain trusted by pods that trust the previous CA
+	// certificates.
+	fakeClock.SetTime(renewAt)
+	ensure()
+	rotated := secrets()
+	if string(rotated[caName].Data[corev1.TLSCertKey]) != string(rotating[caName].Data[nextCACertKey]) {
+		t.Errorf("expected secret %s to contain the next ca", caName)
+	}
+	if string(rotated[caName].Data[previousCACertKey]) != string(issued[caName].Data[corev1.TLSCertKey]) {
+		t.Errorf("expected secret %s to contain the previous ca", caName)
+	}
+	if _, ok := rotated[caName].Data[nextCACertKey]; ok {
+		t.Errorf("expected secret %s not to contain a next ca", caName)
+	}
+	for _, name := range []string{contourName, envoyName} {
+		secret := rotated[name]
+		if string(secret.Data[caCertKey]) != string(rotated[caName].Data[corev1.TLSCertKey])+string(issued[caName].Data[corev1.TLSCertKey]) {
+			t.Errorf("expected secret %s to contain the certificates of the ca and the previous ca", name)
+		}
+		cert, err := certificate.ParseCert(secret.Data[corev1.TLSCertKey])
+		if err != nil {
+			t.Fatalf("failed to parse certificate of secret %s: %v", name, err)
+		}
+		if err := cert.CheckSignatureFrom(next); err != nil {
+			t.Errorf("expected certificate of secret %s to be issued by the next ca: %v", name, err)
+		}
+		for _, trusted := range [][]byte{rotating[name].Data[caCertKey], secret.Data[caCertKey]} {
+			if err := verifyAt(secret.Data[corev1.TLSCertKey], trusted, renewAt); err != nil {
+				t.Errorf("expected certificate of secret %s to be trusted: %v", name, err)
+			}
+		}
+		if err := verifyAt(rotating[name].Data[corev1.TLSCertKey], secret.Data[caCertKey], renewAt); err != nil {
+			t.Errorf("expected previous certificate of secret %s to be trusted: %v", name, err)
+		}
+	}
+
+	// The previous CA is removed once it expires.
+	fakeClock.SetTime(ca.NotAfter)
+	ensure()
+	expired := secrets()
+	if _, ok := expired[caName].Data[previousCACertKey]; ok {
+		t.Errorf("expected secret %s not to contain the previous ca", caName)
+	}
+	for _, name := range []string{contourName, envoyName} {
+		if string(expired[name].Data[caCertKey]) != string(rotated[caName].Data[corev1.TLSCertKey]) {
+			t.Errorf("expected secret %s to only contain the certificate of the ca", name)
+		}
+	}
+
+	// A CA that expires within the transition period is replaced without a
+	// transition.
+	current, err := certificate.ParseCert(expired[caName].Data[corev1.TLSCertKey])
+	if err != nil {
+		t.Fatalf("failed to parse ca: %v", err)
+	}
+	replaceAt := current.NotAfter.Add(-caTransitionPeriod)
+	fakeClock.SetTime(replaceAt)
+	ensure()
+	replaced := secrets()
+	if _, ok := replaced[caName].Data[nextCACertKey]; ok {
+		t.Errorf("expected secret %s not to contain a next ca", caName)
+	}
+	for _, name := range []string{contourName, envoyName} {
+		if err := verifyAt(replaced[name].Data[corev1.TLSCertKey], replaced[caName].Data[corev1.TLSCertKey], replaceAt); err != nil {
+			t.Errorf("expected certificate of secret %s to be issued by the new ca: %v", name, err)
+		}
+	}
+
+	// No annotations are returned for certificates generated by certgen.
+	cntr.Spec.XDSTLS.Provider = operatorv1alpha1.CertGenXDSTLSProvider
+	if a := annotations(contourName); a != nil {
+		t.Errorf("expected no certificate annotations, got %v", a)
+	}
+}
+
+// verifyAt verifies that certPEM is issued by a CA of caPEM at time at.
+func verifyAt(certPEM, caPEM []byte, at time.Time) error {
+	cert, err := certificate.ParseCert(certPEM)
+	if err != nil {
+		return err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return fmt.Errorf("failed to decode ca certificates")
+	}
+	_, err = cert.Verify(x509.VerifyOptions{Roots: roots, CurrentTime: at, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+	return err
+}
+
+func TestEnsureCADeleted(t *testing.T) {
+	ctx := context.Background()
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "test-contour",
+		Namespace:   "test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	cntr.Spec.XDSTLS.Provider = operatorv1alpha1.OperatorXDSTLSProvider
+	cli := fake.NewClientBuilder().Build()
+	if _, err := EnsureXDSCerts(ctx, cli, cntr); err != nil {
+		t.Fatalf("failed to ensure xds certificates: %v", err)
+	}
+	if err := EnsureCADeleted(ctx, cli, cntr); err != nil {
+		t.Fatalf("failed to delete ca: %v", err)
+	}
+	list := &corev1.SecretList{}
+	if err := cli.List(ctx, list, client.InNamespace(cntr.Spec.Namespace.Name)); err != nil {
+		t.Fatalf("failed to list secrets: %v", err)
+	}
+	for _, secret := range list.Items {
+		if secret.Name == objcontour.CACertsSecretName(cntr) {
+			t.Errorf("expected secret %s to be deleted", secret.Name)
+		}
+	}
+	if len(list.Items) != 2 {
+		t.Errorf("expected the certificate secrets to be kept, got %d secrets", len(list.Items))
+	}
+	// Deleting a deleted CA is a no-op.
+	if err := EnsureCADeleted(ctx, cli, cntr); err != nil {
+		t.Errorf("failed to delete deleted ca: %v", err)
+	}
+	// A secret without the owner labels of the contour is kept.
+	unowned := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cntr.Spec.Namespace.Name,
+			Name:      objcontour.CACertsSecretName(cntr),
+		},
+	}
+	if err := cli.Create(ctx, unowned); err != nil {
+		t.Fatalf("failed to create secret: %v", err)
+	}
+	if err := EnsureCADeleted(ctx, cli, cntr); err != nil {
+		t.Fatalf("failed to delete ca: %v", err)
+	}
+	if _, err := CurrentSecret(ctx, cli, unowned.Namespace, unowned.Name); err != nil {
+		t.Errorf("expected secret %s without owner labels to be kept: %v", unowned.Name, err)
+	}
+}
+
+func TestValidateSecretRefs(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	ca, err := certificate.NewCA("test-ca", now, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to issue ca: %v", err)
+	}
+	other, err := certificate.NewCA("other-ca", now, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to issue ca: %v", err)
+	}
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "test-contour",
+		Namespace:   "test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	cntr.Spec.XDSTLS.SecretRefs = &operatorv1alpha1.XDSTLSSecretRefs{
+		Contour: "my-contour-certs",
+		Envoy:   "my-envoy-certs",
+	}
+	ns := cntr.Spec.Namespace.Name
+
+	secret := func(name string, issuer *certificate.KeyPair) *corev1.Secret {
+		cert, err := certificate.NewCert(issuer, name, []string{name}, now, time.Hour)
+		if err != nil {
+			t.Fatalf("failed to issue certificate: %v", err)
+		}
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+			Data: map[string][]byte{
+				caCertKey:               issuer.CertPEM(),
+				corev1.TLSCertKey:       cert.CertPEM(),
+				corev1.TLSPrivateKeyKey: cert.KeyPEM(),
+			},
+		}
+	}
+
+	testCases := []struct {
+		description string
+		secrets     []client.Object
+		expectErr   bool
+	}{
+		{
+			description: "secrets issued by the same ca",
+			secrets:     []client.Object{secret("my-contour-certs", ca), secret("my-envoy-certs", ca)},
+		},
+		{
+			description: "missing envoy secret",
+			secrets:     []client.Object{secret("my-contour-certs", ca)},
+			expectErr:   true,
+		},
+		{
+			description: "secret without a private key",
+			secrets: func() []client.Object {
+				envoy := secret("my-envoy-certs", ca)
+				delete(envoy.Data, corev1.TLSPrivateKeyKey)
+				return []client.Object{secret("my-contour-certs", ca), envoy}
+			}(),
+			expectErr: true,
+		},
+		{
+			description: "secrets issued by different cas",
+			secrets:     []client.Object{secret("my-contour-certs", ca), secret("my-envoy-certs", other)},
+			expectErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		cli := fake.NewClientBuilder().WithObjects(tc.secrets...).Build()
+		err := ValidateSecretRefs(ctx, cli, cntr)
+		switch {
+		case tc.expectErr && err == nil:
+			t.Errorf("%q: expected an error", tc.description)
+		case !tc.expectErr && err != nil:
+			t.Errorf("%q: unexpected error: %v", tc.description, err)
+		}
+	}
+}
+
+func TestEnsureCertsDeletedKeepsSecretRefs(t *testing.T) {
+	ctx := context.Background()
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "test-contour",
+		Namespace:   "test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	ns := cntr.Spec.Namespace.Name
+	contourName := objcontour.ContourCertsSecretName(cntr)
+	envoyName := objcontour.EnvoyCertsSecretName(cntr)
+	cntr.Spec.XDSTLS.SecretRefs = &operatorv1alpha1.XDSTLSSecretRefs{
+		Contour: contourName,
+		Envoy:   "my-envoy-certs",
+	}
+	var objs []client.Object
+	for _, name := range []string{contourName, envoyName, "my-envoy-certs"} {
+		objs = append(objs, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name,
+			Labels: objcontour.OwnerLabels(cntr)}})
+	}
+	// A secret without owner labels is kept, although it is named like
+	// the CA of the contour.
+	caName := objcontour.CACertsSecretName(cntr)
+	objs = append(objs, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: caName}})
+	cli := fake.NewClientBuilder().WithObjects(objs...).Build()
+	if err := EnsureCertsDeleted(ctx, cli, cntr); err != nil {
+		t.Fatalf("failed to delete certificate secrets: %v", err)
+	}
+	list := &corev1.SecretList{}
+	if err := cli.List(ctx, list, client.InNamespace(ns)); err != nil {
+		t.Fatalf("failed to list secrets: %v", err)
+	}
+	kept := map[string]bool{}
+	for _, secret := range list.Items {
+		kept[secret.Name] = true
+	}
+	if !kept[contourName] || !kept["my-envoy-certs"] || !kept[caName] || kept[envoyName] || len(kept) != 3 {
+		t.Errorf("expected only the referenced and unlabeled secrets to be kept, got %v", kept)
+	}
+}
+
+func TestEnsureCertsLabeled(t *testing.T) {
+	ctx := context.Background()
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "test-contour",
+		Namespace:   "test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	ns := cntr.Spec.Namespace.Name
+	contourName := objcontour.ContourCertsSecretName(cntr)
+	// Secrets generated by certgen don't contain owner labels.
+	cli := fake.NewClientBuilder().WithObjects(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: contourName}}).Build()
+	if err := EnsureCertsLabeled(ctx, cli, cntr); err != nil {
+		t.Fatalf("failed to label certificate secrets: %v", err)
+	}
+	secret, err := CurrentSecret(ctx, cli, ns, contourName)
+	if err != nil {
+		t.Fatalf("failed to get secret %s: %v", contourName, err)
+	}
+	for k, v := range objcontour.OwnerLabels(cntr) {
+		if secret.Labels[k] != v {
+			t.Errorf("expected secret %s to contain label %s=%s, got %v", contourName, k, v, secret.Labels)
+		}
+	}
+	// The labeled secrets are deleted with the contour.
+	if err := EnsureCertsDeleted(ctx, cli, cntr); err != nil {
+		t.Fatalf("failed to delete certificate secrets: %v", err)
+	}
+	if _, err := CurrentSecret(ctx, cli, ns, contourName); !errors.IsNotFound(err) {
+		t.Errorf("expected secret %s to be deleted, got %v", contourName, err)
+	}
+}
@@ -15,14 +15,20 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
 	"github.com/projectcontour/contour-operator/internal/equality"
 	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
 	objds "github.com/projectcontour/contour-operator/internal/objects/daemonset"
 	objdeploy "github.com/projectcontour/contour-operator/internal/objects/deployment"
+	objlegacy "github.com/projectcontour/contour-operator/internal/objects/legacy"
 	objcfg "github.com/projectcontour/contour-operator/internal/objects/sharedconfig"
+	"github.com/projectcontour/contour-operator/internal/operator/metrics/drift"
 	"github.com/projectcontour/contour-operator/pkg/labels"
 
 	corev1 "k8s.io/api/core/v1"
@@ -34,14 +40,6 @@ import (
 )
 
 const (
-	// contourSvcName is the name of Contour's Service.
-	// [TODO] danehans: Update Contour name to contour.Name + "-contour" to support multiple
-	// Contours/ns when https://github.com/projectcontour/contour/issues/2122 is fixed.
-	contourSvcName = "contour"
-	// [TODO] danehans: Update Envoy name to contour.Name + "-envoy" to support multiple
-	// Contours/ns when https://github.com/projectcontour/contour/issues/2122 is fixed.
-	// envoySvcName is the name of Envoy's Service.
-	envoySvcName = "envoy"
 	// awsLbBackendProtoAnnotation is a Service annotation that places the AWS ELB into
 	// "TCP" mode so that it does not do HTTP negotiation for HTTPS connections at the
 	// ELB edge. The downside of this is the remote IP address of all connections will
@@ -66,6 +64,39 @@ const (
 	// load balancer. For additional details, see:
 	// https://kubernetes.io/docs/concepts/services-networking/service/#proxy-protocol-support-on-aws
 	awsLBProxyProtocolAnnotation = "service.beta.kubernetes.io/aws-load-balancer-proxy-protocol"
+	// azureLBResourceGroupAnnotation is the annotation used on a service to specify
+	// the resource group of an Azure load balancer's public IP address.
+	azureLBResourceGroupAnnotation = "service.beta.kubernetes.io/azure-load-balancer-resource-group"
+	// azurePIPNameAnnotation is the annotation used on a service to specify the name
+	// of a static public IP address of an Azure load balancer.
+	azurePIPNameAnnotation = "service.beta.kubernetes.io/azure-pip-name"
+	// azureInternalLBSubnetAnnotation is the annotation used on a service to specify
+	// the subnet of an internal Azure load balancer.
+	azureInternalLBSubnetAnnotation = "service.beta.kubernetes.io/azure-load-balancer-internal-subnet"
+	// azureHealthProbeProtocolAnnotation is the annotation used on a service to specify
+	// the health probe protocol of an Azure load balancer.
+	azureHealthProbeProtocolAnnotation = "service.beta.kubernetes.io/azure-load-balancer-health-probe-protocol"
+	// azureHealthProbeRequestPathAnnotation is the annotation used on a service to
+	// specify the health probe request path of an Azure load balancer.
+	azureHealthProbeRequestPathAnnotation = "service.beta.kubernetes.io/azure-load-balancer-health-probe-request-path"
+	// azureHealthProbeIntervalAnnotation is the annotation used on a service to specify
+	// the health probe interval of an Azure load balancer.
+	azureHealthProbeIntervalAnnotation = "service.beta.kubernetes.io/azure-load-balancer-health-probe-interval"
+	// azureHealthProbeNumOfProbeAnnotation is the annotation used on a service to specify
+	// the number of failed health probes before an Azure load balancer backend is unhealthy.
+	azureHealthProbeNumOfProbeAnnotation = "service.beta.kubernetes.io/azure-load-balancer-health-probe-num-of-probe"
+	// gcpLBSubnetAnnotation is the annotation used on a service to specify the subnet
+	// of an internal GCP load balancer.
+	gcpLBSubnetAnnotation = "networking.gke.io/internal-load-balancer-subnet"
+	// gcpLBGlobalAccessAnnotation is the annotation used on a service to allow global
+	// access to an internal GCP load balancer.
+	gcpLBGlobalAccessAnnotation = "networking.gke.io/internal-load-balancer-allow-global-access"
+	// gcpLBBackendServiceAnnotation is the annotation used on a service to create a
+	// backend service-based external GCP load balancer.
+	gcpLBBackendServiceAnnotation = "cloud.google.com/l4-rbs"
+	// gcpNEGAnnotation is the annotation used on a service to expose its ports as
+	// standalone GCP network endpoint groups.
+	gcpNEGAnnotation = "cloud.google.com/neg"
 	// EnvoyServiceHTTPPort is the HTTP port number of the Envoy service.
 	EnvoyServiceHTTPPort = int32(80)
 	// EnvoyServiceHTTPSPort is the HTTPS port number of the Envoy service.
@@ -115,15 +146,27 @@ func EnsureContourService(ctx context.Context, cli client.Client, contour *opera
 }
 
 // EnsureEnvoyService ensures that an Envoy Service exists for the given contour.
+// An adopted Envoy Service using the legacy name keeps its selector while the
+// legacy workloads exist, so it selects the legacy Envoy pods until the Envoy
+// pods of contour replace them.
 func EnsureEnvoyService(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
 	desired := DesiredEnvoyService(contour)
-	current, err := currentEnvoyService(ctx, cli, contour)
+	current, err := CurrentEnvoyService(ctx, cli, contour)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			return createService(ctx, cli, desired)
 		}
 		return fmt.Errorf("failed to get service %s/%s: %w", desired.Namespace, desired.Name, err)
 	}
+	if current.Name != desired.Name {
+		legacy, err := objlegacy.WorkloadsExist(ctx, cli, contour)
+		if err != nil {
+			return err
+		}
+		if legacy {
+			desired.Spec.Selector = current.Spec.Selector
+		}
+	}
 	if err := updateEnvoyServiceIfNeeded(ctx, cli, contour, current, desired); err != nil {
 		return fmt.Errorf("failed to update service %s/%s: %w", desired.Namespace, desired.Name, err)
 	}
@@ -154,7 +197,7 @@ func EnsureContourServiceDeleted(ctx context.Context, cli client.Client, contour
 // EnsureEnvoyServiceDeleted ensures that an Envoy Service for the
 // provided contour is deleted.
 func EnsureEnvoyServiceDeleted(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
-	svc, err := currentEnvoyService(ctx, cli, contour)
+	svc, err := CurrentEnvoyService(ctx, cli, contour)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			return nil
@@ -178,7 +221,7 @@ func DesiredContourService(contour *operatorv1alpha1.Contour) *corev1.Service {
 	svc := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: contour.Spec.Namespace.Name,
-			Name:      contourSvcName,
+			Name:      objcontour.ContourName(contour),
 			Labels: map[string]string{
 				operatorv1alpha1.OwningContourNameLabel: contour.Name,
 				operatorv1alpha1.OwningContourNsLabel:   contour.Namespace,
@@ -193,9 +236,11 @@ func DesiredContourService(contour *operatorv1alpha1.Contour) *corev1.Service {
 					TargetPort: intstr.IntOrString{IntVal: xdsPort},
 				},
 			},
-			Selector:        objdeploy.ContourDeploymentPodSelector().MatchLabels,
+			Selector:        objdeploy.ContourDeploymentPodSelector(contour).MatchLabels,
 			Type:            corev1.ServiceTypeClusterIP,
 			SessionAffinity: corev1.ServiceAffinityNone,
+			IPFamilyPolicy:  contour.Spec.IPFamilyPolicy,
+			IPFamilies:      contour.Spec.IPFamilies,
 		},
 	}
 	return svc
@@ -230,7 +275,7 @@ func DesiredEnvoyService(contour *operatorv1alpha1.Contour) *corev1.Service {
 	svc := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace:   contour.Spec.Namespace.Name,
-			Name:        envoySvcName,
+			Name:        objcontour.EnvoyName(contour),
 			Annotations: map[string]string{},
 			Labels: map[string]string{
 				operatorv1alpha1.OwningContourNameLabel: contour.Name,
@@ -239,8 +284,10 @@ func DesiredEnvoyService(contour *operatorv1alpha1.Contour) *corev1.Service {
 		},
 		Spec: corev1.ServiceSpec{
 			Ports:           ports,
-			Selector:        objds.EnvoyDaemonSetPodSelector().MatchLabels,
+			Selector:        objds.EnvoyPodSelector(contour).MatchLabels,
 			SessionAffinity: corev1.ServiceAffinityNone,
+			IPFamilyPolicy:  contour.Spec.IPFamilyPolicy,
+			IPFamilies:      contour.Spec.IPFamilies,
 		},
 	}
 
@@ -261,13 +308,22 @@ func DesiredEnvoyService(contour *operatorv1alpha1.Contour) *corev1.Service {
 	if epType == operatorv1alpha1.LoadBalancerServicePublishingType ||
 		epType == operatorv1alpha1.NodePortServicePublishingType {
 		svc.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeLocal
+		if policy := contour.Spec.NetworkPublishing.Envoy.ExternalTrafficPolicy; policy != "" {
+			svc.Spec.ExternalTrafficPolicy = policy
+		}
+	}
+	if affinity := contour.Spec.NetworkPublishing.Envoy.SessionAffinity; affinity != "" {
+		svc.Spec.SessionAffinity = affinity
 	}
 	switch epType {
 	case operatorv1alpha1.LoadBalancerServicePublishingType:
 		svc.Spec.Type = corev1.ServiceTypeLoadBalancer
-		isInternal := contour.Spec.NetworkPublishing.Envoy.LoadBalancer.Scope == operatorv1alpha1.InternalLoadBalancer
+		lb := contour.Spec.NetworkPublishing.Envoy.LoadBalancer
+		svc.Spec.LoadBalancerIP = lb.LoadBalancerIP
+		svc.Spec.LoadBalancerSourceRanges = lb.LoadBalancerSourceRanges
+		isInternal := lb.Scope == operatorv1alpha1.InternalLoadBalancer
 		if isInternal {
-			provider := contour.Spec.NetworkPublishing.Envoy.LoadBalancer.ProviderParameters.Type
+			provider := lb.ProviderParameters.Type
 			internalAnnotations := InternalLBAnnotations[provider]
 			for name, value := range internalAnnotations {
 				svc.Annotations[name] = value
@@ -289,15 +345,110 @@ func DesiredEnvoyService(contour *operatorv1alpha1.Contour) *corev1.Service {
 	case operatorv1alpha1.ClusterIPServicePublishingType:
 		svc.Spec.Type = corev1.ServiceTypeClusterIP
 	}
+
+	if epType == operatorv1alpha1.LoadBalancerServicePublishingType {
+		addProviderAnnotations(svc, &contour.Spec.NetworkPublishing.Envoy.LoadBalancer)
+	}
+
+	// User-specified annotations take precedence over the annotations above,
+	// while the owner labels can't be overridden.
+	for k, v := range contour.Spec.NetworkPublishing.Envoy.ServiceAnnotations {
+		svc.Annotations[k] = v
+	}
+	svcLabels := map[string]string{}
+	for k, v := range contour.Spec.NetworkPublishing.Envoy.ServiceLabels {
+		svcLabels[k] = v
+	}
+	for k, v := range svc.Labels {
+		svcLabels[k] = v
+	}
+	svc.Labels = svcLabels
+	// Record the keys managed by the operator, so that keys added by others,
+	// e.g. cloud controllers, are preserved when the Service is updated.
+	svc.Annotations[operatorv1alpha1.ManagedAnnotationsAnnotation] = managedKeys(svc.Annotations)
+	svc.Annotations[operatorv1alpha1.ManagedLabelsAnnotation] = managedKeys(svc.Labels)
 	return svc
 }
 
+// addProviderAnnotations adds the annotations of the Azure or GCP provider
+// parameters of lb to svc.
+func addProviderAnnotations(svc *corev1.Service, lb *operatorv1alpha1.LoadBalancerStrategy) {
+	params := lb.ProviderParameters
+	switch {
+	case params.Type == operatorv1alpha1.AzureLoadBalancerProvider && params.Azure != nil:
+		azure := params.Azure
+		if len(azure.ResourceGroup) > 0 {
+			svc.Annotations[azureLBResourceGroupAnnotation] = azure.ResourceGroup
+		}
+		if len(azure.PublicIPName) > 0 {
+			svc.Annotations[azurePIPNameAnnotation] = azure.PublicIPName
+		}
+		if len(azure.Subnet) > 0 {
+			svc.Annotations[azureInternalLBSubnetAnnotation] = azure.Subnet
+		}
+		if probe := azure.HealthProbe; probe != nil {
+			if len(probe.Protocol) > 0 {
+				svc.Annotations[azureHealthProbeProtocolAnnotation] = string(probe.Protocol)
+			}
+			if len(probe.RequestPath) > 0 {
+				svc.Annotations[azureHealthProbeRequestPathAnnotation] = probe.RequestPath
+			}
+			if probe.IntervalSeconds != nil {
+				svc.Annotations[azureHealthProbeIntervalAnnotation] = strconv.Itoa(int(*probe.IntervalSeconds))
+			}
+			if probe.NumberOfProbes != nil {
+				svc.Annotations[azureHealthProbeNumOfProbeAnnotation] = strconv.Itoa(int(*probe.NumberOfProbes))
+			}
+		}
+	case params.Type == operatorv1alpha1.GCPLoadBalancerProvider && params.GCP != nil:
+		gcp := params.GCP
+		if len(gcp.Subnet) > 0 {
+			svc.Annotations[gcpLBSubnetAnnotation] = gcp.Subnet
+		}
+		if gcp.GlobalAccess {
+			svc.Annotations[gcpLBGlobalAccessAnnotation] = "true"
+		}
+		if gcp.BackendServiceBased {
+			svc.Annotations[gcpLBBackendServiceAnnotation] = "enabled"
+		}
+		if gcp.NetworkEndpointGroups {
+			svc.Annotations[gcpNEGAnnotation] = negAnnotationValue(svc.Spec.Ports)
+		}
+	}
+}
+
+// negAnnotationValue returns the value of the GCP NEG annotation exposing ports
+// as standalone network endpoint groups.
+func negAnnotationValue(ports []corev1.ServicePort) string {
+	exposed := map[string]struct{}{}
+	for _, p := range ports {
+		exposed[strconv.Itoa(int(p.Port))] = struct{}{}
+	}
+	// Marshaling can't fail and sorts the map keys.
+	value, _ := json.Marshal(map[string]map[string]struct{}{"exposed_ports": exposed})
+	return string(value)
+}
+
+// managedKeys returns a sorted, comma-separated list of the keys of m,
+// excluding the annotations used to record managed keys.
+func managedKeys(m map[string]string) string {
+	var keys []string
+	for k := range m {
+		if k == operatorv1alpha1.ManagedAnnotationsAnnotation || k == operatorv1alpha1.ManagedLabelsAnnotation {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
 // currentContourService returns the current Contour Service for the provided contour.
 func currentContourService(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) (*corev1.Service, error) {
 	current := &corev1.Service{}
 	key := types.NamespacedName{
 		Namespace: contour.Spec.Namespace.Name,
-		Name:      contourSvcName,
+		Name:      objcontour.ContourName(contour),
 	}
 	err := cli.Get(ctx, key, current)
 	if err != nil {
@@ -306,14 +457,19 @@ func currentContourService(ctx context.Context, cli client.Client, contour *oper
 	return current, nil
 }
 
-// currentEnvoyService returns the current Envoy Service for the provided contour.
-func currentEnvoyService(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) (*corev1.Service, error) {
+// CurrentEnvoyService returns the current Envoy Service for the provided contour,
+// named as described by objlegacy.EnvoyServiceName.
+func CurrentEnvoyService(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) (*corev1.Service, error) {
+	name, err := objlegacy.EnvoyServiceName(ctx, cli, contour)
+	if err != nil {
+		return nil, err
+	}
 	current := &corev1.Service{}
 	key := types.NamespacedName{
 		Namespace: contour.Spec.Namespace.Name,
-		Name:      envoySvcName,
+		Name:      name,
 	}
-	err := cli.Get(ctx, key, current)
+	err = cli.Get(ctx, key, current)
 	if err != nil {
 		return nil, err
 	}
@@ -331,11 +487,14 @@ func createService(ctx context.Context, cli client.Client, svc *corev1.Service)
 // updateContourServiceIfNeeded updates a Contour Service if current does not match desired.
 func updateContourServiceIfNeeded(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, current, desired *corev1.Service) error {
 	if labels.Exist(current, objcontour.OwnerLabels(contour)) {
-		_, updated := equality.ClusterIPServiceChanged(current, desired)
-		if updated {
-			if err := cli.Update(ctx, desired); err != nil {
+		// Using the Service returned by the equality pkg instead of the desired
+		// parameter since clusterIP is immutable.
+		updated, needed := equality.ClusterIPServiceChanged(current, desired)
+		if needed {
+			if err := cli.Update(ctx, updated); err != nil {
 				return fmt.Errorf("failed to update service %s/%s: %w", desired.Namespace, desired.Name, err)
 			}
+			drift.ObserveCorrection(cli, updated)
 			return nil
 		}
 	}
@@ -364,6 +523,7 @@ func updateEnvoyServiceIfNeeded(ctx context.Context, cli client.Client, contour
 			if err := cli.Update(ctx, updated); err != nil {
 				return fmt.Errorf("failed to update service %s/%s: %w", desired.Namespace, desired.Name, err)
 			}
+			drift.ObserveCorrection(cli, updated)
 			return nil
 		}
 	}
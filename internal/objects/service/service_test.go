@@ -22,7 +22,9 @@ import (
 	objcfg "github.com/projectcontour/contour-operator/internal/objects/sharedconfig"
 
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
 )
 
 func checkServiceHasPort(t *testing.T, svc *corev1.Service, port int32) {
@@ -216,8 +218,192 @@ func TestDesiredEnvoyService(t *testing.T) {
 	// GCP LBs should not should not have AWS PROXY protocol or backend protocol annotations.
 	checkServiceHasAnnotation(t, svc, false, awsLbBackendProtoAnnotation)
 	checkServiceHasAnnotation(t, svc, false, awsLBProxyProtocolAnnotation)
+	// Test user-specified service parameters.
+	cntr.Spec.NetworkPublishing.Envoy.ServiceAnnotations = map[string]string{
+		"external-dns.alpha.kubernetes.io/hostname": "example.com",
+		// User-specified annotations take precedence.
+		gcpLBTypeAnnotation: "External",
+	}
+	cntr.Spec.NetworkPublishing.Envoy.ServiceLabels = map[string]string{
+		"app.kubernetes.io/part-of":             "ingress",
+		operatorv1alpha1.OwningContourNameLabel: "foo",
+	}
+	cntr.Spec.NetworkPublishing.Envoy.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeCluster
+	cntr.Spec.NetworkPublishing.Envoy.SessionAffinity = corev1.ServiceAffinityClientIP
+	cntr.Spec.NetworkPublishing.Envoy.LoadBalancer.LoadBalancerIP = "203.0.113.10"
+	cntr.Spec.NetworkPublishing.Envoy.LoadBalancer.LoadBalancerSourceRanges = []string{"10.0.0.0/8"}
+	svc = DesiredEnvoyService(cntr)
+	checkServiceHasAnnotation(t, svc, true, "external-dns.alpha.kubernetes.io/hostname")
+	if svc.Annotations[gcpLBTypeAnnotation] != "External" {
+		t.Errorf("service has unexpected annotation %s=%q", gcpLBTypeAnnotation, svc.Annotations[gcpLBTypeAnnotation])
+	}
+	expected := "cloud.google.com/load-balancer-type,external-dns.alpha.kubernetes.io/hostname"
+	if svc.Annotations[operatorv1alpha1.ManagedAnnotationsAnnotation] != expected {
+		t.Errorf("service has unexpected managed annotations %q", svc.Annotations[operatorv1alpha1.ManagedAnnotationsAnnotation])
+	}
+	if svc.Labels["app.kubernetes.io/part-of"] != "ingress" {
+		t.Errorf("service is missing label %q", "app.kubernetes.io/part-of")
+	}
+	if svc.Labels[operatorv1alpha1.OwningContourNameLabel] != cntr.Name {
+		t.Errorf("service has unexpected owner label %q", svc.Labels[operatorv1alpha1.OwningContourNameLabel])
+	}
+	checkServiceHasExternalTrafficPolicy(t, svc, corev1.ServiceExternalTrafficPolicyTypeCluster)
+	if svc.Spec.SessionAffinity != corev1.ServiceAffinityClientIP {
+		t.Errorf("service has unexpected session affinity %q", svc.Spec.SessionAffinity)
+	}
+	if svc.Spec.LoadBalancerIP != "203.0.113.10" {
+		t.Errorf("service has unexpected load balancer IP %q", svc.Spec.LoadBalancerIP)
+	}
+	if len(svc.Spec.LoadBalancerSourceRanges) != 1 || svc.Spec.LoadBalancerSourceRanges[0] != "10.0.0.0/8" {
+		t.Errorf("service has unexpected load balancer source ranges %v", svc.Spec.LoadBalancerSourceRanges)
+	}
 	// Set network publishing type to ClusterIPService and verify the service type is as expected.
 	cntr.Spec.NetworkPublishing.Envoy.Type = operatorv1alpha1.ClusterIPServicePublishingType
 	svc = DesiredEnvoyService(cntr)
 	checkServiceHasType(t, svc, corev1.ServiceTypeClusterIP)
+	if svc.Spec.LoadBalancerIP != "" {
+		t.Errorf("service has unexpected load balancer IP %q", svc.Spec.LoadBalancerIP)
+	}
+}
+
+func TestDesiredServiceIPFamilies(t *testing.T) {
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "svc-ip-families-test",
+		Namespace:   "svc-ip-families-test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	for _, svc := range []*corev1.Service{DesiredContourService(cntr), DesiredEnvoyService(cntr)} {
+		if svc.Spec.IPFamilyPolicy != nil || len(svc.Spec.IPFamilies) > 0 {
+			t.Errorf("service %s has unexpected ip families %v/%v", svc.Name, svc.Spec.IPFamilyPolicy, svc.Spec.IPFamilies)
+		}
+	}
+
+	policy := corev1.IPFamilyPolicyRequireDualStack
+	families := []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol}
+	cntr.Spec.IPFamilyPolicy = &policy
+	cntr.Spec.IPFamilies = families
+	for _, svc := range []*corev1.Service{DesiredContourService(cntr), DesiredEnvoyService(cntr)} {
+		if svc.Spec.IPFamilyPolicy == nil || *svc.Spec.IPFamilyPolicy != policy {
+			t.Errorf("service %s has unexpected ip family policy %v", svc.Name, svc.Spec.IPFamilyPolicy)
+		}
+		if !apiequality.Semantic.DeepEqual(svc.Spec.IPFamilies, families) {
+			t.Errorf("service %s has unexpected ip families %v", svc.Name, svc.Spec.IPFamilies)
+		}
+	}
+}
+
+func TestDesiredEnvoyServiceProviderAnnotations(t *testing.T) {
+	testCases := []struct {
+		description string
+		scope       operatorv1alpha1.LoadBalancerScope
+		params      operatorv1alpha1.ProviderLoadBalancerParameters
+		expected    map[string]string
+	}{
+		{
+			description: "azure with default parameters",
+			params: operatorv1alpha1.ProviderLoadBalancerParameters{
+				Type: operatorv1alpha1.AzureLoadBalancerProvider,
+			},
+			expected: map[string]string{},
+		},
+		{
+			description: "external azure load balancer",
+			params: operatorv1alpha1.ProviderLoadBalancerParameters{
+				Type: operatorv1alpha1.AzureLoadBalancerProvider,
+				Azure: &operatorv1alpha1.AzureLoadBalancerParameters{
+					ResourceGroup: "contour-rg",
+					PublicIPName:  "contour-pip",
+					HealthProbe: &operatorv1alpha1.AzureHealthProbe{
+						Protocol:        operatorv1alpha1.AzureHealthProbeProtocolHTTP,
+						RequestPath:     "/ready",
+						IntervalSeconds: pointer.Int32Ptr(10),
+						NumberOfProbes:  pointer.Int32Ptr(3),
+					},
+				},
+			},
+			expected: map[string]string{
+				azureLBResourceGroupAnnotation:        "contour-rg",
+				azurePIPNameAnnotation:                "contour-pip",
+				azureHealthProbeProtocolAnnotation:    "Http",
+				azureHealthProbeRequestPathAnnotation: "/ready",
+				azureHealthProbeIntervalAnnotation:    "10",
+				azureHealthProbeNumOfProbeAnnotation:  "3",
+			},
+		},
+		{
+			description: "internal azure load balancer",
+			scope:       operatorv1alpha1.InternalLoadBalancer,
+			params: operatorv1alpha1.ProviderLoadBalancerParameters{
+				Type:  operatorv1alpha1.AzureLoadBalancerProvider,
+				Azure: &operatorv1alpha1.AzureLoadBalancerParameters{Subnet: "contour-subnet"},
+			},
+			expected: map[string]string{
+				azureInternalLBAnnotation:       "true",
+				azureInternalLBSubnetAnnotation: "contour-subnet",
+			},
+		},
+		{
+			description: "external gcp load balancer",
+			params: operatorv1alpha1.ProviderLoadBalancerParameters{
+				Type: operatorv1alpha1.GCPLoadBalancerProvider,
+				GCP: &operatorv1alpha1.GCPLoadBalancerParameters{
+					BackendServiceBased:   true,
+					NetworkEndpointGroups: true,
+				},
+			},
+			expected: map[string]string{
+				gcpLBBackendServiceAnnotation: "enabled",
+				gcpNEGAnnotation:              `{"exposed_ports":{"443":{},"80":{}}}`,
+			},
+		},
+		{
+			description: "internal gcp load balancer",
+			scope:       operatorv1alpha1.InternalLoadBalancer,
+			params: operatorv1alpha1.ProviderLoadBalancerParameters{
+				Type: operatorv1alpha1.GCPLoadBalancerProvider,
+				GCP: &operatorv1alpha1.GCPLoadBalancerParameters{
+					Subnet:       "contour-subnet",
+					GlobalAccess: true,
+				},
+			},
+			expected: map[string]string{
+				gcpLBTypeAnnotation:         "Internal",
+				gcpLBSubnetAnnotation:       "contour-subnet",
+				gcpLBGlobalAccessAnnotation: "true",
+			},
+		},
+		{
+			description: "gcp parameters with azure provider",
+			params: operatorv1alpha1.ProviderLoadBalancerParameters{
+				Type: operatorv1alpha1.AzureLoadBalancerProvider,
+				GCP:  &operatorv1alpha1.GCPLoadBalancerParameters{GlobalAccess: true},
+			},
+			expected: map[string]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		cntr := objcontour.New(objcontour.Config{
+			Name:        "svc-provider-test",
+			Namespace:   "svc-provider-test-ns",
+			SpecNs:      "projectcontour",
+			NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+		})
+		cntr.Spec.NetworkPublishing.Envoy.LoadBalancer.Scope = operatorv1alpha1.ExternalLoadBalancer
+		if tc.scope != "" {
+			cntr.Spec.NetworkPublishing.Envoy.LoadBalancer.Scope = tc.scope
+		}
+		cntr.Spec.NetworkPublishing.Envoy.LoadBalancer.ProviderParameters = tc.params
+		svc := DesiredEnvoyService(cntr)
+		annotations := map[string]string{}
+		for k, v := range svc.Annotations {
+			if k != operatorv1alpha1.ManagedAnnotationsAnnotation && k != operatorv1alpha1.ManagedLabelsAnnotation {
+				annotations[k] = v
+			}
+		}
+		if !apiequality.Semantic.DeepEqual(annotations, tc.expected) {
+			t.Errorf("%q: expected annotations %v, got %v", tc.description, tc.expected, annotations)
+		}
+	}
 }
@@ -20,6 +20,7 @@ import (
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
 	utilequality "github.com/projectcontour/contour-operator/internal/equality"
 	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+	"github.com/projectcontour/contour-operator/internal/operator/metrics/drift"
 	"github.com/projectcontour/contour-operator/pkg/labels"
 
 	corev1 "k8s.io/api/core/v1"
@@ -102,6 +103,7 @@ func updateSvcAcctIfNeeded(ctx context.Context, cli client.Client, contour *oper
 			if err := cli.Update(ctx, sa); err != nil {
 				return nil, fmt.Errorf("failed to update service account %s/%s: %w", sa.Namespace, sa.Name, err)
 			}
+			drift.ObserveCorrection(cli, sa)
 			return sa, nil
 		}
 	}
@@ -19,6 +19,9 @@ const (
 	DefaultMetricsAddr            = ":8080"
 	DefaultEnableLeaderElection   = false
 	DefaultEnableLeaderElectionID = "0d879e31.projectcontour.io"
+	DefaultEnableWebhooks         = true
+	DefaultWebhookPort            = 9443
+	DefaultWebhookCertDir         = "/tmp/k8s-webhook-server/serving-certs"
 )
 
 // Config is configuration of the operator.
@@ -42,6 +45,20 @@ type Config struct {
 	// LeaderElectionID determines the name of the configmap that leader election will
 	// use for holding the leader lock.
 	LeaderElectionID string
+
+	// EnableWebhooks determines whether or not to serve the conversion and
+	// defaulting webhooks for Contour objects and the validating admission
+	// webhooks for Contour, GatewayClass and Gateway objects. The conversion
+	// webhook is required to serve the v1beta1 Contour API, so it should only
+	// be disabled when running the operator outside of the cluster.
+	EnableWebhooks bool
+
+	// WebhookPort is the port that the webhook server serves at.
+	WebhookPort int
+
+	// WebhookCertDir is the directory that contains the webhook server key and
+	// certificate, named tls.key and tls.crt.
+	WebhookCertDir string
 }
 
 // New returns an operator config using default values.
@@ -52,5 +69,8 @@ func New() *Config {
 		MetricsBindAddress: DefaultMetricsAddr,
 		LeaderElection:     DefaultEnableLeaderElection,
 		LeaderElectionID:   DefaultEnableLeaderElectionID,
+		EnableWebhooks:     DefaultEnableWebhooks,
+		WebhookPort:        DefaultWebhookPort,
+		WebhookCertDir:     DefaultWebhookCertDir,
 	}
 }
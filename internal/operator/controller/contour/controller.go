@@ -16,26 +16,37 @@ package contour
 import (
 	"context"
 	"fmt"
+	"time"
 
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
 	objutil "github.com/projectcontour/contour-operator/internal/objects"
+	objcertmgr "github.com/projectcontour/contour-operator/internal/objects/certmanager"
 	objcm "github.com/projectcontour/contour-operator/internal/objects/configmap"
 	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
 	objds "github.com/projectcontour/contour-operator/internal/objects/daemonset"
 	objdeploy "github.com/projectcontour/contour-operator/internal/objects/deployment"
 	objgc "github.com/projectcontour/contour-operator/internal/objects/gatewayclass"
 	objjob "github.com/projectcontour/contour-operator/internal/objects/job"
+	objlegacy "github.com/projectcontour/contour-operator/internal/objects/legacy"
 	objns "github.com/projectcontour/contour-operator/internal/objects/namespace"
+	objpm "github.com/projectcontour/contour-operator/internal/objects/podmonitor"
+	objsecret "github.com/projectcontour/contour-operator/internal/objects/secret"
 	objsvc "github.com/projectcontour/contour-operator/internal/objects/service"
+	"github.com/projectcontour/contour-operator/internal/operator/events"
+	opmetrics "github.com/projectcontour/contour-operator/internal/operator/metrics"
 	"github.com/projectcontour/contour-operator/internal/operator/status"
 	retryable "github.com/projectcontour/contour-operator/internal/retryableerror"
 	"github.com/projectcontour/contour-operator/pkg/validation"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -47,6 +58,13 @@ import (
 
 const (
 	controllerName = "contour_controller"
+	// namespaceMigrationRetryPeriod is the period after which a blocked
+	// namespace migration is re-evaluated.
+	namespaceMigrationRetryPeriod = 30 * time.Second
+	// envoyWorkloadRetryPeriod is the period after which the availability of
+	// the Envoy workload is re-evaluated while the workload of the previous
+	// spec.envoy.workloadType is kept.
+	envoyWorkloadRetryPeriod = 10 * time.Second
 )
 
 // Config holds all the things necessary for the controller to run.
@@ -55,22 +73,31 @@ type Config struct {
 	ContourImage string
 	// EnvoyImage is the name of the Envoy container image.
 	EnvoyImage string
+	// PodMonitors is true if the PodMonitor CRD of the Prometheus Operator
+	// exists, so PodMonitors are managed for contours that enable them.
+	PodMonitors bool
+	// CertManager is true if the Certificate CRD of cert-manager exists, so
+	// Certificates are managed for contours that use cert-manager to issue
+	// their xDS TLS certificates.
+	CertManager bool
 }
 
 // reconciler reconciles a Contour object.
 type reconciler struct {
-	config Config
-	client client.Client
-	log    logr.Logger
+	config   Config
+	client   client.Client
+	recorder record.EventRecorder
+	log      logr.Logger
 }
 
 // New creates the contour controller from mgr and cfg. The controller will be pre-configured
 // to watch for Contour objects across all namespaces.
 func New(mgr manager.Manager, cfg Config) (controller.Controller, error) {
 	r := &reconciler{
-		config: cfg,
-		client: mgr.GetClient(),
-		log:    ctrl.Log.WithName(controllerName),
+		config:   cfg,
+		client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor(controllerName),
+		log:      ctrl.Log.WithName(controllerName),
 	}
 	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
 	if err != nil {
@@ -79,6 +106,11 @@ func New(mgr manager.Manager, cfg Config) (controller.Controller, error) {
 	if err := c.Watch(&source.Kind{Type: &operatorv1alpha1.Contour{}}, &handler.EnqueueRequestForObject{}); err != nil {
 		return nil, err
 	}
+	// Watch contours of the same name to re-evaluate contours that are invalid
+	// due to a conflict with another contour.
+	if err := c.Watch(&source.Kind{Type: &operatorv1alpha1.Contour{}}, r.enqueueRequestsForContoursWithName()); err != nil {
+		return nil, err
+	}
 	// Watch the Contour deployment and Envoy daemonset to properly surface Contour status conditions.
 	if err := c.Watch(&source.Kind{Type: &appsv1.Deployment{}}, r.enqueueRequestForOwningContour()); err != nil {
 		return nil, err
@@ -86,6 +118,25 @@ func New(mgr manager.Manager, cfg Config) (controller.Controller, error) {
 	if err := c.Watch(&source.Kind{Type: &appsv1.DaemonSet{}}, r.enqueueRequestForOwningContour()); err != nil {
 		return nil, err
 	}
+	// Watch the certgen job to surface its completion or failure and retry
+	// it once it failed.
+	if err := c.Watch(&source.Kind{Type: &batchv1.Job{}}, r.enqueueRequestForOwningContour()); err != nil {
+		return nil, err
+	}
+	// Watch the Envoy service to surface the Envoy addresses, e.g. once the
+	// load balancer is provisioned.
+	if err := c.Watch(&source.Kind{Type: &corev1.Service{}}, r.enqueueRequestForOwningContour()); err != nil {
+		return nil, err
+	}
+	// Watch cert-manager Certificates to surface their readiness and roll
+	// Contour and Envoy once the certificates are renewed.
+	if cfg.CertManager {
+		cert := &unstructured.Unstructured{}
+		cert.SetGroupVersionKind(objcertmgr.GroupVersionKind)
+		if err := c.Watch(&source.Kind{Type: cert}, r.enqueueRequestForOwningContour()); err != nil {
+			return nil, err
+		}
+	}
 	return c, nil
 }
 
@@ -111,6 +162,38 @@ func (r *reconciler) enqueueRequestForOwningContour() handler.EventHandler {
 	})
 }
 
+// enqueueRequestsForContoursWithName returns an event handler that maps events
+// of a contour to the other contours of the same name.
+func (r *reconciler) enqueueRequestsForContoursWithName() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(a client.Object) []reconcile.Request {
+		contours := &operatorv1alpha1.ContourList{}
+		if err := r.client.List(context.Background(), contours); err != nil {
+			r.log.Error(err, "failed to list contours", "related", a.GetSelfLink())
+			return []reconcile.Request{}
+		}
+		var requests []reconcile.Request
+		for _, c := range contours.Items {
+			if c.Name != a.GetName() || c.Namespace == a.GetNamespace() {
+				continue
+			}
+			r.log.Info("queueing contour", "namespace", c.Namespace, "name", c.Name, "related", a.GetSelfLink())
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: c.Namespace,
+					Name:      c.Name,
+				},
+			})
+		}
+		return requests
+	})
+}
+
+// clientFor returns a client that records an event on contour and counts the
+// operation for each object created, updated or deleted through the client.
+func (r *reconciler) clientFor(contour *operatorv1alpha1.Contour) client.Client {
+	return events.NewRecordingClient(opmetrics.NewClient(r.client), r.recorder, contour)
+}
+
 // Reconcile reconciles watched objects and attempts to make the current state of
 // the object match the desired state.
 func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -158,7 +241,19 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	desired := contour.ObjectMeta.DeletionTimestamp.IsZero()
 	if desired {
 		if err := validation.Contour(ctx, r.client, contour); err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to validate contour %s/%s: %w", contour.Namespace, contour.Name, err)
+			if !validation.IsInvalid(err) {
+				return ctrl.Result{}, fmt.Errorf("failed to validate contour %s/%s: %w", contour.Namespace, contour.Name, err)
+			}
+			// Requeueing an invalid contour won't make it valid, so surface the
+			// failure in its status and wait for the contour, or a contour it
+			// conflicts with, to change.
+			r.log.Info("invalid contour", "namespace", contour.Namespace, "name", contour.Name, "reason", err.Error())
+			r.recorder.Event(contour, corev1.EventTypeWarning, events.ReasonInvalid, err.Error())
+			if err := status.SyncContour(ctx, r.client, contour, &status.ContourResult{Invalid: err}); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to sync status for contour %s/%s: %w", contour.Namespace,
+					contour.Name, err)
+			}
+			return ctrl.Result{}, nil
 		}
 		switch {
 		case contour.GatewayClassSet():
@@ -166,6 +261,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 				switch e := err.(type) {
 				case retryable.Error:
 					r.log.Error(e, "got retryable error; requeueing", "after", e.After())
+					r.recorder.Eventf(contour, corev1.EventTypeWarning, events.ReasonRetrying, "Requeueing after %s: %v", e.After(), e)
 					return ctrl.Result{RequeueAfter: e.After()}, nil
 				default:
 					return ctrl.Result{}, err
@@ -181,16 +277,19 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 				r.log.Info("finalized contour", "namespace", contour.Namespace, "name", contour.Name)
 			} else {
 				r.log.Info("contour finalized", "namespace", contour.Namespace, "name", contour.Name)
-				if err := r.ensureContour(ctx, contour); err != nil {
+				res, err := r.ensureContour(ctx, contour)
+				if err != nil {
 					switch e := err.(type) {
 					case retryable.Error:
 						r.log.Error(e, "got retryable error; requeueing", "after", e.After())
+						r.recorder.Eventf(contour, corev1.EventTypeWarning, events.ReasonRetrying, "Requeueing after %s: %v", e.After(), e)
 						return ctrl.Result{RequeueAfter: e.After()}, nil
 					default:
 						return ctrl.Result{}, err
 					}
 				}
 				r.log.Info("ensured contour", "namespace", contour.Namespace, "name", contour.Name)
+				return res, nil
 			}
 		}
 	} else {
@@ -203,6 +302,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 				switch e := err.(type) {
 				case retryable.Error:
 					r.log.Error(e, "got retryable error; requeueing", "after", e.After())
+					r.recorder.Eventf(contour, corev1.EventTypeWarning, events.ReasonRetrying, "Requeueing after %s: %v", e.After(), e)
 					return ctrl.Result{RequeueAfter: e.After()}, nil
 				default:
 					return ctrl.Result{}, err
@@ -215,29 +315,60 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 }
 
 // ensureContour ensures all necessary resources exist for the given contour.
-func (r *reconciler) ensureContour(ctx context.Context, contour *operatorv1alpha1.Contour) error {
+// The returned result requeues contour when its xDS TLS certificates are due
+// for renewal, or while the Envoy workload of a previous workload type is kept.
+func (r *reconciler) ensureContour(ctx context.Context, contour *operatorv1alpha1.Contour) (ctrl.Result, error) {
+	var res ctrl.Result
 	var errs []error
-	cli := r.client
+	cli := r.clientFor(contour)
+	result := status.NewContourResult()
 
-	handleResult := func(resource string, err error) {
+	// handleResult records err as an error of component, if component is not
+	// empty, so the failure is surfaced in the status of contour.
+	handleResult := func(component, resource string, err error) {
 		if err != nil {
-			errs = append(errs, fmt.Errorf("failed to ensure %s for contour %s/%s: %w", resource, contour.Namespace, contour.Name, err))
+			wrapped := fmt.Errorf("failed to ensure %s for contour %s/%s: %w", resource, contour.Namespace, contour.Name, err)
+			// Keep the retry period of a retryable error, e.g. the backoff
+			// period of a failed certgen job.
+			if e, ok := err.(retryable.Error); ok {
+				wrapped = retryable.New(wrapped, e.After())
+			}
+			err = wrapped
+			errs = append(errs, err)
+			if len(component) > 0 {
+				result.AddError(component, err)
+			}
 		} else {
 			r.log.Info(fmt.Sprintf("ensured %s for contour", resource), "namespace", contour.Namespace, "name", contour.Name)
 		}
 	}
 
-	syncContourStatus := func() error {
-		if err := status.SyncContour(ctx, cli, contour); err != nil {
+	syncContourStatus := func() (ctrl.Result, error) {
+		if err := status.SyncContour(ctx, cli, contour, result); err != nil {
 			errs = append(errs, fmt.Errorf("failed to sync status for contour %s/%s: %w", contour.Namespace, contour.Name, err))
 		} else {
 			r.log.Info("synced status for contour", "namespace", contour.Namespace, "name", contour.Name)
 		}
-		return retryable.NewMaybeRetryableAggregate(errs)
+		return res, retryable.NewMaybeRetryableAggregate(errs)
 	}
 
-	handleResult("namespace", objns.EnsureNamespace(ctx, cli, contour))
-	handleResult("rbac", objutil.EnsureRBAC(ctx, cli, contour))
+	// Keep running in the previously applied namespace if the migration to
+	// spec.namespace.name is unsafe.
+	if err := validation.NamespaceMigration(ctx, cli, contour); err != nil {
+		if !validation.IsInvalid(err) {
+			return ctrl.Result{}, fmt.Errorf("failed to validate namespace migration of contour %s/%s: %w", contour.Namespace,
+				contour.Name, err)
+		}
+		result.Invalid = err
+		errs = append(errs, retryable.New(fmt.Errorf("blocked namespace migration of contour %s/%s: %w",
+			contour.Namespace, contour.Name, err), namespaceMigrationRetryPeriod))
+		return syncContourStatus()
+	}
+
+	handleResult(operatorv1alpha1.NamespaceComponent, "namespace", objns.EnsureNamespace(ctx, cli, contour))
+	handleResult(operatorv1alpha1.RBACComponent, "rbac", objutil.EnsureRBAC(ctx, cli, contour))
+	result.SetEnsured(operatorv1alpha1.NamespaceComponent)
+	result.SetEnsured(operatorv1alpha1.RBACComponent)
 
 	if len(errs) > 0 {
 		return syncContourStatus()
@@ -246,20 +377,164 @@ func (r *reconciler) ensureContour(ctx context.Context, contour *operatorv1alpha
 	contourImage := r.config.ContourImage
 	envoyImage := r.config.EnvoyImage
 
-	handleResult("configmap", objcm.Ensure(ctx, cli, objcm.NewCfgForContour(contour)))
-	handleResult("job", objjob.EnsureJob(ctx, cli, contour, contourImage))
-	handleResult("deployment", objdeploy.EnsureDeployment(ctx, cli, contour, contourImage))
-	handleResult("daemonset", objds.EnsureDaemonSet(ctx, cli, contour, contourImage, envoyImage))
-	handleResult("contour service", objsvc.EnsureContourService(ctx, cli, contour))
+	handleResult(operatorv1alpha1.ContourComponent, "configmap", objcm.Ensure(ctx, cli, objcm.NewCfgForContour(contour)))
+	provider := contour.Spec.XDSTLS.Provider
+	if r.config.CertManager && provider != operatorv1alpha1.CertManagerXDSTLSProvider {
+		handleResult(operatorv1alpha1.CertGenComponent, "removal of certificates", objcertmgr.EnsureCertificatesDeleted(ctx, cli, contour))
+	}
+	switch {
+	case contour.Spec.XDSTLS.SecretRefs != nil:
+		handleResult(operatorv1alpha1.CertGenComponent, "xds secrets", objsecret.ValidateSecretRefs(ctx, cli, contour))
+		handleResult(operatorv1alpha1.CertGenComponent, "removal of certgen job", objjob.EnsureJobDeleted(ctx, cli, contour))
+		handleResult(operatorv1alpha1.CertGenComponent, "removal of xds ca", objsecret.EnsureCADeleted(ctx, cli, contour))
+	case provider == operatorv1alpha1.OperatorXDSTLSProvider:
+		renewAt, err := objsecret.EnsureXDSCerts(ctx, cli, contour)
+		handleResult(operatorv1alpha1.CertGenComponent, "xds certificates", err)
+		if err == nil {
+			res.RequeueAfter = time.Until(renewAt)
+		}
+		handleResult(operatorv1alpha1.CertGenComponent, "removal of certgen job", objjob.EnsureJobDeleted(ctx, cli, contour))
+	case provider == operatorv1alpha1.CertManagerXDSTLSProvider:
+		if r.config.CertManager {
+			handleResult(operatorv1alpha1.CertGenComponent, "certificates", objcertmgr.EnsureCertificates(ctx, cli, contour))
+		} else {
+			handleResult(operatorv1alpha1.CertGenComponent, "certificates", objcertmgr.ErrCRDNotFound)
+		}
+		handleResult(operatorv1alpha1.CertGenComponent, "removal of certgen job", objjob.EnsureJobDeleted(ctx, cli, contour))
+		handleResult(operatorv1alpha1.CertGenComponent, "removal of xds ca", objsecret.EnsureCADeleted(ctx, cli, contour))
+	default:
+		handleResult(operatorv1alpha1.CertGenComponent, "job", objjob.EnsureJob(ctx, cli, contour, contourImage))
+		handleResult(operatorv1alpha1.CertGenComponent, "removal of xds ca", objsecret.EnsureCADeleted(ctx, cli, contour))
+	}
+	handleResult(operatorv1alpha1.ContourComponent, "deployment", objdeploy.EnsureDeployment(ctx, cli, contour, contourImage))
+	kept, err := objdeploy.EnsureEnvoyWorkload(ctx, cli, contour, contourImage, envoyImage)
+	handleResult(operatorv1alpha1.EnvoyComponent, "envoy workload", err)
+	if kept && (res.RequeueAfter == 0 || res.RequeueAfter > envoyWorkloadRetryPeriod) {
+		// Requeue to delete the Envoy workload of the previous type once
+		// the new workload is available.
+		res.RequeueAfter = envoyWorkloadRetryPeriod
+	}
+	handleResult(operatorv1alpha1.ContourComponent, "contour service", objsvc.EnsureContourService(ctx, cli, contour))
 
 	switch contour.Spec.NetworkPublishing.Envoy.Type {
 	case operatorv1alpha1.LoadBalancerServicePublishingType, operatorv1alpha1.NodePortServicePublishingType, operatorv1alpha1.ClusterIPServicePublishingType:
-		handleResult("envoy service", objsvc.EnsureEnvoyService(ctx, cli, contour))
+		handleResult(operatorv1alpha1.EnvoyServiceComponent, "envoy service", objsvc.EnsureEnvoyService(ctx, cli, contour))
+	}
+
+	if r.config.PodMonitors {
+		if contour.Spec.Monitoring.PodMonitors {
+			handleResult(operatorv1alpha1.ContourComponent, "contour pod monitor", objpm.EnsureContourPodMonitor(ctx, cli, contour))
+			handleResult(operatorv1alpha1.EnvoyComponent, "envoy pod monitor", objpm.EnsureEnvoyPodMonitor(ctx, cli, contour))
+		} else {
+			handleResult(operatorv1alpha1.ContourComponent, "removal of pod monitors", objpm.EnsurePodMonitorsDeleted(ctx, cli, contour))
+		}
+	}
+
+	// Remove resources named by previous versions of the operator once
+	// their replacements are available, since the legacy workloads serve
+	// traffic until then. A change of the availability of the workloads
+	// of contour triggers a reconcile.
+	if len(errs) == 0 {
+		available, err := status.ContourAvailable(ctx, cli, contour)
+		switch {
+		case err != nil:
+			handleResult("", "legacy resources", err)
+		case available:
+			handleResult("", "legacy resources", objlegacy.EnsureDeleted(ctx, cli, contour))
+		}
+		handleResult(operatorv1alpha1.NamespaceComponent, "namespace migration", r.ensureNamespaceMigrated(ctx, contour))
 	}
 
 	return syncContourStatus()
 }
 
+// ensureNamespaceMigrated ensures the resources of contour in namespaces other
+// than spec.namespace.name are deleted. Resources in the previously applied
+// namespace are deleted once contour is available in spec.namespace.name, while
+// resources in any other namespace, e.g. left by an interrupted migration, are
+// deleted right away.
+func (r *reconciler) ensureNamespaceMigrated(ctx context.Context, contour *operatorv1alpha1.Contour) error {
+	applied := contour.Status.AppliedNamespace
+	if len(applied) == 0 {
+		// Resources in other namespaces can't be told apart from the
+		// resources contour runs with until contour has been available.
+		return nil
+	}
+	cli := r.clientFor(contour)
+	namespaces, err := objdeploy.OwnedNamespaces(ctx, cli, contour)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, ns := range namespaces {
+		switch ns {
+		case contour.Spec.Namespace.Name:
+			continue
+		case applied:
+			available, err := status.ContourAvailable(ctx, cli, contour)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if !available {
+				r.log.Info("waiting for contour to be available before removing resources from namespace",
+					"namespace", contour.Namespace, "name", contour.Name, "applied", applied)
+				continue
+			}
+		}
+		if err := r.ensureResourcesDeleted(ctx, objcontour.InNamespace(contour, ns)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// ensureResourcesDeleted ensures the resources of contour in spec.namespace.name
+// have been deleted. The Contour deployment is deleted after the other namespaced
+// resources, since it marks the namespace as used by contour. Cluster-scoped
+// resources are kept, since they are shared by all namespaces of contour.
+func (r *reconciler) ensureResourcesDeleted(ctx context.Context, contour *operatorv1alpha1.Contour) error {
+	var errs []error
+	cli := r.clientFor(contour)
+	ns := contour.Spec.Namespace.Name
+
+	handleResult := func(resource string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete %s in namespace %s for contour %s/%s: %w", resource, ns,
+				contour.Namespace, contour.Name, err))
+		} else {
+			r.log.Info(fmt.Sprintf("deleted %s for contour", resource), "namespace", contour.Namespace, "name", contour.Name,
+				"resourceNamespace", ns)
+		}
+	}
+
+	switch contour.Spec.NetworkPublishing.Envoy.Type {
+	case operatorv1alpha1.LoadBalancerServicePublishingType, operatorv1alpha1.NodePortServicePublishingType, operatorv1alpha1.ClusterIPServicePublishingType:
+		handleResult("envoy service", objsvc.EnsureEnvoyServiceDeleted(ctx, cli, contour))
+	}
+
+	handleResult("service", objsvc.EnsureContourServiceDeleted(ctx, cli, contour))
+	if r.config.PodMonitors {
+		handleResult("pod monitors", objpm.EnsurePodMonitorsDeleted(ctx, cli, contour))
+	}
+	if r.config.CertManager {
+		handleResult("certificates", objcertmgr.EnsureCertificatesDeleted(ctx, cli, contour))
+	}
+	handleResult("daemonset", objds.EnsureDaemonSetDeleted(ctx, cli, contour))
+	handleResult("envoy deployment", objdeploy.EnsureEnvoyDeploymentDeleted(ctx, cli, contour))
+	handleResult("job", objjob.EnsureJobDeleted(ctx, cli, contour))
+	handleResult("certificate secrets", objsecret.EnsureCertsDeleted(ctx, cli, contour))
+	handleResult("configmap", objcm.Delete(ctx, cli, objcm.NewCfgForContour(contour)))
+	handleResult("rbac", objutil.EnsureNamespacedRBACDeleted(ctx, cli, contour))
+	handleResult("legacy resources", objlegacy.EnsureDeleted(ctx, cli, contour))
+	if len(errs) == 0 {
+		handleResult("deployment", objdeploy.EnsureDeploymentDeleted(ctx, cli, contour))
+		handleResult("namespace", objns.EnsureNamespaceDeleted(ctx, cli, contour))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
 // ensureContourForGatewayClass ensures all necessary resources exist for the given contour
 // when the contour is being managed by a GatewayClass.
 func (r *reconciler) ensureContourForGatewayClass(ctx context.Context, contour *operatorv1alpha1.Contour) error {
@@ -281,7 +556,7 @@ func (r *reconciler) ensureContourForGatewayClass(ctx context.Context, contour *
 			}
 		}
 	}
-	if err := status.SyncContour(ctx, cli, contour); err != nil {
+	if err := status.SyncContour(ctx, cli, contour, status.NewContourResult()); err != nil {
 		errs = append(errs, fmt.Errorf("failed to sync status for contour %s/%s: %w", contour.Namespace, contour.Name, err))
 	} else {
 		r.log.Info("synced status for contour", "namespace", contour.Namespace, "name", contour.Name)
@@ -296,28 +571,27 @@ func (r *reconciler) ensureContourDeleted(ctx context.Context, contour *operator
 	}
 
 	var errs []error
-	cli := r.client
+	cli := r.clientFor(contour)
 
-	handleResult := func(resource string, err error) {
-		if err != nil {
-			errs = append(errs, fmt.Errorf("failed to delete %s for contour %s/%s: %w", resource, contour.Namespace, contour.Name, err))
-		} else {
-			r.log.Info(fmt.Sprintf("deleted %s for contour", resource), "namespace", contour.Namespace, "name", contour.Name)
+	namespaces, err := objdeploy.OwnedNamespaces(ctx, cli, contour)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, ns := range namespaces {
+		// Delete the resources left in other namespaces, e.g. by a namespace
+		// migration in progress.
+		if ns != contour.Spec.Namespace.Name {
+			if err := r.ensureResourcesDeleted(ctx, objcontour.InNamespace(contour, ns)); err != nil {
+				errs = append(errs, err)
+			}
 		}
 	}
-
-	switch contour.Spec.NetworkPublishing.Envoy.Type {
-	case operatorv1alpha1.LoadBalancerServicePublishingType, operatorv1alpha1.NodePortServicePublishingType, operatorv1alpha1.ClusterIPServicePublishingType:
-		handleResult("envoy service", objsvc.EnsureEnvoyServiceDeleted(ctx, cli, contour))
+	if err := r.ensureResourcesDeleted(ctx, contour); err != nil {
+		errs = append(errs, err)
+	}
+	if err := objutil.EnsureRBACDeleted(ctx, cli, contour); err != nil {
+		errs = append(errs, fmt.Errorf("failed to delete rbac for contour %s/%s: %w", contour.Namespace, contour.Name, err))
 	}
-
-	handleResult("service", objsvc.EnsureContourServiceDeleted(ctx, cli, contour))
-	handleResult("daemonset", objds.EnsureDaemonSetDeleted(ctx, cli, contour))
-	handleResult("deployment", objdeploy.EnsureDeploymentDeleted(ctx, cli, contour))
-	handleResult("job", objjob.EnsureJobDeleted(ctx, cli, contour))
-	handleResult("configmap", objcm.Delete(ctx, cli, objcm.NewCfgForContour(contour)))
-	handleResult("rbac", objutil.EnsureRBACDeleted(ctx, cli, contour))
-	handleResult("namespace", objns.EnsureNamespaceDeleted(ctx, cli, contour))
 
 	if len(errs) == 0 {
 		if err := objcontour.EnsureFinalizerRemoved(ctx, cli, contour); err != nil {
@@ -0,0 +1,213 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contour
+
+import (
+	"context"
+	"testing"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+	objds "github.com/projectcontour/contour-operator/internal/objects/daemonset"
+	objdeploy "github.com/projectcontour/contour-operator/internal/objects/deployment"
+	"github.com/projectcontour/contour-operator/pkg/slice"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1alpha1 "sigs.k8s.io/gateway-api/apis/v1alpha1"
+)
+
+func TestReconcileInvalidContour(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{clientgoscheme.AddToScheme, operatorv1alpha1.AddToScheme,
+		gatewayv1alpha1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatalf("failed to build scheme: %v", err)
+		}
+	}
+	// Contours of the same name can't share a spec.namespace.name, so both
+	// contours are invalid and none of their workloads exist.
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "test-contour",
+		Namespace:   "test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	other := objcontour.New(objcontour.Config{
+		Name:        "test-contour",
+		Namespace:   "other-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cntr, other).Build()
+	r := &reconciler{
+		client:   cli,
+		recorder: record.NewFakeRecorder(10),
+		log:      ctrl.Log.WithName(controllerName),
+	}
+
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cntr)}
+	res, err := r.Reconcile(ctx, req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if res != (ctrl.Result{}) {
+		t.Errorf("expected an empty result, got %+v", res)
+	}
+	latest := &operatorv1alpha1.Contour{}
+	if err := cli.Get(ctx, req.NamespacedName, latest); err != nil {
+		t.Fatalf("failed to get contour: %v", err)
+	}
+	valid := meta.FindStatusCondition(latest.Status.Conditions, operatorv1alpha1.ContourValidConditionType)
+	if valid == nil || valid.Status != metav1.ConditionFalse {
+		t.Errorf("expected contour to be invalid, got conditions %v", latest.Status.Conditions)
+	}
+	if latest.Status.AvailableContours != 0 || latest.Status.AvailableEnvoys != 0 {
+		t.Errorf("expected no available contours and envoys, got %d and %d", latest.Status.AvailableContours,
+			latest.Status.AvailableEnvoys)
+	}
+	for _, c := range latest.Status.Components {
+		switch c.Name {
+		case operatorv1alpha1.NamespaceComponent, operatorv1alpha1.RBACComponent:
+			if c.Ready || c.Reason != "Skipped" {
+				t.Errorf("expected component %s to be skipped, got %+v", c.Name, c)
+			}
+		}
+	}
+}
+
+func TestReconcileLegacyResources(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{clientgoscheme.AddToScheme, operatorv1alpha1.AddToScheme,
+		gatewayv1alpha1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatalf("failed to build scheme: %v", err)
+		}
+	}
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "test-contour",
+		Namespace:   "test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	cntr.Finalizers = []string{operatorv1alpha1.ContourFinalizer}
+	ns := cntr.Spec.Namespace.Name
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: ns, Name: name, Labels: objcontour.OwnerLabels(cntr)}
+	}
+	// The resources created by a previous version of the operator.
+	legacySelector := map[string]string{"app": "envoy"}
+	legacyDeploy := &appsv1.Deployment{ObjectMeta: meta("contour")}
+	legacyDS := &appsv1.DaemonSet{ObjectMeta: meta("envoy")}
+	legacySvc := &corev1.Service{
+		ObjectMeta: meta("envoy"),
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeLoadBalancer,
+			Selector: legacySelector,
+		},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "192.0.2.1"}}},
+		},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cntr, legacyDeploy, legacyDS, legacySvc).Build()
+	r := &reconciler{
+		client:   cli,
+		recorder: record.NewFakeRecorder(100),
+		log:      ctrl.Log.WithName(controllerName),
+	}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cntr)}
+	reconcile := func() {
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			t.Fatalf("failed to reconcile contour: %v", err)
+		}
+	}
+	exists := func(obj client.Object) bool {
+		err := cli.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		if err != nil && !errors.IsNotFound(err) {
+			t.Fatalf("failed to get %T %s: %v", obj, obj.GetName(), err)
+		}
+		return err == nil
+	}
+
+	// The legacy workloads serve traffic until the workloads of the
+	// contour are available, and the legacy Envoy service is adopted.
+	reconcile()
+	for _, obj := range []client.Object{legacyDeploy, legacyDS, legacySvc} {
+		if !exists(obj) {
+			t.Errorf("expected legacy %T %s to be kept", obj, obj.GetName())
+		}
+	}
+	if !exists(&appsv1.Deployment{ObjectMeta: meta(objcontour.ContourName(cntr))}) {
+		t.Errorf("expected the contour deployment to be created")
+	}
+	if exists(&corev1.Service{ObjectMeta: meta(objcontour.EnvoyName(cntr))}) {
+		t.Errorf("expected the legacy envoy service to be adopted")
+	}
+	if !apiequality.Semantic.DeepEqual(legacySvc.Spec.Selector, legacySelector) {
+		t.Errorf("expected the legacy envoy service to keep its selector, got %v", legacySvc.Spec.Selector)
+	}
+	contourDeploy, err := objdeploy.CurrentDeployment(ctx, cli, cntr)
+	if err != nil {
+		t.Fatalf("failed to get contour deployment: %v", err)
+	}
+	if arg := "--envoy-service-name=envoy"; !slice.ContainsString(contourDeploy.Spec.Template.Spec.Containers[0].Args, arg) {
+		t.Errorf("expected contour deployment args to contain %s", arg)
+	}
+
+	// The legacy workloads are deleted once the workloads of the contour
+	// are available, and the adopted service then selects the Envoy pods
+	// of the contour.
+	deploy := contourDeploy
+	deploy.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}}
+	if err := cli.Status().Update(ctx, deploy); err != nil {
+		t.Fatalf("failed to update contour deployment: %v", err)
+	}
+	ds, err := objds.CurrentDaemonSet(ctx, cli, cntr)
+	if err != nil {
+		t.Fatalf("failed to get envoy daemonset: %v", err)
+	}
+	ds.Status.NumberAvailable = 1
+	if err := cli.Status().Update(ctx, ds); err != nil {
+		t.Fatalf("failed to update envoy daemonset: %v", err)
+	}
+	reconcile()
+	reconcile()
+	for _, obj := range []client.Object{legacyDeploy, legacyDS} {
+		if exists(obj) {
+			t.Errorf("expected legacy %T %s to be deleted", obj, obj.GetName())
+		}
+	}
+	if !exists(legacySvc) {
+		t.Fatalf("expected the adopted envoy service to be kept")
+	}
+	if !apiequality.Semantic.DeepEqual(legacySvc.Spec.Selector, objds.EnvoyPodSelector(cntr).MatchLabels) {
+		t.Errorf("expected the adopted envoy service to select the envoy pods of the contour, got %v",
+			legacySvc.Spec.Selector)
+	}
+	if len(legacySvc.Status.LoadBalancer.Ingress) != 1 {
+		t.Errorf("expected the adopted envoy service to keep its load balancer address")
+	}
+}
@@ -16,9 +16,11 @@ package gateway
 import (
 	"context"
 	"fmt"
+	"time"
 
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
 	objutil "github.com/projectcontour/contour-operator/internal/objects"
+	objcertmgr "github.com/projectcontour/contour-operator/internal/objects/certmanager"
 	objcm "github.com/projectcontour/contour-operator/internal/objects/configmap"
 	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
 	objds "github.com/projectcontour/contour-operator/internal/objects/daemonset"
@@ -26,16 +28,23 @@ import (
 	objgw "github.com/projectcontour/contour-operator/internal/objects/gateway"
 	objgc "github.com/projectcontour/contour-operator/internal/objects/gatewayclass"
 	objjob "github.com/projectcontour/contour-operator/internal/objects/job"
+	objlegacy "github.com/projectcontour/contour-operator/internal/objects/legacy"
 	objns "github.com/projectcontour/contour-operator/internal/objects/namespace"
+	objpm "github.com/projectcontour/contour-operator/internal/objects/podmonitor"
+	objsecret "github.com/projectcontour/contour-operator/internal/objects/secret"
 	objsvc "github.com/projectcontour/contour-operator/internal/objects/service"
+	"github.com/projectcontour/contour-operator/internal/operator/events"
+	opmetrics "github.com/projectcontour/contour-operator/internal/operator/metrics"
 	"github.com/projectcontour/contour-operator/internal/operator/status"
 	retryable "github.com/projectcontour/contour-operator/internal/retryableerror"
 	"github.com/projectcontour/contour-operator/pkg/validation"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -48,6 +57,10 @@ import (
 
 const (
 	controllerName = "gateway_controller"
+	// envoyWorkloadRetryPeriod is the period after which the availability of
+	// the Envoy workload is re-evaluated while the workload of the previous
+	// spec.envoy.workloadType, or the legacy workloads, are kept.
+	envoyWorkloadRetryPeriod = 10 * time.Second
 )
 
 // Config holds all the things necessary for the controller to run.
@@ -56,22 +69,31 @@ type Config struct {
 	ContourImage string
 	// EnvoyImage is the name of the Envoy container image.
 	EnvoyImage string
+	// PodMonitors is true if the PodMonitor CRD of the Prometheus Operator
+	// exists, so PodMonitors are managed for contours that enable them.
+	PodMonitors bool
+	// CertManager is true if the Certificate CRD of cert-manager exists, so
+	// Certificates are managed for contours that use cert-manager to issue
+	// their xDS TLS certificates.
+	CertManager bool
 }
 
 // reconciler reconciles a Gateway object.
 type reconciler struct {
-	config Config
-	client client.Client
-	log    logr.Logger
+	config   Config
+	client   client.Client
+	recorder record.EventRecorder
+	log      logr.Logger
 }
 
 // New creates the gateway controller from mgr. The controller will be pre-configured
 // to watch for Gateway objects across all namespaces.
 func New(mgr manager.Manager, cfg Config) (controller.Controller, error) {
 	r := &reconciler{
-		client: mgr.GetClient(),
-		config: cfg,
-		log:    ctrl.Log.WithName(controllerName),
+		client:   mgr.GetClient(),
+		config:   cfg,
+		recorder: mgr.GetEventRecorderFor(controllerName),
+		log:      ctrl.Log.WithName(controllerName),
 	}
 	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
 	if err != nil {
@@ -122,6 +144,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		Namespace: req.Namespace,
 		Name:      req.Name,
 	}
+	var res ctrl.Result
 	var errs []error
 	if err := r.client.Get(ctx, key, gw); err != nil {
 		if errors.IsNotFound(err) {
@@ -140,11 +163,13 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if desired {
 		cntr, err := validation.Gateway(ctx, r.client, gw)
 		if err != nil {
+			r.recorder.Event(gw, corev1.EventTypeWarning, events.ReasonInvalid, err.Error())
 			return ctrl.Result{}, fmt.Errorf("failed to validate gateway %s/%s: %w", gw.Namespace, gw.Name, err)
 		}
 		switch {
 		case objgw.IsFinalized(gw):
-			if err := r.ensureGateway(ctx, gw, cntr); err != nil {
+			res, err = r.ensureGateway(ctx, gw, cntr)
+			if err != nil {
 				return ctrl.Result{}, fmt.Errorf("failed to get ensure gateway %s/%s: %w", req.Namespace, req.Name, err)
 			}
 			// The gateway is valid, so finalize dependent resources of gateway.
@@ -182,6 +207,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			switch e := err.(type) {
 			case retryable.Error:
 				r.log.Error(e, "got retryable error; requeueing", "after", e.After())
+				r.recorder.Eventf(gw, corev1.EventTypeWarning, events.ReasonRetrying, "Requeueing after %s: %v", e.After(), e)
 				return ctrl.Result{RequeueAfter: e.After()}, nil
 			default:
 				return ctrl.Result{}, err
@@ -191,17 +217,26 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	if len(errs) != 0 {
 		return ctrl.Result{}, retryable.NewMaybeRetryableAggregate(errs)
 	}
-	return ctrl.Result{}, nil
+	return res, nil
 }
 
-// ensureGateway ensures all necessary resources exist for the given gw.
-func (r *reconciler) ensureGateway(ctx context.Context, gw *gatewayv1alpha1.Gateway, contour *operatorv1alpha1.Contour) error {
+// ensureGateway ensures all necessary resources exist for the given gw. The
+// returned result requeues gw when the xDS TLS certificates of contour are due
+// for renewal, or while the Envoy workload of a previous workload type is kept.
+func (r *reconciler) ensureGateway(ctx context.Context, gw *gatewayv1alpha1.Gateway, contour *operatorv1alpha1.Contour) (ctrl.Result, error) {
+	var res ctrl.Result
 	var errs []error
-	cli := r.client
+	cli := events.NewRecordingClient(opmetrics.NewClient(r.client), r.recorder, gw)
 
 	handleResult := func(resource string, err error) {
 		if err != nil {
-			errs = append(errs, fmt.Errorf("failed to ensure %s for contour %s/%s: %w", resource, contour.Namespace, contour.Name, err))
+			wrapped := fmt.Errorf("failed to ensure %s for contour %s/%s: %w", resource, contour.Namespace, contour.Name, err)
+			// Keep the retry period of a retryable error, e.g. the backoff
+			// period of a failed certgen job.
+			if e, ok := err.(retryable.Error); ok {
+				wrapped = retryable.New(wrapped, e.After())
+			}
+			errs = append(errs, wrapped)
 		} else {
 			r.log.Info(fmt.Sprintf("ensured %s for contour", resource), "namespace", contour.Namespace, "name", contour.Name)
 		}
@@ -211,11 +246,11 @@ func (r *reconciler) ensureGateway(ctx context.Context, gw *gatewayv1alpha1.Gate
 	handleResult("rbac", objutil.EnsureRBAC(ctx, cli, contour))
 
 	if len(errs) > 0 {
-		return retryable.NewMaybeRetryableAggregate(errs)
+		return res, retryable.NewMaybeRetryableAggregate(errs)
 	}
 
 	// configmap error/logging messages are different, hence not using handleResult
-	if err := objcm.Ensure(ctx, cli, objcm.NewCfgForGateway(gw)); err != nil {
+	if err := objcm.Ensure(ctx, cli, objcm.NewCfgForGateway(contour, gw)); err != nil {
 		errs = append(errs, fmt.Errorf("failed to ensure configmap for gateway %s/%s: %w", gw.Namespace, gw.Name, err))
 	} else {
 		r.log.Info("ensured configmap for gateway", "namespace", gw.Namespace, "name", gw.Name)
@@ -224,9 +259,42 @@ func (r *reconciler) ensureGateway(ctx context.Context, gw *gatewayv1alpha1.Gate
 	contourImage := r.config.ContourImage
 	envoyImage := r.config.EnvoyImage
 
-	handleResult("job", objjob.EnsureJob(ctx, cli, contour, contourImage))
+	provider := contour.Spec.XDSTLS.Provider
+	if r.config.CertManager && provider != operatorv1alpha1.CertManagerXDSTLSProvider {
+		handleResult("removal of certificates", objcertmgr.EnsureCertificatesDeleted(ctx, cli, contour))
+	}
+	switch {
+	case contour.Spec.XDSTLS.SecretRefs != nil:
+		handleResult("xds secrets", objsecret.ValidateSecretRefs(ctx, cli, contour))
+		handleResult("removal of certgen job", objjob.EnsureJobDeleted(ctx, cli, contour))
+		handleResult("removal of xds ca", objsecret.EnsureCADeleted(ctx, cli, contour))
+	case provider == operatorv1alpha1.OperatorXDSTLSProvider:
+		renewAt, err := objsecret.EnsureXDSCerts(ctx, cli, contour)
+		handleResult("xds certificates", err)
+		if err == nil {
+			res.RequeueAfter = time.Until(renewAt)
+		}
+		handleResult("removal of certgen job", objjob.EnsureJobDeleted(ctx, cli, contour))
+	case provider == operatorv1alpha1.CertManagerXDSTLSProvider:
+		if r.config.CertManager {
+			handleResult("certificates", objcertmgr.EnsureCertificates(ctx, cli, contour))
+		} else {
+			handleResult("certificates", objcertmgr.ErrCRDNotFound)
+		}
+		handleResult("removal of certgen job", objjob.EnsureJobDeleted(ctx, cli, contour))
+		handleResult("removal of xds ca", objsecret.EnsureCADeleted(ctx, cli, contour))
+	default:
+		handleResult("job", objjob.EnsureJob(ctx, cli, contour, contourImage))
+		handleResult("removal of xds ca", objsecret.EnsureCADeleted(ctx, cli, contour))
+	}
 	handleResult("deployment", objdeploy.EnsureDeployment(ctx, cli, contour, contourImage))
-	handleResult("daemonset", objds.EnsureDaemonSet(ctx, cli, contour, contourImage, envoyImage))
+	kept, err := objdeploy.EnsureEnvoyWorkload(ctx, cli, contour, contourImage, envoyImage)
+	handleResult("envoy workload", err)
+	if kept && (res.RequeueAfter == 0 || res.RequeueAfter > envoyWorkloadRetryPeriod) {
+		// Requeue to delete the Envoy workload of the previous type once
+		// the new workload is available.
+		res.RequeueAfter = envoyWorkloadRetryPeriod
+	}
 	handleResult("contour service", objsvc.EnsureContourService(ctx, cli, contour))
 
 	switch contour.Spec.NetworkPublishing.Envoy.Type {
@@ -234,18 +302,51 @@ func (r *reconciler) ensureGateway(ctx context.Context, gw *gatewayv1alpha1.Gate
 		handleResult("envoy service", objsvc.EnsureEnvoyService(ctx, cli, contour))
 	}
 
-	return retryable.NewMaybeRetryableAggregate(errs)
+	if r.config.PodMonitors {
+		if contour.Spec.Monitoring.PodMonitors {
+			handleResult("contour pod monitor", objpm.EnsureContourPodMonitor(ctx, cli, contour))
+			handleResult("envoy pod monitor", objpm.EnsureEnvoyPodMonitor(ctx, cli, contour))
+		} else {
+			handleResult("removal of pod monitors", objpm.EnsurePodMonitorsDeleted(ctx, cli, contour))
+		}
+	}
+
+	// Remove resources named by previous versions of the operator once
+	// their replacements are available, since the legacy workloads serve
+	// traffic until then.
+	if len(errs) == 0 {
+		available, err := status.ContourAvailable(ctx, cli, contour)
+		switch {
+		case err != nil:
+			handleResult("legacy resources", err)
+		case available:
+			handleResult("legacy resources", objlegacy.EnsureDeleted(ctx, cli, contour))
+			handleResult("legacy configmap", objlegacy.EnsureGatewayConfigMapDeleted(ctx, cli, gw))
+		default:
+			legacy, err := objlegacy.WorkloadsExist(ctx, cli, contour)
+			if err != nil {
+				handleResult("legacy resources", err)
+			}
+			if legacy && (res.RequeueAfter == 0 || res.RequeueAfter > envoyWorkloadRetryPeriod) {
+				// Requeue to delete the legacy resources once contour is
+				// available.
+				res.RequeueAfter = envoyWorkloadRetryPeriod
+			}
+		}
+	}
+
+	return res, retryable.NewMaybeRetryableAggregate(errs)
 }
 
 // ensureGatewayDeleted ensures gw and all child resources have been deleted.
 func (r *reconciler) ensureGatewayDeleted(ctx context.Context, gw *gatewayv1alpha1.Gateway) error {
 	var errs []error
-	cli := r.client
 
-	contour, err := objgw.ContourForGateway(ctx, cli, gw)
+	contour, err := objgw.ContourForGateway(ctx, r.client, gw)
 	if err != nil {
 		return fmt.Errorf("failed to get contour for gateway %s/%s", gw.Namespace, gw.Name)
 	}
+	cli := events.NewRecordingClient(opmetrics.NewClient(r.client), r.recorder, gw)
 
 	handleResult := func(resource string, err error) {
 		if err != nil {
@@ -261,11 +362,20 @@ func (r *reconciler) ensureGatewayDeleted(ctx context.Context, gw *gatewayv1alph
 	}
 
 	handleResult("contour service", objsvc.EnsureContourServiceDeleted(ctx, cli, contour))
+	if r.config.PodMonitors {
+		handleResult("pod monitors", objpm.EnsurePodMonitorsDeleted(ctx, cli, contour))
+	}
+	if r.config.CertManager {
+		handleResult("certificates", objcertmgr.EnsureCertificatesDeleted(ctx, cli, contour))
+	}
 	handleResult("daemonset", objds.EnsureDaemonSetDeleted(ctx, cli, contour))
+	handleResult("envoy deployment", objdeploy.EnsureEnvoyDeploymentDeleted(ctx, cli, contour))
 	handleResult("deployment", objdeploy.EnsureDeploymentDeleted(ctx, cli, contour))
 	handleResult("job", objjob.EnsureJobDeleted(ctx, cli, contour))
-	handleResult("configmap", objcm.Delete(ctx, cli, objcm.NewCfgForGateway(gw)))
+	handleResult("configmap", objcm.Delete(ctx, cli, objcm.NewCfgForGateway(contour, gw)))
 	handleResult("rbac", objutil.EnsureRBACDeleted(ctx, cli, contour))
+	handleResult("legacy resources", objlegacy.EnsureDeleted(ctx, cli, contour))
+	handleResult("legacy configmap", objlegacy.EnsureGatewayConfigMapDeleted(ctx, cli, gw))
 
 	if len(errs) > 0 {
 		return utilerrors.NewAggregate(errs)
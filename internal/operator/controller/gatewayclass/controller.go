@@ -19,13 +19,16 @@ import (
 
 	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
 	objgc "github.com/projectcontour/contour-operator/internal/objects/gatewayclass"
+	"github.com/projectcontour/contour-operator/internal/operator/events"
 	"github.com/projectcontour/contour-operator/internal/operator/status"
 	retryable "github.com/projectcontour/contour-operator/internal/retryableerror"
 	"github.com/projectcontour/contour-operator/pkg/validation"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -42,16 +45,18 @@ const (
 
 // Reconciler reconciles a GatewayClass object.
 type reconciler struct {
-	client client.Client
-	log    logr.Logger
+	client   client.Client
+	recorder record.EventRecorder
+	log      logr.Logger
 }
 
 // New creates the gatewayclass controller from mgr. The controller will be pre-configured
 // to watch for GatewayClass objects.
 func New(mgr manager.Manager) (controller.Controller, error) {
 	r := &reconciler{
-		client: mgr.GetClient(),
-		log:    ctrl.Log.WithName(controllerName),
+		client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor(controllerName),
+		log:      ctrl.Log.WithName(controllerName),
 	}
 	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
 	if err != nil {
@@ -100,7 +105,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			}
 			if len(cntrs) > 0 {
 				for i, cntr := range cntrs {
-					if err := status.SyncContour(ctx, r.client, &cntrs[i]); err != nil {
+					if err := status.SyncContour(ctx, r.client, &cntrs[i], nil); err != nil {
 						return ctrl.Result{}, fmt.Errorf("failed to sync status for contour %s/%s: %w", cntr.Namespace, cntr.Name, err)
 					}
 					r.log.Info("synced contour for gatewayclass", "name", gc.Name)
@@ -122,6 +127,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		valid := false
 		if owned {
 			if err := validation.GatewayClass(gc); err != nil {
+				r.recorder.Event(gc, corev1.EventTypeWarning, events.ReasonInvalid, err.Error())
 				errs = append(errs, fmt.Errorf("invalid gatewayclass %s: %w", gc.Name, err))
 			} else {
 				valid = true
@@ -140,7 +146,7 @@ func (r *reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 		if len(cntrs) > 0 {
 			for i, cntr := range cntrs {
-				if err := status.SyncContour(ctx, r.client, &cntrs[i]); err != nil {
+				if err := status.SyncContour(ctx, r.client, &cntrs[i], nil); err != nil {
 					return ctrl.Result{}, fmt.Errorf("failed to sync status for contour %s/%s: %w", cntr.Namespace, cntr.Name, err)
 				}
 				r.log.Info("synced status for contour", "namespace", cntr.Namespace, "name", cntr.Name)
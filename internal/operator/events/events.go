@@ -0,0 +1,121 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// ReasonCreated is the reason of an event for a created object.
+	ReasonCreated = "Created"
+	// ReasonUpdated is the reason of an event for an updated object.
+	ReasonUpdated = "Updated"
+	// ReasonDeleted is the reason of an event for a deleted object.
+	ReasonDeleted = "Deleted"
+	// ReasonCreateFailed is the reason of an event for an object that failed
+	// to be created.
+	ReasonCreateFailed = "CreateFailed"
+	// ReasonUpdateFailed is the reason of an event for an object that failed
+	// to be updated.
+	ReasonUpdateFailed = "UpdateFailed"
+	// ReasonDeleteFailed is the reason of an event for an object that failed
+	// to be deleted.
+	ReasonDeleteFailed = "DeleteFailed"
+	// ReasonInvalid is the reason of an event for an object that failed
+	// validation.
+	ReasonInvalid = "Invalid"
+	// ReasonRetrying is the reason of an event for a reconciliation that
+	// failed with a retryable error.
+	ReasonRetrying = "Retrying"
+)
+
+// recordingClient is a client that records an event on an involved object
+// for each object created, updated or deleted through the client.
+type recordingClient struct {
+	client.Client
+	recorder record.EventRecorder
+	involved runtime.Object
+}
+
+// NewRecordingClient returns a client that uses cli and records an event on
+// involved with recorder for each object created, updated or deleted through
+// the client, e.g. the objects managed for a Contour.
+func NewRecordingClient(cli client.Client, recorder record.EventRecorder, involved runtime.Object) client.Client {
+	return &recordingClient{
+		Client:   cli,
+		recorder: recorder,
+		involved: involved,
+	}
+}
+
+// Create creates obj and records a Created or CreateFailed event. No event is
+// recorded if obj already exists, e.g. while a deleted object is recreated.
+func (c *recordingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.Client.Create(ctx, obj, opts...); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			c.recorder.Eventf(c.involved, corev1.EventTypeWarning, ReasonCreateFailed, "Failed to create %s: %v", c.describe(obj), err)
+		}
+		return err
+	}
+	c.recorder.Eventf(c.involved, corev1.EventTypeNormal, ReasonCreated, "Created %s", c.describe(obj))
+	return nil
+}
+
+// Update updates obj and records an Updated or UpdateFailed event. No event is
+// recorded on a conflict, since the update is retried with the latest obj.
+func (c *recordingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := c.Client.Update(ctx, obj, opts...); err != nil {
+		if !errors.IsConflict(err) {
+			c.recorder.Eventf(c.involved, corev1.EventTypeWarning, ReasonUpdateFailed, "Failed to update %s: %v", c.describe(obj), err)
+		}
+		return err
+	}
+	c.recorder.Eventf(c.involved, corev1.EventTypeNormal, ReasonUpdated, "Updated %s", c.describe(obj))
+	return nil
+}
+
+// Delete deletes obj and records a Deleted or DeleteFailed event. No event is
+// recorded if obj does not exist.
+func (c *recordingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if err := c.Client.Delete(ctx, obj, opts...); err != nil {
+		if !errors.IsNotFound(err) {
+			c.recorder.Eventf(c.involved, corev1.EventTypeWarning, ReasonDeleteFailed, "Failed to delete %s: %v", c.describe(obj), err)
+		}
+		return err
+	}
+	c.recorder.Eventf(c.involved, corev1.EventTypeNormal, ReasonDeleted, "Deleted %s", c.describe(obj))
+	return nil
+}
+
+// describe returns the kind and namespaced name of obj, e.g.
+// "Deployment projectcontour/contour".
+func (c *recordingClient) describe(obj client.Object) string {
+	kind := fmt.Sprintf("%T", obj)
+	if gvk, err := apiutil.GVKForObject(obj, c.Scheme()); err == nil {
+		kind = gvk.Kind
+	}
+	if len(obj.GetNamespace()) == 0 {
+		return fmt.Sprintf("%s %s", kind, obj.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", kind, obj.GetNamespace(), obj.GetName())
+}
@@ -0,0 +1,104 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"testing"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRecordingClient(t *testing.T) {
+	cntr := &operatorv1alpha1.Contour{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-contour",
+			Namespace: "test-contour-ns",
+		},
+	}
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "contour",
+			Namespace: "projectcontour",
+		},
+	}
+	recorder := record.NewFakeRecorder(10)
+	cli := NewRecordingClient(fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(), recorder, cntr)
+
+	testCases := []struct {
+		description string
+		action      func() error
+		expectErr   bool
+		expected    string
+	}{
+		{
+			description: "create",
+			action:      func() error { return cli.Create(context.TODO(), deploy) },
+			expected:    "Normal Created Created Deployment projectcontour/contour",
+		},
+		{
+			description: "create existing",
+			action: func() error {
+				existing := deploy.DeepCopy()
+				existing.ResourceVersion = ""
+				return cli.Create(context.TODO(), existing)
+			},
+			expectErr: true,
+		},
+		{
+			description: "update",
+			action:      func() error { return cli.Update(context.TODO(), deploy) },
+			expected:    "Normal Updated Updated Deployment projectcontour/contour",
+		},
+		{
+			description: "delete",
+			action:      func() error { return cli.Delete(context.TODO(), deploy) },
+			expected:    "Normal Deleted Deleted Deployment projectcontour/contour",
+		},
+		{
+			description: "delete nonexistent",
+			action:      func() error { return cli.Delete(context.TODO(), deploy) },
+			expectErr:   true,
+		},
+		{
+			description: "update nonexistent",
+			action:      func() error { return cli.Update(context.TODO(), deploy) },
+			expectErr:   true,
+			expected:    "Warning UpdateFailed Failed to update Deployment projectcontour/contour",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := tc.action()
+		if tc.expectErr != (err != nil) {
+			t.Fatalf("%q: expected error %t, got %v", tc.description, tc.expectErr, err)
+		}
+		select {
+		case event := <-recorder.Events:
+			if len(tc.expected) == 0 || len(event) < len(tc.expected) || event[:len(tc.expected)] != tc.expected {
+				t.Errorf("%q: expected event %q, got %q", tc.description, tc.expected, event)
+			}
+		default:
+			if len(tc.expected) > 0 {
+				t.Errorf("%q: expected event %q, got none", tc.description, tc.expected)
+			}
+		}
+	}
+}
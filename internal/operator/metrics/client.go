@@ -0,0 +1,74 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// instrumentedClient is a client that counts the objects created, updated
+// or deleted through the client.
+type instrumentedClient struct {
+	client.Client
+}
+
+// NewClient returns a client that uses cli and counts the objects created,
+// updated or deleted through the client. Updates are not counted as drift
+// corrections, since objects are also updated for other reasons, e.g. to
+// rotate xDS TLS certificates; see the drift package.
+func NewClient(cli client.Client) client.Client {
+	return &instrumentedClient{Client: cli}
+}
+
+// Create creates obj and counts the create operation.
+func (c *instrumentedClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	err := c.Client.Create(ctx, obj, opts...)
+	c.count(obj, OperationCreate, err)
+	return err
+}
+
+// Update updates obj and counts the update operation.
+func (c *instrumentedClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	err := c.Client.Update(ctx, obj, opts...)
+	c.count(obj, OperationUpdate, err)
+	return err
+}
+
+// Delete deletes obj and counts the delete operation.
+func (c *instrumentedClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	err := c.Client.Delete(ctx, obj, opts...)
+	c.count(obj, OperationDelete, err)
+	return err
+}
+
+// count counts operation on obj with the result of err.
+func (c *instrumentedClient) count(obj client.Object, operation string, err error) {
+	result := ResultSuccess
+	if err != nil {
+		result = ResultError
+	}
+	objectOperations.WithLabelValues(c.kind(obj), operation, result).Inc()
+}
+
+// kind returns the kind of obj, e.g. "Deployment".
+func (c *instrumentedClient) kind(obj client.Object) string {
+	if gvk, err := apiutil.GVKForObject(obj, c.Scheme()); err == nil {
+		return gvk.Kind
+	}
+	return fmt.Sprintf("%T", obj)
+}
@@ -0,0 +1,94 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	objjob "github.com/projectcontour/contour-operator/internal/objects/job"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	contoursDesc = prometheus.NewDesc(
+		"contour_operator_contours",
+		"Number of Contours managed by the operator by the status of their Available condition.",
+		[]string{"available"}, nil,
+	)
+	certgenJobFailuresDesc = prometheus.NewDesc(
+		"contour_operator_certgen_job_failures",
+		"Number of Contours whose certgen job failed.",
+		nil, nil,
+	)
+)
+
+// contourCollector collects the metrics of the Contours managed by the
+// operator at scrape time.
+type contourCollector struct {
+	client client.Client
+}
+
+// newContourCollector returns a collector of the Contours read with cli.
+func newContourCollector(cli client.Client) *contourCollector {
+	return &contourCollector{client: cli}
+}
+
+// Describe sends the descriptors of the metrics collected by c to ch.
+func (c *contourCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- contoursDesc
+	ch <- certgenJobFailuresDesc
+}
+
+// Collect sends the metrics of the Contours managed by the operator to ch.
+func (c *contourCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+	contours := &operatorv1alpha1.ContourList{}
+	if err := c.client.List(ctx, contours); err != nil {
+		ch <- prometheus.NewInvalidMetric(contoursDesc, err)
+		ch <- prometheus.NewInvalidMetric(certgenJobFailuresDesc, err)
+		return
+	}
+	available := map[metav1.ConditionStatus]int{
+		metav1.ConditionTrue:    0,
+		metav1.ConditionFalse:   0,
+		metav1.ConditionUnknown: 0,
+	}
+	failures := 0
+	for i := range contours.Items {
+		contour := &contours.Items[i]
+		available[availableStatus(contour)]++
+		if job, err := objjob.CurrentJob(ctx, c.client, contour); err == nil && objjob.Failed(job) {
+			failures++
+		}
+	}
+	for status, count := range available {
+		ch <- prometheus.MustNewConstMetric(contoursDesc, prometheus.GaugeValue, float64(count), string(status))
+	}
+	ch <- prometheus.MustNewConstMetric(certgenJobFailuresDesc, prometheus.GaugeValue, float64(failures))
+}
+
+// availableStatus returns the status of the Available condition of contour,
+// or Unknown if contour has no Available condition.
+func availableStatus(contour *operatorv1alpha1.Contour) metav1.ConditionStatus {
+	cond := meta.FindStatusCondition(contour.Status.Conditions, operatorv1alpha1.ContourAvailableConditionType)
+	if cond == nil {
+		return metav1.ConditionUnknown
+	}
+	return cond.Status
+}
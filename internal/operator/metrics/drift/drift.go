@@ -0,0 +1,48 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drift
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// corrections counts the managed objects updated or recreated since
+// internal/equality detected that their config drifted from the desired
+// config.
+var corrections = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "contour_operator_drift_corrections_total",
+		Help: "Total number of managed objects updated or recreated to correct a drift from their desired config.",
+	},
+	[]string{"kind"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(corrections)
+}
+
+// ObserveCorrection counts a correction of the drift of obj from its desired
+// config, using the scheme of cli to get the kind of obj.
+func ObserveCorrection(cli client.Client, obj client.Object) {
+	kind := fmt.Sprintf("%T", obj)
+	if gvk, err := apiutil.GVKForObject(obj, cli.Scheme()); err == nil {
+		kind = gvk.Kind
+	}
+	corrections.WithLabelValues(kind).Inc()
+}
@@ -0,0 +1,62 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drift
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestObserveCorrection(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).Build()
+	pm := &unstructured.Unstructured{}
+	pm.SetAPIVersion("monitoring.coreos.com/v1")
+	pm.SetKind("PodMonitor")
+
+	testCases := []struct {
+		description string
+		obj         client.Object
+		expected    string
+	}{
+		{
+			description: "typed object",
+			obj:         &appsv1.Deployment{},
+			expected:    "Deployment",
+		},
+		{
+			description: "unstructured object",
+			obj:         pm,
+			expected:    "PodMonitor",
+		},
+	}
+
+	corrections.Reset()
+	for _, tc := range testCases {
+		ObserveCorrection(cli, tc.obj)
+		if got := testutil.ToFloat64(corrections.WithLabelValues(tc.expected)); got != 1 {
+			t.Errorf("%q: expected 1 drift correction of kind %s, got %v", tc.description, tc.expected, got)
+		}
+	}
+}
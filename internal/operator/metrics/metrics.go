@@ -0,0 +1,73 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// OperationCreate is the operation label value of a created object.
+	OperationCreate = "create"
+	// OperationUpdate is the operation label value of an updated object.
+	OperationUpdate = "update"
+	// OperationDelete is the operation label value of a deleted object.
+	OperationDelete = "delete"
+
+	// ResultSuccess is the result label value of a successful operation.
+	ResultSuccess = "success"
+	// ResultError is the result label value of a failed operation.
+	ResultError = "error"
+)
+
+var (
+	// objectOperations counts the create, update and delete operations on
+	// the objects managed for Contours.
+	objectOperations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "contour_operator_object_operations_total",
+			Help: "Total number of create, update and delete operations on objects managed by the operator.",
+		},
+		[]string{"kind", "operation", "result"},
+	)
+
+	// timeToAvailable observes the time Contours take to become available.
+	timeToAvailable = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "contour_operator_contour_time_to_available_seconds",
+			Help:    "Time taken by a Contour to become available since it was created or last became unavailable.",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+		},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(objectOperations, timeToAvailable)
+}
+
+// ObserveTimeToAvailable records d as the time a Contour took to become
+// available.
+func ObserveTimeToAvailable(d time.Duration) {
+	timeToAvailable.Observe(d.Seconds())
+}
+
+// Register registers the collector of the Contours managed by the operator,
+// read with cli, with the controller-runtime metrics registry.
+func Register(cli client.Client) error {
+	return metrics.Registry.Register(newContourCollector(cli))
+}
@@ -0,0 +1,177 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+	objjob "github.com/projectcontour/contour-operator/internal/objects/job"
+	"github.com/projectcontour/contour-operator/internal/operator/config"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func testScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := operatorv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+func TestInstrumentedClient(t *testing.T) {
+	cntr := &operatorv1alpha1.Contour{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-contour",
+			Namespace: "test-contour-ns",
+		},
+	}
+	owned := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "contour",
+			Namespace: "projectcontour",
+			Labels:    objcontour.OwnerLabels(cntr),
+		},
+	}
+	unowned := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "other",
+			Namespace: "projectcontour",
+		},
+	}
+	objectOperations.Reset()
+	cli := NewClient(fake.NewClientBuilder().WithScheme(testScheme(t)).Build())
+
+	testCases := []struct {
+		description string
+		action      func() error
+		operation   string
+		result      string
+	}{
+		{
+			description: "create owned object",
+			action:      func() error { return cli.Create(context.TODO(), owned) },
+			operation:   OperationCreate,
+			result:      ResultSuccess,
+		},
+		{
+			description: "create existing object",
+			action: func() error {
+				existing := owned.DeepCopy()
+				existing.ResourceVersion = ""
+				return cli.Create(context.TODO(), existing)
+			},
+			operation: OperationCreate,
+			result:    ResultError,
+		},
+		{
+			description: "update owned object",
+			action:      func() error { return cli.Update(context.TODO(), owned) },
+			operation:   OperationUpdate,
+			result:      ResultSuccess,
+		},
+		{
+			description: "update unowned object",
+			action: func() error {
+				if err := cli.Create(context.TODO(), unowned); err != nil {
+					return err
+				}
+				return cli.Update(context.TODO(), unowned)
+			},
+			operation: OperationUpdate,
+			result:    ResultSuccess,
+		},
+		{
+			description: "delete owned object",
+			action:      func() error { return cli.Delete(context.TODO(), owned) },
+			operation:   OperationDelete,
+			result:      ResultSuccess,
+		},
+		{
+			description: "delete missing object",
+			action:      func() error { return cli.Delete(context.TODO(), owned) },
+			operation:   OperationDelete,
+			result:      ResultError,
+		},
+	}
+
+	for _, tc := range testCases {
+		counter := objectOperations.WithLabelValues("Deployment", tc.operation, tc.result)
+		before := testutil.ToFloat64(counter)
+		_ = tc.action()
+		if got := testutil.ToFloat64(counter) - before; got != 1 {
+			t.Errorf("%q: expected 1 %s %s operation, got %v", tc.description, tc.operation, tc.result, got)
+		}
+	}
+}
+
+func TestContourCollector(t *testing.T) {
+	contour := func(name string, available metav1.ConditionStatus) *operatorv1alpha1.Contour {
+		cntr := &operatorv1alpha1.Contour{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: operatorv1alpha1.ContourSpec{
+				Namespace: operatorv1alpha1.NamespaceSpec{Name: name},
+			},
+		}
+		if len(available) > 0 {
+			cntr.Status.Conditions = []metav1.Condition{{
+				Type:   operatorv1alpha1.ContourAvailableConditionType,
+				Status: available,
+			}}
+		}
+		return cntr
+	}
+	failed := objjob.DesiredJob(contour("unavailable", ""), config.DefaultContourImage)
+	failed.Status.Conditions = []batchv1.JobCondition{{
+		Type:   batchv1.JobFailed,
+		Status: corev1.ConditionTrue,
+	}}
+	cli := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(
+		contour("available", metav1.ConditionTrue),
+		contour("unavailable", metav1.ConditionFalse),
+		contour("new", ""),
+		failed,
+	).Build()
+
+	expected := `
+# HELP contour_operator_certgen_job_failures Number of Contours whose certgen job failed.
+# TYPE contour_operator_certgen_job_failures gauge
+contour_operator_certgen_job_failures 1
+# HELP contour_operator_contours Number of Contours managed by the operator by the status of their Available condition.
+# TYPE contour_operator_contours gauge
+contour_operator_contours{available="False"} 1
+contour_operator_contours{available="True"} 1
+contour_operator_contours{available="Unknown"} 1
+`
+	if err := testutil.CollectAndCompare(newContourCollector(cli), strings.NewReader(expected)); err != nil {
+		t.Errorf("unexpected metrics: %v", err)
+	}
+}
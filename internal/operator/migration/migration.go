@@ -0,0 +1,172 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	migratorName = "storage_version_migrator"
+	// ContourCRDName is the name of the Contour CRD.
+	ContourCRDName = "contours.operator.projectcontour.io"
+)
+
+// backoff is the backoff used to retry a failed migration.
+var backoff = wait.Backoff{
+	Duration: 5 * time.Second,
+	Factor:   2,
+	Steps:    8,
+	Cap:      5 * time.Minute,
+}
+
+// Migrator rewrites Contour objects stored in a version other than the
+// storage version of the Contour CRD, then removes the other versions from
+// the stored versions of the CRD, so that a version can later be removed
+// from the CRD.
+type Migrator struct {
+	client client.Client
+	log    logr.Logger
+}
+
+// New creates a Migrator from mgr and adds it to mgr, which runs it once
+// the operator is elected leader.
+func New(mgr manager.Manager) (*Migrator, error) {
+	m := &Migrator{
+		client: mgr.GetClient(),
+		log:    ctrl.Log.WithName(migratorName),
+	}
+	if err := mgr.Add(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Start migrates Contour objects to the storage version, retrying failed
+// migrations with backoff until ctx is done. A failed migration is logged
+// instead of stopping the operator, since the stored objects remain usable.
+func (m *Migrator) Start(ctx context.Context) error {
+	err := wait.ExponentialBackoffWithContext(ctx, backoff, func() (bool, error) {
+		if err := m.Migrate(ctx); err != nil {
+			m.log.Error(err, "failed to migrate contours to the storage version; retrying")
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil && ctx.Err() == nil {
+		m.log.Error(err, "failed to migrate contours to the storage version")
+	}
+	return nil
+}
+
+// NeedLeaderElection returns true, since only the leader should migrate.
+func (m *Migrator) NeedLeaderElection() bool {
+	return true
+}
+
+// Migrate rewrites all Contour objects if the Contour CRD lists a stored
+// version other than its storage version. Updating an object without
+// changes makes the API server store the object in the storage version.
+func (m *Migrator) Migrate(ctx context.Context) error {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := m.client.Get(ctx, types.NamespacedName{Name: ContourCRDName}, crd); err != nil {
+		return fmt.Errorf("failed to get crd %s: %w", ContourCRDName, err)
+	}
+	storageVersion := storageVersionOf(crd)
+	if len(storageVersion) == 0 {
+		return fmt.Errorf("failed to find storage version of crd %s", ContourCRDName)
+	}
+	if !needsMigration(crd, storageVersion) {
+		return nil
+	}
+
+	contours := &operatorv1alpha1.ContourList{}
+	if err := m.client.List(ctx, contours); err != nil {
+		return fmt.Errorf("failed to list contours: %w", err)
+	}
+	for i := range contours.Items {
+		if err := m.rewrite(ctx, &contours.Items[i]); err != nil {
+			return err
+		}
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := m.client.Get(ctx, types.NamespacedName{Name: ContourCRDName}, crd); err != nil {
+			return err
+		}
+		crd.Status.StoredVersions = []string{storageVersion}
+		return m.client.Status().Update(ctx, crd)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update stored versions of crd %s: %w", ContourCRDName, err)
+	}
+	m.log.Info("migrated contours to the storage version", "version", storageVersion,
+		"count", len(contours.Items))
+	return nil
+}
+
+// rewrite updates contour without changes, refreshing contour on conflicts.
+// A contour that no longer exists is skipped.
+func (m *Migrator) rewrite(ctx context.Context, contour *operatorv1alpha1.Contour) error {
+	key := types.NamespacedName{Namespace: contour.Namespace, Name: contour.Name}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		err := m.client.Update(ctx, contour)
+		if errors.IsConflict(err) {
+			if getErr := m.client.Get(ctx, key, contour); getErr != nil {
+				return getErr
+			}
+		}
+		return err
+	})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to migrate contour %s/%s: %w", contour.Namespace, contour.Name, err)
+	}
+	return nil
+}
+
+// storageVersionOf returns the storage version of crd, or an empty string if
+// crd has no storage version.
+func storageVersionOf(crd *apiextensionsv1.CustomResourceDefinition) string {
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			return v.Name
+		}
+	}
+	return ""
+}
+
+// needsMigration returns true if crd lists a stored version other than
+// storageVersion.
+func needsMigration(crd *apiextensionsv1.CustomResourceDefinition, storageVersion string) bool {
+	for _, v := range crd.Status.StoredVersions {
+		if v != storageVersion {
+			return true
+		}
+	}
+	return false
+}
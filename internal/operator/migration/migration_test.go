@@ -0,0 +1,99 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"context"
+	"testing"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+
+	"github.com/go-logr/logr"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMigrate(t *testing.T) {
+	testCases := []struct {
+		description    string
+		storedVersions []string
+		expectRewrite  bool
+	}{
+		{
+			description:    "only storage version stored",
+			storedVersions: []string{"v1beta1"},
+		},
+		{
+			description:    "other version stored",
+			storedVersions: []string{"v1alpha1", "v1beta1"},
+			expectRewrite:  true,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := operatorv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := apiextensionsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range testCases {
+		crd := &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: ContourCRDName},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+					{Name: "v1alpha1", Served: true},
+					{Name: "v1beta1", Served: true, Storage: true},
+				},
+			},
+			Status: apiextensionsv1.CustomResourceDefinitionStatus{
+				StoredVersions: tc.storedVersions,
+			},
+		}
+		cntr := &operatorv1alpha1.Contour{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "test"},
+		}
+		cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(crd, cntr).Build()
+		m := &Migrator{client: cli, log: logr.Discard()}
+
+		ctx := context.TODO()
+		before := &operatorv1alpha1.Contour{}
+		key := types.NamespacedName{Namespace: cntr.Namespace, Name: cntr.Name}
+		if err := cli.Get(ctx, key, before); err != nil {
+			t.Fatalf("%q: failed to get contour: %v", tc.description, err)
+		}
+		if err := m.Migrate(ctx); err != nil {
+			t.Fatalf("%q: failed to migrate: %v", tc.description, err)
+		}
+
+		after := &operatorv1alpha1.Contour{}
+		if err := cli.Get(ctx, key, after); err != nil {
+			t.Fatalf("%q: failed to get contour: %v", tc.description, err)
+		}
+		rewritten := after.ResourceVersion != before.ResourceVersion
+		if rewritten != tc.expectRewrite {
+			t.Errorf("%q: expected contour to be rewritten: %t", tc.description, tc.expectRewrite)
+		}
+		if err := cli.Get(ctx, types.NamespacedName{Name: ContourCRDName}, crd); err != nil {
+			t.Fatalf("%q: failed to get crd: %v", tc.description, err)
+		}
+		if len(crd.Status.StoredVersions) != 1 || crd.Status.StoredVersions[0] != "v1beta1" {
+			t.Errorf("%q: expected stored versions [v1beta1], got %v", tc.description, crd.Status.StoredVersions)
+		}
+	}
+}
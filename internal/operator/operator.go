@@ -18,13 +18,19 @@ import (
 	"fmt"
 
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	objcertmgr "github.com/projectcontour/contour-operator/internal/objects/certmanager"
+	objpm "github.com/projectcontour/contour-operator/internal/objects/podmonitor"
 	operatorconfig "github.com/projectcontour/contour-operator/internal/operator/config"
 	contourcontroller "github.com/projectcontour/contour-operator/internal/operator/controller/contour"
 	gwcontroller "github.com/projectcontour/contour-operator/internal/operator/controller/gateway"
 	gccontroller "github.com/projectcontour/contour-operator/internal/operator/controller/gatewayclass"
+	opmetrics "github.com/projectcontour/contour-operator/internal/operator/metrics"
+	"github.com/projectcontour/contour-operator/internal/operator/migration"
+	"github.com/projectcontour/contour-operator/internal/operator/webhook"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
@@ -61,6 +67,8 @@ type Operator struct {
 // +kubebuilder:rbac:groups="",resources=namespaces;secrets;serviceaccounts;services,verbs=get;list;watch;delete;create;update
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;delete;create;update
 // +kubebuilder:rbac:groups="",resources=endpoints,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update
 // +kubebuilder:rbac:groups=networking.x-k8s.io,resources=gatewayclasses;gateways;backendpolicies;httproutes;tlsroutes,verbs=get;list;watch;update
 // +kubebuilder:rbac:groups=networking.x-k8s.io,resources=gatewayclasses/status;gateways/status;backendpolicies/status;httproutes/status;tlsroutes/status,verbs=create;get;update
@@ -76,42 +84,67 @@ type Operator struct {
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;delete;create;update
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;delete;create;update
 // +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;delete;create;update
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=podmonitors,verbs=get;list;watch;delete;create;update
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;delete;create;update
 // +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions/status,verbs=get;update
 
 // New creates a new operator from cliCfg and opCfg.
 func New(cliCfg *rest.Config, opCfg *operatorconfig.Config) (*Operator, error) {
 	nonCached := []client.Object{&operatorv1alpha1.Contour{}, &gatewayv1alpha1.GatewayClass{},
-		&gatewayv1alpha1.Gateway{}, &apiextensionsv1.CustomResourceDefinition{}}
+		&gatewayv1alpha1.Gateway{}, &apiextensionsv1.CustomResourceDefinition{}, &corev1.Secret{}, &corev1.Pod{}}
 	mgrOpts := manager.Options{
 		Scheme:                GetOperatorScheme(),
 		LeaderElection:        opCfg.LeaderElection,
 		LeaderElectionID:      opCfg.LeaderElectionID,
 		MetricsBindAddress:    opCfg.MetricsBindAddress,
 		ClientDisableCacheFor: nonCached,
+		Port:                  opCfg.WebhookPort,
+		CertDir:               opCfg.WebhookCertDir,
 	}
 	mgr, err := ctrl.NewManager(cliCfg, mgrOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create manager: %w", err)
 	}
 
+	restMapper, err := apiutil.NewDiscoveryRESTMapper(cliCfg)
+	if err != nil {
+		return nil, err
+	}
+	o := &Operator{
+		manager: mgr,
+		client:  Client{mgr.GetClient(), restMapper},
+		log:     ctrl.Log.WithName(operatorName),
+	}
+
 	// Create and register the contour controller with the operator manager.
 	if _, err := contourcontroller.New(mgr, contourcontroller.Config{
 		ContourImage: opCfg.ContourImage,
 		EnvoyImage:   opCfg.EnvoyImage,
+		PodMonitors:  o.podMonitorCRDExists(),
+		CertManager:  o.certificateCRDExists(),
 	}); err != nil {
 		return nil, fmt.Errorf("failed to create contour controller: %w", err)
 	}
 
-	restMapper, err := apiutil.NewDiscoveryRESTMapper(cliCfg)
-	if err != nil {
-		return nil, err
+	// Register the collector of the Contours managed by the operator with
+	// the metrics registry of the operator manager.
+	if err := opmetrics.Register(mgr.GetClient()); err != nil {
+		return nil, fmt.Errorf("failed to register metrics: %w", err)
 	}
 
-	return &Operator{
-		manager: mgr,
-		client:  Client{mgr.GetClient(), restMapper},
-		log:     ctrl.Log.WithName(operatorName),
-	}, nil
+	// Create and register the storage version migrator with the operator manager.
+	if _, err := migration.New(mgr); err != nil {
+		return nil, fmt.Errorf("failed to create storage version migrator: %w", err)
+	}
+
+	// Register the conversion, defaulting and validating webhooks with the
+	// operator manager.
+	if opCfg.EnableWebhooks {
+		webhook.Register(mgr)
+	}
+
+	return o, nil
 }
 
 // Start creates Gateway API controllers (if configured) and starts the operator
@@ -148,6 +181,8 @@ func (o *Operator) createGatewayControllers(opCfg *operatorconfig.Config) error
 		cfg := gwcontroller.Config{
 			ContourImage: opCfg.ContourImage,
 			EnvoyImage:   opCfg.EnvoyImage,
+			PodMonitors:  o.podMonitorCRDExists(),
+			CertManager:  o.certificateCRDExists(),
 		}
 		if _, err := gwcontroller.New(o.manager, cfg); err != nil {
 			return fmt.Errorf("failed to create gateway controller: %w", err)
@@ -167,6 +202,26 @@ func (o *Operator) gatewayCRDsExist() bool {
 	return true
 }
 
+// podMonitorCRDExists returns true if the PodMonitor CRD of the Prometheus
+// Operator exists.
+func (o *Operator) podMonitorCRDExists() bool {
+	if _, err := o.client.KindFor(objpm.GroupVersionResource); meta.IsNoMatchError(err) {
+		o.log.Info("PodMonitor CRD not found; starting operator without pod monitors")
+		return false
+	}
+	return true
+}
+
+// certificateCRDExists returns true if the Certificate CRD of cert-manager
+// exists.
+func (o *Operator) certificateCRDExists() bool {
+	if _, err := o.client.KindFor(objcertmgr.GroupVersionResource); meta.IsNoMatchError(err) {
+		o.log.Info("Certificate CRD not found; starting operator without cert-manager certificates")
+		return false
+	}
+	return true
+}
+
 // GatewayAPIResources for Operator.
 // The list omits TCP and UDP routes since they're unsupported by operator.
 func GatewayAPIResources() []schema.GroupVersionResource {
@@ -15,6 +15,7 @@ package operator
 
 import (
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	operatorv1beta1 "github.com/projectcontour/contour-operator/api/v1beta1"
 
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -39,6 +40,9 @@ func init() {
 	if err := operatorv1alpha1.AddToScheme(scheme); err != nil {
 		panic(err)
 	}
+	if err := operatorv1beta1.AddToScheme(scheme); err != nil {
+		panic(err)
+	}
 	if err := gatewayv1alpha1.AddToScheme(scheme); err != nil {
 		panic(err)
 	}
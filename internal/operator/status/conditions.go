@@ -18,11 +18,18 @@ import (
 	"strings"
 
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	objcertmgr "github.com/projectcontour/contour-operator/internal/objects/certmanager"
+	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+	objjob "github.com/projectcontour/contour-operator/internal/objects/job"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	utilclock "k8s.io/apimachinery/pkg/util/clock"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayv1alpha1 "sigs.k8s.io/gateway-api/apis/v1alpha1"
 )
 
@@ -30,8 +37,11 @@ import (
 var clock utilclock.Clock = utilclock.RealClock{}
 
 // computeContourAvailableCondition computes the contour Available status condition
-// type based on deployment, ds, set, exists and admitted.
-func computeContourAvailableCondition(deployment *appsv1.Deployment, ds *appsv1.DaemonSet, set, exists, admitted bool) metav1.Condition {
+// type based on deployment, envoy, workloadType, set, exists and admitted. envoy is
+// the Envoy DaemonSet or Deployment of the contour as specified by workloadType,
+// or nil if it does not exist.
+func computeContourAvailableCondition(deployment *appsv1.Deployment, envoy client.Object, workloadType operatorv1alpha1.EnvoyWorkloadType,
+	set, exists, admitted bool) metav1.Condition {
 	switch {
 	case set:
 		switch {
@@ -67,22 +77,23 @@ func computeContourAvailableCondition(deployment *appsv1.Deployment, ds *appsv1.
 				Message: "Contour deployment does not exist.",
 			}
 		}
-		if ds == nil {
+		kind := strings.ToLower(string(workloadType))
+		if envoy == nil {
 			return metav1.Condition{
 				Type:    operatorv1alpha1.ContourAvailableConditionType,
 				Status:  metav1.ConditionFalse,
 				Reason:  "ContourUnavailable",
-				Message: "Envoy daemonset does not exist.",
+				Message: fmt.Sprintf("Envoy %s does not exist.", kind),
 			}
 		}
-		dsAvailable := ds.Status.NumberAvailable > 0
+		available := envoyAvailable(envoy)
 		for _, cond := range deployment.Status.Conditions {
 			if cond.Type != appsv1.DeploymentAvailable {
 				continue
 			}
 			switch {
 			case cond.Status == corev1.ConditionTrue:
-				if dsAvailable {
+				if available {
 					return metav1.Condition{
 						Type:    operatorv1alpha1.ContourAvailableConditionType,
 						Status:  metav1.ConditionTrue,
@@ -94,10 +105,10 @@ func computeContourAvailableCondition(deployment *appsv1.Deployment, ds *appsv1.
 					Type:    operatorv1alpha1.ContourAvailableConditionType,
 					Status:  metav1.ConditionFalse,
 					Reason:  "ContourUnavailable",
-					Message: "Envoy daemonset does not have minimum availability.",
+					Message: fmt.Sprintf("Envoy %s does not have minimum availability.", kind),
 				}
 			case cond.Status == corev1.ConditionFalse:
-				if dsAvailable {
+				if available {
 					return metav1.Condition{
 						Type:    operatorv1alpha1.ContourAvailableConditionType,
 						Status:  metav1.ConditionFalse,
@@ -109,8 +120,8 @@ func computeContourAvailableCondition(deployment *appsv1.Deployment, ds *appsv1.
 					Type:   operatorv1alpha1.ContourAvailableConditionType,
 					Status: metav1.ConditionFalse,
 					Reason: "ContourUnavailable",
-					Message: fmt.Sprintf("Envoy daemonset does not have minimum availability. Contour %s",
-						strings.ToLower(cond.Message)),
+					Message: fmt.Sprintf("Envoy %s does not have minimum availability. Contour %s",
+						kind, strings.ToLower(cond.Message)),
 				}
 			case cond.Status == corev1.ConditionUnknown:
 				return metav1.Condition{
@@ -131,6 +142,312 @@ func computeContourAvailableCondition(deployment *appsv1.Deployment, ds *appsv1.
 	}
 }
 
+// computeContourValidCondition computes the contour Valid status condition type
+// based on invalid, the error the contour failed validation with, if any.
+func computeContourValidCondition(invalid error) metav1.Condition {
+	if invalid != nil {
+		return metav1.Condition{
+			Type:    operatorv1alpha1.ContourValidConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  "ValidationFailed",
+			Message: fmt.Sprintf("Contour is invalid: %s.", invalid),
+		}
+	}
+	return metav1.Condition{
+		Type:    operatorv1alpha1.ContourValidConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Valid",
+		Message: "Contour is valid.",
+	}
+}
+
+// computeContourNamespaceMigratedCondition computes the contour NamespaceMigrated
+// status condition type based on applied, the namespace the resources of the
+// contour were last made available in, target, the spec.namespace.name of the
+// contour, and blocked, the reason the migration from applied to target is
+// blocked, if any.
+func computeContourNamespaceMigratedCondition(applied, target string, blocked error) metav1.Condition {
+	switch {
+	case applied == target:
+		return metav1.Condition{
+			Type:    operatorv1alpha1.ContourNamespaceMigratedConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  "NamespaceApplied",
+			Message: fmt.Sprintf("Contour runs in namespace %s.", target),
+		}
+	case blocked != nil:
+		return metav1.Condition{
+			Type:    operatorv1alpha1.ContourNamespaceMigratedConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  "MigrationBlocked",
+			Message: fmt.Sprintf("Migration from namespace %s to %s is blocked: %s.", applied, target, blocked),
+		}
+	default:
+		return metav1.Condition{
+			Type:   operatorv1alpha1.ContourNamespaceMigratedConditionType,
+			Status: metav1.ConditionFalse,
+			Reason: "MigrationInProgress",
+			Message: fmt.Sprintf("Migrating from namespace %s to %s; resources in namespace %s are removed "+
+				"once Contour is available in namespace %s.", applied, target, applied, target),
+		}
+	}
+}
+
+// computeContourComponents computes the status of the components of contour
+// based on result, the components that were ensured and the errors that
+// occurred while ensuring each component, the current deployment, envoy, job,
+// certs and svc of contour and the certificates in the status of contour. envoy
+// is the Envoy DaemonSet or Deployment as specified by workloadType, job is the
+// certgen Job and svc is the Envoy Service of contour, each nil if it does not
+// exist. certs are the existing cert-manager Certificates of contour.
+func computeContourComponents(contour *operatorv1alpha1.Contour, result *ContourResult, deployment *appsv1.Deployment,
+	envoy client.Object, workloadType operatorv1alpha1.EnvoyWorkloadType, job *batchv1.Job, certs []*unstructured.Unstructured,
+	svc *corev1.Service) []operatorv1alpha1.ComponentStatus {
+	errs := result.Errors
+	component := func(name string, ready bool, reason, msg string) operatorv1alpha1.ComponentStatus {
+		if len(errs[name]) > 0 {
+			return operatorv1alpha1.ComponentStatus{
+				Name:    name,
+				Ready:   false,
+				Reason:  fmt.Sprintf("%sFailed", name),
+				Message: utilerrors.NewAggregate(errs[name]).Error(),
+			}
+		}
+		return operatorv1alpha1.ComponentStatus{
+			Name:    name,
+			Ready:   ready,
+			Reason:  reason,
+			Message: msg,
+		}
+	}
+
+	// ensured returns the status of the component name, whose resources are
+	// described by desc, based on whether they were ensured.
+	ensured := func(name, desc string) operatorv1alpha1.ComponentStatus {
+		switch {
+		case result.ensured[name]:
+			return component(name, true, "Ensured", fmt.Sprintf("%s ensured.", desc))
+		case result.Invalid != nil:
+			return component(name, false, "Skipped", fmt.Sprintf("%s not ensured since contour is invalid.", desc))
+		default:
+			return component(name, false, "Skipped", fmt.Sprintf("%s not ensured.", desc))
+		}
+	}
+
+	components := []operatorv1alpha1.ComponentStatus{
+		ensured(operatorv1alpha1.NamespaceComponent, fmt.Sprintf("Namespace %s is", contour.Spec.Namespace.Name)),
+		ensured(operatorv1alpha1.RBACComponent, "RBAC resources are"),
+	}
+
+	switch {
+	case contour.Spec.XDSTLS.SecretRefs != nil:
+		components = append(components, component(operatorv1alpha1.CertGenComponent, true, "Provided",
+			"xDS TLS certificates are provided by secret references."))
+	case contour.Spec.XDSTLS.Provider == operatorv1alpha1.OperatorXDSTLSProvider:
+		if certificatesIssued(contour) {
+			components = append(components, component(operatorv1alpha1.CertGenComponent, true, "Issued",
+				"xDS TLS certificates are issued."))
+		} else {
+			components = append(components, component(operatorv1alpha1.CertGenComponent, false, "CertificatesPending",
+				"xDS TLS certificates are not yet issued."))
+		}
+	case contour.Spec.XDSTLS.Provider == operatorv1alpha1.CertManagerXDSTLSProvider:
+		if ready, msg := objcertmgr.Ready(contour, certs); ready {
+			components = append(components, component(operatorv1alpha1.CertGenComponent, true, "Issued", msg))
+		} else {
+			components = append(components, component(operatorv1alpha1.CertGenComponent, false, "CertificatesPending", msg))
+		}
+	case job == nil:
+		components = append(components, component(operatorv1alpha1.CertGenComponent, false, "CertGenPending",
+			"Certgen job does not exist."))
+	case job.Status.Succeeded > 0:
+		components = append(components, component(operatorv1alpha1.CertGenComponent, true, "Completed",
+			"Certgen job has completed."))
+	case objjob.Failed(job):
+		components = append(components, component(operatorv1alpha1.CertGenComponent, false, "CertGenFailed",
+			"Certgen job has failed."))
+	default:
+		components = append(components, component(operatorv1alpha1.CertGenComponent, false, "CertGenPending",
+			"Certgen job has not completed."))
+	}
+
+	contourAvailable := false
+	if deployment != nil {
+		for _, cond := range deployment.Status.Conditions {
+			if cond.Type == appsv1.DeploymentAvailable && cond.Status == corev1.ConditionTrue {
+				contourAvailable = true
+			}
+		}
+	}
+	switch {
+	case deployment == nil:
+		components = append(components, component(operatorv1alpha1.ContourComponent, false, "ContourProgressing",
+			"Contour deployment does not exist."))
+	case contourAvailable:
+		components = append(components, component(operatorv1alpha1.ContourComponent, true, "Available",
+			"Contour deployment has minimum availability."))
+	default:
+		components = append(components, component(operatorv1alpha1.ContourComponent, false, "ContourProgressing",
+			"Contour deployment does not have minimum availability."))
+	}
+
+	kind := strings.ToLower(string(workloadType))
+	switch {
+	case envoy == nil:
+		components = append(components, component(operatorv1alpha1.EnvoyComponent, false, "EnvoyProgressing",
+			fmt.Sprintf("Envoy %s does not exist.", kind)))
+	case envoyAvailable(envoy):
+		components = append(components, component(operatorv1alpha1.EnvoyComponent, true, "Available",
+			fmt.Sprintf("Envoy %s has minimum availability.", kind)))
+	default:
+		components = append(components, component(operatorv1alpha1.EnvoyComponent, false, "EnvoyProgressing",
+			fmt.Sprintf("Envoy %s does not have minimum availability.", kind)))
+	}
+
+	switch contour.Spec.NetworkPublishing.Envoy.Type {
+	case operatorv1alpha1.LoadBalancerServicePublishingType, operatorv1alpha1.NodePortServicePublishingType,
+		operatorv1alpha1.ClusterIPServicePublishingType:
+		switch {
+		case svc == nil:
+			components = append(components, component(operatorv1alpha1.EnvoyServiceComponent, false, "ServicePending",
+				"Envoy service does not exist."))
+		case svc.Spec.Type == corev1.ServiceTypeLoadBalancer && len(svc.Status.LoadBalancer.Ingress) == 0:
+			components = append(components, component(operatorv1alpha1.EnvoyServiceComponent, false, "LoadBalancerPending",
+				"Envoy service load balancer is not yet provisioned."))
+		default:
+			components = append(components, component(operatorv1alpha1.EnvoyServiceComponent, true, "Ensured",
+				"Envoy service is ensured."))
+		}
+	}
+
+	return components
+}
+
+// certificatesIssued returns true if the status of contour contains the xDS TLS
+// certificates of both Contour and Envoy.
+func certificatesIssued(contour *operatorv1alpha1.Contour) bool {
+	var contourIssued, envoyIssued bool
+	for _, cert := range contour.Status.Certificates {
+		switch cert.Name {
+		case objcontour.ContourCertsSecretName(contour):
+			contourIssued = true
+		case objcontour.EnvoyCertsSecretName(contour):
+			envoyIssued = true
+		}
+	}
+	return contourIssued && envoyIssued
+}
+
+// computeContourCertificatesReadyCondition computes the contour CertificatesReady
+// status condition type based on components, using the status of the CertGen
+// component that issues the xDS TLS certificates of the contour.
+func computeContourCertificatesReadyCondition(components []operatorv1alpha1.ComponentStatus) metav1.Condition {
+	for _, c := range components {
+		if c.Name != operatorv1alpha1.CertGenComponent {
+			continue
+		}
+		status := metav1.ConditionFalse
+		if c.Ready {
+			status = metav1.ConditionTrue
+		}
+		return metav1.Condition{
+			Type:    operatorv1alpha1.ContourCertificatesReadyConditionType,
+			Status:  status,
+			Reason:  c.Reason,
+			Message: c.Message,
+		}
+	}
+	return metav1.Condition{
+		Type:    operatorv1alpha1.ContourCertificatesReadyConditionType,
+		Status:  metav1.ConditionUnknown,
+		Reason:  "CertificatesUnknown",
+		Message: "xDS TLS certificates status unknown.",
+	}
+}
+
+// computeContourProgressingCondition computes the contour Progressing status
+// condition type based on components and errs, the errors that occurred while
+// ensuring each component. The contour is progressing while a component that
+// did not fail is not ready.
+func computeContourProgressingCondition(components []operatorv1alpha1.ComponentStatus, errs map[string][]error) metav1.Condition {
+	var reason string
+	var msgs []string
+	for _, c := range components {
+		if c.Ready || len(errs[c.Name]) > 0 {
+			continue
+		}
+		if len(reason) == 0 {
+			reason = c.Reason
+		}
+		msgs = append(msgs, c.Message)
+	}
+	if len(msgs) == 0 {
+		return metav1.Condition{
+			Type:    operatorv1alpha1.ContourProgressingConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  "AsExpected",
+			Message: "All components are ready.",
+		}
+	}
+	return metav1.Condition{
+		Type:    operatorv1alpha1.ContourProgressingConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: strings.Join(msgs, " "),
+	}
+}
+
+// computeContourDegradedCondition computes the contour Degraded status condition
+// type based on invalid, the error the contour failed validation with, if any,
+// components and errs, the errors that occurred while ensuring each component.
+func computeContourDegradedCondition(invalid error, components []operatorv1alpha1.ComponentStatus, errs map[string][]error) metav1.Condition {
+	if invalid != nil {
+		return metav1.Condition{
+			Type:    operatorv1alpha1.ContourDegradedConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  "ValidationFailed",
+			Message: fmt.Sprintf("Contour failed validation: %s.", invalid),
+		}
+	}
+	var reason string
+	var msgs []string
+	for _, c := range components {
+		if len(errs[c.Name]) == 0 {
+			continue
+		}
+		if len(reason) == 0 {
+			reason = c.Reason
+		}
+		msgs = append(msgs, fmt.Sprintf("%s: %s.", c.Name, c.Message))
+	}
+	if len(msgs) == 0 {
+		return metav1.Condition{
+			Type:    operatorv1alpha1.ContourDegradedConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  "AsExpected",
+			Message: "All components were ensured.",
+		}
+	}
+	return metav1.Condition{
+		Type:    operatorv1alpha1.ContourDegradedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: strings.Join(msgs, " "),
+	}
+}
+
+// envoyAvailable returns true if envoy, an Envoy DaemonSet or Deployment, has
+// at least one available pod.
+func envoyAvailable(envoy client.Object) bool {
+	switch workload := envoy.(type) {
+	case *appsv1.Deployment:
+		return workload.Status.AvailableReplicas > 0
+	case *appsv1.DaemonSet:
+		return workload.Status.NumberAvailable > 0
+	}
+	return false
+}
+
 // computeGatewayClassAdmittedCondition computes the Available status condition based
 // upon the GatewayClass status specification.
 func computeGatewayClassAdmittedCondition(owned, valid bool) metav1.Condition {
@@ -19,12 +19,16 @@ import (
 	"time"
 
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	utilclock "k8s.io/apimachinery/pkg/util/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func newCondition(t string, status metav1.ConditionStatus, reason, msg string, lt time.Time) metav1.Condition {
@@ -41,7 +45,8 @@ func TestComputeContourAvailableCondition(t *testing.T) {
 	testCases := []struct {
 		description      string
 		deployConditions []appsv1.DeploymentCondition
-		dsAvailable      int32
+		workloadType     operatorv1alpha1.EnvoyWorkloadType
+		envoyAvailable   int32
 		gcSet            bool
 		gcExists         bool
 		gcAdmitted       bool
@@ -52,7 +57,7 @@ func TestComputeContourAvailableCondition(t *testing.T) {
 			deployConditions: []appsv1.DeploymentCondition{
 				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
 			},
-			dsAvailable: int32(0),
+			envoyAvailable: int32(0),
 			expect: metav1.Condition{
 				Type:   operatorv1alpha1.ContourAvailableConditionType,
 				Status: metav1.ConditionUnknown,
@@ -63,7 +68,7 @@ func TestComputeContourAvailableCondition(t *testing.T) {
 			deployConditions: []appsv1.DeploymentCondition{
 				{Type: appsv1.DeploymentReplicaFailure, Status: corev1.ConditionTrue},
 			},
-			dsAvailable: int32(0),
+			envoyAvailable: int32(0),
 			expect: metav1.Condition{
 				Type:   operatorv1alpha1.ContourAvailableConditionType,
 				Status: metav1.ConditionUnknown,
@@ -74,7 +79,7 @@ func TestComputeContourAvailableCondition(t *testing.T) {
 			deployConditions: []appsv1.DeploymentCondition{
 				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
 			},
-			dsAvailable: int32(0),
+			envoyAvailable: int32(0),
 			expect: metav1.Condition{
 				Type:   operatorv1alpha1.ContourAvailableConditionType,
 				Status: metav1.ConditionFalse,
@@ -85,7 +90,7 @@ func TestComputeContourAvailableCondition(t *testing.T) {
 			deployConditions: []appsv1.DeploymentCondition{
 				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse},
 			},
-			dsAvailable: int32(1),
+			envoyAvailable: int32(1),
 			expect: metav1.Condition{
 				Type:   operatorv1alpha1.ContourAvailableConditionType,
 				Status: metav1.ConditionFalse,
@@ -96,7 +101,43 @@ func TestComputeContourAvailableCondition(t *testing.T) {
 			deployConditions: []appsv1.DeploymentCondition{
 				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
 			},
-			dsAvailable: int32(1),
+			envoyAvailable: int32(1),
+			expect: metav1.Condition{
+				Type:   operatorv1alpha1.ContourAvailableConditionType,
+				Status: metav1.ConditionTrue,
+			},
+		},
+		{
+			description: "deployment available, envoy deployment unavailable",
+			deployConditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+			},
+			workloadType:   operatorv1alpha1.DeploymentEnvoyWorkloadType,
+			envoyAvailable: int32(0),
+			expect: metav1.Condition{
+				Type:   operatorv1alpha1.ContourAvailableConditionType,
+				Status: metav1.ConditionFalse,
+			},
+		},
+		{
+			description: "deployment unavailable, envoy deployment available",
+			deployConditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse},
+			},
+			workloadType:   operatorv1alpha1.DeploymentEnvoyWorkloadType,
+			envoyAvailable: int32(1),
+			expect: metav1.Condition{
+				Type:   operatorv1alpha1.ContourAvailableConditionType,
+				Status: metav1.ConditionFalse,
+			},
+		},
+		{
+			description: "deployment available, envoy deployment available",
+			deployConditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+			},
+			workloadType:   operatorv1alpha1.DeploymentEnvoyWorkloadType,
+			envoyAvailable: int32(1),
 			expect: metav1.Condition{
 				Type:   operatorv1alpha1.ContourAvailableConditionType,
 				Status: metav1.ConditionTrue,
@@ -141,16 +182,29 @@ func TestComputeContourAvailableCondition(t *testing.T) {
 			},
 		}
 
-		ds := &appsv1.DaemonSet{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: fmt.Sprintf("contour-%d", i+1),
-			},
-			Status: appsv1.DaemonSetStatus{
-				NumberAvailable: tc.dsAvailable,
-			},
+		var envoy client.Object
+		switch tc.workloadType {
+		case operatorv1alpha1.DeploymentEnvoyWorkloadType:
+			envoy = &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: fmt.Sprintf("envoy-%d", i+1),
+				},
+				Status: appsv1.DeploymentStatus{
+					AvailableReplicas: tc.envoyAvailable,
+				},
+			}
+		default:
+			envoy = &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: fmt.Sprintf("contour-%d", i+1),
+				},
+				Status: appsv1.DaemonSetStatus{
+					NumberAvailable: tc.envoyAvailable,
+				},
+			}
 		}
 
-		actual := computeContourAvailableCondition(deploy, ds, tc.gcSet, tc.gcExists, tc.gcAdmitted)
+		actual := computeContourAvailableCondition(deploy, envoy, tc.workloadType, tc.gcSet, tc.gcExists, tc.gcAdmitted)
 		if !apiequality.Semantic.DeepEqual(actual.Type, tc.expect.Type) ||
 			!apiequality.Semantic.DeepEqual(actual.Status, tc.expect.Status) {
 			t.Fatalf("%q: expected %#v, got %#v", tc.description, tc.expect, actual)
@@ -158,6 +212,345 @@ func TestComputeContourAvailableCondition(t *testing.T) {
 	}
 }
 
+func TestComputeContourValidCondition(t *testing.T) {
+	testCases := []struct {
+		description string
+		invalid     error
+		expect      metav1.Condition
+	}{
+		{
+			description: "valid contour",
+			expect: metav1.Condition{
+				Type:   operatorv1alpha1.ContourValidConditionType,
+				Status: metav1.ConditionTrue,
+				Reason: "Valid",
+			},
+		},
+		{
+			description: "invalid contour",
+			invalid:     fmt.Errorf("another contour named test exists in namespace projectcontour"),
+			expect: metav1.Condition{
+				Type:    operatorv1alpha1.ContourValidConditionType,
+				Status:  metav1.ConditionFalse,
+				Reason:  "ValidationFailed",
+				Message: "Contour is invalid: another contour named test exists in namespace projectcontour.",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		actual := computeContourValidCondition(tc.invalid)
+		if actual.Type != tc.expect.Type || actual.Status != tc.expect.Status || actual.Reason != tc.expect.Reason {
+			t.Errorf("%q: expected %#v, got %#v", tc.description, tc.expect, actual)
+		}
+		if len(tc.expect.Message) > 0 && actual.Message != tc.expect.Message {
+			t.Errorf("%q: expected message %q, got %q", tc.description, tc.expect.Message, actual.Message)
+		}
+	}
+}
+
+func TestComputeContourNamespaceMigratedCondition(t *testing.T) {
+	testCases := []struct {
+		description string
+		applied     string
+		blocked     error
+		expect      metav1.Condition
+	}{
+		{
+			description: "applied to the target namespace",
+			applied:     "projectcontour",
+			expect: metav1.Condition{
+				Type:   operatorv1alpha1.ContourNamespaceMigratedConditionType,
+				Status: metav1.ConditionTrue,
+				Reason: "NamespaceApplied",
+			},
+		},
+		{
+			description: "migration in progress",
+			applied:     "projectcontour-old",
+			expect: metav1.Condition{
+				Type:   operatorv1alpha1.ContourNamespaceMigratedConditionType,
+				Status: metav1.ConditionFalse,
+				Reason: "MigrationInProgress",
+			},
+		},
+		{
+			description: "migration blocked",
+			applied:     "projectcontour-old",
+			blocked:     fmt.Errorf("namespace projectcontour is terminating"),
+			expect: metav1.Condition{
+				Type:   operatorv1alpha1.ContourNamespaceMigratedConditionType,
+				Status: metav1.ConditionFalse,
+				Reason: "MigrationBlocked",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		actual := computeContourNamespaceMigratedCondition(tc.applied, "projectcontour", tc.blocked)
+		if actual.Type != tc.expect.Type || actual.Status != tc.expect.Status || actual.Reason != tc.expect.Reason {
+			t.Errorf("%q: expected %#v, got %#v", tc.description, tc.expect, actual)
+		}
+	}
+}
+
+func TestComputeContourComponents(t *testing.T) {
+	availableDeploy := &appsv1.Deployment{
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+	availableEnvoy := &appsv1.DaemonSet{
+		Status: appsv1.DaemonSetStatus{NumberAvailable: int32(1)},
+	}
+	completedJob := &batchv1.Job{
+		Status: batchv1.JobStatus{Succeeded: int32(1)},
+	}
+	failedJob := &batchv1.Job{
+		Status: batchv1.JobStatus{
+			Failed: int32(2),
+			Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"},
+			},
+		},
+	}
+	provisionedSvc := &corev1.Service{
+		Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: "1.2.3.4"}},
+			},
+		},
+	}
+	pendingSvc := &corev1.Service{
+		Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+	}
+	unnamed := &operatorv1alpha1.Contour{}
+	contourCerts := objcontour.ContourCertsSecretName(unnamed)
+	envoyCerts := objcontour.EnvoyCertsSecretName(unnamed)
+	certificate := func(name, ready string) *unstructured.Unstructured {
+		cert := &unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": ready},
+				},
+			},
+		}}
+		cert.SetName(name)
+		return cert
+	}
+
+	testCases := []struct {
+		description       string
+		invalid           error
+		notEnsured        bool
+		errs              map[string][]error
+		deploy            *appsv1.Deployment
+		envoy             client.Object
+		job               *batchv1.Job
+		svc               *corev1.Service
+		xdsTLSProvider    operatorv1alpha1.XDSTLSProvider
+		certsIssued       bool
+		certs             []*unstructured.Unstructured
+		expectReasons     map[string]string
+		expectCertsReady  metav1.ConditionStatus
+		expectProgressing metav1.Condition
+		expectDegraded    metav1.Condition
+	}{
+		{
+			description: "all components ready",
+			deploy:      availableDeploy,
+			envoy:       availableEnvoy,
+			job:         completedJob,
+			svc:         provisionedSvc,
+			expectReasons: map[string]string{
+				operatorv1alpha1.NamespaceComponent:    "Ensured",
+				operatorv1alpha1.RBACComponent:         "Ensured",
+				operatorv1alpha1.CertGenComponent:      "Completed",
+				operatorv1alpha1.ContourComponent:      "Available",
+				operatorv1alpha1.EnvoyComponent:        "Available",
+				operatorv1alpha1.EnvoyServiceComponent: "Ensured",
+			},
+			expectCertsReady:  metav1.ConditionTrue,
+			expectProgressing: metav1.Condition{Status: metav1.ConditionFalse, Reason: "AsExpected"},
+			expectDegraded:    metav1.Condition{Status: metav1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			description: "load balancer pending",
+			deploy:      availableDeploy,
+			envoy:       availableEnvoy,
+			job:         completedJob,
+			svc:         pendingSvc,
+			expectReasons: map[string]string{
+				operatorv1alpha1.EnvoyServiceComponent: "LoadBalancerPending",
+			},
+			expectProgressing: metav1.Condition{Status: metav1.ConditionTrue, Reason: "LoadBalancerPending"},
+			expectDegraded:    metav1.Condition{Status: metav1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			description: "certgen job failed to be ensured",
+			errs: map[string][]error{
+				operatorv1alpha1.CertGenComponent: {fmt.Errorf("failed to create job")},
+			},
+			deploy: availableDeploy,
+			envoy:  availableEnvoy,
+			svc:    provisionedSvc,
+			expectReasons: map[string]string{
+				operatorv1alpha1.CertGenComponent: "CertGenFailed",
+			},
+			expectCertsReady:  metav1.ConditionFalse,
+			expectProgressing: metav1.Condition{Status: metav1.ConditionFalse, Reason: "AsExpected"},
+			expectDegraded:    metav1.Condition{Status: metav1.ConditionTrue, Reason: "CertGenFailed"},
+		},
+		{
+			description: "certgen job failed",
+			deploy:      availableDeploy,
+			envoy:       availableEnvoy,
+			job:         failedJob,
+			svc:         provisionedSvc,
+			expectReasons: map[string]string{
+				operatorv1alpha1.CertGenComponent: "CertGenFailed",
+			},
+			expectCertsReady:  metav1.ConditionFalse,
+			expectProgressing: metav1.Condition{Status: metav1.ConditionTrue, Reason: "CertGenFailed"},
+			expectDegraded:    metav1.Condition{Status: metav1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			description: "rbac failed to be ensured, workloads do not exist",
+			errs: map[string][]error{
+				operatorv1alpha1.RBACComponent: {fmt.Errorf("failed to create role")},
+			},
+			expectReasons: map[string]string{
+				operatorv1alpha1.RBACComponent:         "RBACFailed",
+				operatorv1alpha1.CertGenComponent:      "CertGenPending",
+				operatorv1alpha1.ContourComponent:      "ContourProgressing",
+				operatorv1alpha1.EnvoyComponent:        "EnvoyProgressing",
+				operatorv1alpha1.EnvoyServiceComponent: "ServicePending",
+			},
+			expectCertsReady:  metav1.ConditionFalse,
+			expectProgressing: metav1.Condition{Status: metav1.ConditionTrue, Reason: "CertGenPending"},
+			expectDegraded:    metav1.Condition{Status: metav1.ConditionTrue, Reason: "RBACFailed"},
+		},
+		{
+			description: "invalid contour, namespace and rbac not ensured",
+			invalid:     fmt.Errorf("nodeport 30080 is in use"),
+			notEnsured:  true,
+			expectReasons: map[string]string{
+				operatorv1alpha1.NamespaceComponent: "Skipped",
+				operatorv1alpha1.RBACComponent:      "Skipped",
+				operatorv1alpha1.ContourComponent:   "ContourProgressing",
+			},
+			expectProgressing: metav1.Condition{Status: metav1.ConditionTrue, Reason: "Skipped"},
+			expectDegraded:    metav1.Condition{Status: metav1.ConditionTrue, Reason: "ValidationFailed"},
+		},
+		{
+			description:    "certificates issued by the operator",
+			deploy:         availableDeploy,
+			envoy:          availableEnvoy,
+			svc:            provisionedSvc,
+			xdsTLSProvider: operatorv1alpha1.OperatorXDSTLSProvider,
+			certsIssued:    true,
+			expectReasons: map[string]string{
+				operatorv1alpha1.CertGenComponent: "Issued",
+			},
+			expectProgressing: metav1.Condition{Status: metav1.ConditionFalse, Reason: "AsExpected"},
+			expectDegraded:    metav1.Condition{Status: metav1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			description:    "certificates not yet issued by the operator",
+			deploy:         availableDeploy,
+			envoy:          availableEnvoy,
+			job:            completedJob,
+			svc:            provisionedSvc,
+			xdsTLSProvider: operatorv1alpha1.OperatorXDSTLSProvider,
+			expectReasons: map[string]string{
+				operatorv1alpha1.CertGenComponent: "CertificatesPending",
+			},
+			expectProgressing: metav1.Condition{Status: metav1.ConditionTrue, Reason: "CertificatesPending"},
+			expectDegraded:    metav1.Condition{Status: metav1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			description:    "cert-manager certificates ready",
+			deploy:         availableDeploy,
+			envoy:          availableEnvoy,
+			svc:            provisionedSvc,
+			xdsTLSProvider: operatorv1alpha1.CertManagerXDSTLSProvider,
+			certs:          []*unstructured.Unstructured{certificate(contourCerts, "True"), certificate(envoyCerts, "True")},
+			expectReasons: map[string]string{
+				operatorv1alpha1.CertGenComponent: "Issued",
+			},
+			expectProgressing: metav1.Condition{Status: metav1.ConditionFalse, Reason: "AsExpected"},
+			expectDegraded:    metav1.Condition{Status: metav1.ConditionFalse, Reason: "AsExpected"},
+		},
+		{
+			description:    "cert-manager certificate not ready",
+			deploy:         availableDeploy,
+			envoy:          availableEnvoy,
+			svc:            provisionedSvc,
+			xdsTLSProvider: operatorv1alpha1.CertManagerXDSTLSProvider,
+			certs:          []*unstructured.Unstructured{certificate(contourCerts, "True"), certificate(envoyCerts, "False")},
+			expectReasons: map[string]string{
+				operatorv1alpha1.CertGenComponent: "CertificatesPending",
+			},
+			expectProgressing: metav1.Condition{Status: metav1.ConditionTrue, Reason: "CertificatesPending"},
+			expectDegraded:    metav1.Condition{Status: metav1.ConditionFalse, Reason: "AsExpected"},
+		},
+	}
+
+	for _, tc := range testCases {
+		cntr := &operatorv1alpha1.Contour{}
+		cntr.Spec.Namespace.Name = "projectcontour"
+		cntr.Spec.NetworkPublishing.Envoy.Type = operatorv1alpha1.LoadBalancerServicePublishingType
+		cntr.Spec.XDSTLS.Provider = tc.xdsTLSProvider
+		if tc.certsIssued {
+			cntr.Status.Certificates = []operatorv1alpha1.CertificateStatus{
+				{Name: objcontour.ContourCertsSecretName(cntr)},
+				{Name: objcontour.EnvoyCertsSecretName(cntr)},
+			}
+		}
+		result := &ContourResult{Invalid: tc.invalid, Errors: tc.errs}
+		if !tc.notEnsured {
+			result.SetEnsured(operatorv1alpha1.NamespaceComponent)
+			result.SetEnsured(operatorv1alpha1.RBACComponent)
+		}
+		components := computeContourComponents(cntr, result, tc.deploy, tc.envoy, operatorv1alpha1.DaemonSetEnvoyWorkloadType,
+			tc.job, tc.certs, tc.svc)
+		if len(components) != 6 {
+			t.Fatalf("%q: expected 6 components, got %d", tc.description, len(components))
+		}
+		for _, c := range components {
+			expected, ok := tc.expectReasons[c.Name]
+			if !ok {
+				continue
+			}
+			if c.Reason != expected {
+				t.Errorf("%q: expected component %s reason %q, got %q", tc.description, c.Name, expected, c.Reason)
+			}
+		}
+		if len(tc.expectCertsReady) > 0 {
+			certsReady := computeContourCertificatesReadyCondition(components)
+			if certsReady.Status != tc.expectCertsReady {
+				t.Errorf("%q: expected certificates ready status %q, got %#v", tc.description, tc.expectCertsReady, certsReady)
+			}
+		}
+		progressing := computeContourProgressingCondition(components, tc.errs)
+		if progressing.Status != tc.expectProgressing.Status || progressing.Reason != tc.expectProgressing.Reason {
+			t.Errorf("%q: expected progressing %#v, got %#v", tc.description, tc.expectProgressing, progressing)
+		}
+		degraded := computeContourDegradedCondition(tc.invalid, components, tc.errs)
+		if degraded.Status != tc.expectDegraded.Status || degraded.Reason != tc.expectDegraded.Reason {
+			t.Errorf("%q: expected degraded %#v, got %#v", tc.description, tc.expectDegraded, degraded)
+		}
+	}
+
+	degraded := computeContourDegradedCondition(fmt.Errorf("namespace projectcontour is terminating"), nil, nil)
+	if degraded.Status != metav1.ConditionTrue || degraded.Reason != "ValidationFailed" {
+		t.Errorf("expected degraded condition with reason ValidationFailed, got %#v", degraded)
+	}
+}
+
 func TestContourConditionChanged(t *testing.T) {
 	testCases := []struct {
 		description string
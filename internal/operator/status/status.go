@@ -17,25 +17,76 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	"github.com/projectcontour/contour-operator/internal/certificate"
 	"github.com/projectcontour/contour-operator/internal/equality"
+	objcertmgr "github.com/projectcontour/contour-operator/internal/objects/certmanager"
+	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
 	objds "github.com/projectcontour/contour-operator/internal/objects/daemonset"
 	objdeploy "github.com/projectcontour/contour-operator/internal/objects/deployment"
 	objgw "github.com/projectcontour/contour-operator/internal/objects/gateway"
 	objgc "github.com/projectcontour/contour-operator/internal/objects/gatewayclass"
+	objjob "github.com/projectcontour/contour-operator/internal/objects/job"
+	objsecret "github.com/projectcontour/contour-operator/internal/objects/secret"
+	objsvc "github.com/projectcontour/contour-operator/internal/objects/service"
+	opmetrics "github.com/projectcontour/contour-operator/internal/operator/metrics"
 	retryable "github.com/projectcontour/contour-operator/internal/retryableerror"
+	"github.com/projectcontour/contour-operator/pkg/validation"
 
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayv1alpha1 "sigs.k8s.io/gateway-api/apis/v1alpha1"
 )
 
+// ContourResult is the result of ensuring the resources of a contour.
+type ContourResult struct {
+	// Invalid is the error the contour failed validation with, if any.
+	Invalid error
+	// Errors maps the name of a component of the contour, e.g.
+	// operatorv1alpha1.EnvoyComponent, to the errors that occurred
+	// while ensuring the resources of the component.
+	Errors map[string][]error
+	// ensured is the set of components of the contour, e.g.
+	// operatorv1alpha1.NamespaceComponent, whose resources were ensured.
+	ensured map[string]bool
+}
+
+// NewContourResult returns an empty ContourResult.
+func NewContourResult() *ContourResult {
+	return &ContourResult{Errors: map[string][]error{}, ensured: map[string]bool{}}
+}
+
+// SetEnsured records that the resources of component were ensured. A
+// component whose resources were not ensured, e.g. of an invalid contour,
+// is reported as not ready.
+func (r *ContourResult) SetEnsured(component string) {
+	if r.ensured == nil {
+		r.ensured = map[string]bool{}
+	}
+	r.ensured[component] = true
+}
+
+// AddError records err as an error that occurred while ensuring the
+// resources of component.
+func (r *ContourResult) AddError(component string, err error) {
+	r.Errors[component] = append(r.Errors[component], err)
+}
+
 // syncContourStatus computes the current status of contour and updates status upon
-// any changes since last sync.
-func SyncContour(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
+// any changes since last sync. result is the result of validating contour and
+// ensuring its resources, used to compute the Valid, Progressing and Degraded
+// conditions and the status of the components of contour, or nil if contour
+// was not reconciled.
+func SyncContour(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, result *ContourResult) error {
 	var err error
 	var errs []error
 	var gcExists, admitted bool
@@ -54,6 +105,7 @@ func SyncContour(ctx context.Context, cli client.Client, contour *operatorv1alph
 	}
 
 	updated := latest.DeepCopy()
+	updated.Status.ObservedGeneration = contour.Generation
 
 	set := latest.GatewayClassSet()
 	if set {
@@ -68,31 +120,96 @@ func SyncContour(ctx context.Context, cli client.Client, contour *operatorv1alph
 			errs = append(errs, fmt.Errorf("failed to verify if gatewayclass %s is admitted: %w", gcRef, err))
 		}
 	}
+	// Workloads that do not exist, e.g. of an invalid contour, are absent
+	// rather than an error.
 	deploy, err := objdeploy.CurrentDeployment(ctx, cli, latest)
-	if err != nil {
-		errs = append(errs, fmt.Errorf("failed to get deployment for contour %s/%s status: %w", latest.Namespace, latest.Name, err))
-	} else {
+	switch {
+	case err == nil:
 		updated.Status.AvailableContours = deploy.Status.AvailableReplicas
+		updated.Status.ContourImage = containerImage(deploy.Spec.Template.Spec, objdeploy.ContourContainerName)
+	case errors.IsNotFound(err):
+		updated.Status.AvailableContours = 0
+	default:
+		errs = append(errs, fmt.Errorf("failed to get deployment for contour %s/%s status: %w", latest.Namespace, latest.Name, err))
 	}
-	ds, err := objds.CurrentDaemonSet(ctx, cli, latest)
+	var envoy client.Object
+	workloadType := latest.Spec.Envoy.WorkloadType
+	switch workloadType {
+	case operatorv1alpha1.DeploymentEnvoyWorkloadType:
+		envoyDeploy, err := objdeploy.CurrentEnvoyDeployment(ctx, cli, latest)
+		switch {
+		case err == nil:
+			updated.Status.AvailableEnvoys = envoyDeploy.Status.AvailableReplicas
+			updated.Status.EnvoyImage = containerImage(envoyDeploy.Spec.Template.Spec, objds.EnvoyContainerName)
+			envoy = envoyDeploy
+		case errors.IsNotFound(err):
+			updated.Status.AvailableEnvoys = 0
+		default:
+			errs = append(errs, fmt.Errorf("failed to get envoy deployment for contour %s/%s status: %w", latest.Namespace, latest.Name, err))
+		}
+	default:
+		workloadType = operatorv1alpha1.DaemonSetEnvoyWorkloadType
+		ds, err := objds.CurrentDaemonSet(ctx, cli, latest)
+		switch {
+		case err == nil:
+			updated.Status.AvailableEnvoys = ds.Status.NumberAvailable
+			updated.Status.EnvoyImage = containerImage(ds.Spec.Template.Spec, objds.EnvoyContainerName)
+			envoy = ds
+		case errors.IsNotFound(err):
+			updated.Status.AvailableEnvoys = 0
+		default:
+			errs = append(errs, fmt.Errorf("failed to get daemonset for contour %s/%s status: %w", latest.Namespace, latest.Name, err))
+		}
+	}
+
+	available := computeContourAvailableCondition(deploy, envoy, workloadType, set, gcExists, admitted)
+	updated.Status.Conditions = mergeConditions(updated.Status.Conditions, available)
+	if result != nil {
+		updated.Status.Conditions = mergeConditions(updated.Status.Conditions, computeContourValidCondition(result.Invalid))
+	}
+	certs, err := certificateStatuses(ctx, cli, latest)
 	if err != nil {
-		errs = append(errs, fmt.Errorf("failed to get daemonset for contour %s/%s status: %w", latest.Namespace, latest.Name, err))
+		errs = append(errs, fmt.Errorf("failed to get certificates for contour %s/%s status: %w", latest.Namespace,
+			latest.Name, err))
 	} else {
-		updated.Status.AvailableEnvoys = ds.Status.NumberAvailable
+		updated.Status.Certificates = certs
+	}
+	if !set {
+		if err := syncAppliedNamespace(ctx, cli, updated, available.Status == metav1.ConditionTrue); err != nil {
+			errs = append(errs, err)
+		}
+		svc, err := objsvc.CurrentEnvoyService(ctx, cli, latest)
+		switch {
+		case err == nil:
+			updated.Status.Addresses = envoyAddresses(svc)
+			updated.Status.NodePorts = envoyNodePorts(svc)
+		case errors.IsNotFound(err):
+			updated.Status.Addresses = nil
+			updated.Status.NodePorts = nil
+		default:
+			errs = append(errs, fmt.Errorf("failed to get envoy service for contour %s/%s status: %w", latest.Namespace,
+				latest.Name, err))
+		}
+		if result != nil {
+			if err := syncComponents(ctx, cli, updated, deploy, envoy, workloadType, svc, result); err != nil {
+				errs = append(errs, err)
+			}
+		}
 	}
-
-	updated.Status.Conditions = mergeConditions(updated.Status.Conditions,
-		computeContourAvailableCondition(deploy, ds, set, gcExists, admitted))
 
 	if equality.ContourStatusChanged(latest.Status, updated.Status) {
-		if err := cli.Status().Update(ctx, updated); err != nil {
+		if err := cli.Status().Update(ctx, updated); err == nil {
+			if d, ok := timeToAvailable(latest, updated); ok {
+				opmetrics.ObserveTimeToAvailable(d)
+			}
+		} else {
 			switch {
 			case errors.IsNotFound(err):
 				// The contour may have been deleted during status sync.
 				return retryable.NewMaybeRetryableAggregate(errs)
 			case strings.Contains(err.Error(), "the object has been modified"):
 				// Retry if the object was modified during status sync.
-				if err := SyncContour(ctx, cli, updated); err != nil {
+				if err := SyncContour(ctx, cli, updated, result); err != nil {
 					errs = append(errs, fmt.Errorf("failed to update contour %s/%s status: %w", latest.Namespace,
 						latest.Name, err))
 				}
@@ -106,6 +223,206 @@ func SyncContour(ctx context.Context, cli client.Client, contour *operatorv1alph
 	return retryable.NewMaybeRetryableAggregate(errs)
 }
 
+// timeToAvailable returns the time taken by a contour to become available if
+// its Available condition transitioned to True from latest to updated, measured
+// from the last transition of the condition in latest or, if latest has no
+// Available condition, from the creation of the contour.
+func timeToAvailable(latest, updated *operatorv1alpha1.Contour) (time.Duration, bool) {
+	before := meta.FindStatusCondition(latest.Status.Conditions, operatorv1alpha1.ContourAvailableConditionType)
+	after := meta.FindStatusCondition(updated.Status.Conditions, operatorv1alpha1.ContourAvailableConditionType)
+	if after == nil || after.Status != metav1.ConditionTrue {
+		return 0, false
+	}
+	since := latest.CreationTimestamp
+	if before != nil {
+		if before.Status == metav1.ConditionTrue {
+			return 0, false
+		}
+		since = before.LastTransitionTime
+	}
+	return after.LastTransitionTime.Sub(since.Time), true
+}
+
+// syncAppliedNamespace sets status.appliedNamespace of contour to
+// spec.namespace.name once contour is available in spec.namespace.name and
+// its resources were removed from the previously applied namespace, then
+// sets the NamespaceMigrated condition of contour.
+func syncAppliedNamespace(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, available bool) error {
+	applied := contour.Status.AppliedNamespace
+	target := contour.Spec.Namespace.Name
+	if available && applied != target {
+		if len(applied) == 0 {
+			applied = target
+		} else {
+			_, err := objdeploy.CurrentDeployment(ctx, cli, objcontour.InNamespace(contour, applied))
+			switch {
+			case errors.IsNotFound(err):
+				applied = target
+			case err != nil:
+				return fmt.Errorf("failed to get deployment for contour %s/%s in namespace %s: %w",
+					contour.Namespace, contour.Name, applied, err)
+			}
+		}
+	}
+	if len(applied) == 0 {
+		// The contour has not been available yet.
+		return nil
+	}
+	contour.Status.AppliedNamespace = applied
+	blocked := validation.NamespaceMigration(ctx, cli, contour)
+	if blocked != nil && !validation.IsInvalid(blocked) {
+		return blocked
+	}
+	contour.Status.Conditions = mergeConditions(contour.Status.Conditions,
+		computeContourNamespaceMigratedCondition(applied, target, blocked))
+	return nil
+}
+
+// syncComponents sets the status of the components and the CertificatesReady,
+// Progressing and Degraded conditions of contour based on result and the current deployment,
+// envoy and Envoy svc of contour, each nil if it does not exist.
+func syncComponents(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour, deploy *appsv1.Deployment,
+	envoy client.Object, workloadType operatorv1alpha1.EnvoyWorkloadType, svc *corev1.Service, result *ContourResult) error {
+	var job *batchv1.Job
+	current, err := objjob.CurrentJob(ctx, cli, contour)
+	switch {
+	case err == nil:
+		job = current
+	case !errors.IsNotFound(err):
+		return fmt.Errorf("failed to get job for contour %s/%s status: %w", contour.Namespace, contour.Name, err)
+	}
+	var certs []*unstructured.Unstructured
+	if contour.Spec.XDSTLS.Provider == operatorv1alpha1.CertManagerXDSTLSProvider {
+		certs, err = objcertmgr.CurrentCertificates(ctx, cli, contour)
+		if err != nil {
+			return fmt.Errorf("failed to get certificates for contour %s/%s status: %w", contour.Namespace, contour.Name, err)
+		}
+	}
+
+	components := computeContourComponents(contour, result, deploy, envoy, workloadType, job, certs, svc)
+	contour.Status.Components = components
+	contour.Status.Conditions = mergeConditions(contour.Status.Conditions,
+		computeContourCertificatesReadyCondition(components),
+		computeContourProgressingCondition(components, result.Errors),
+		computeContourDegradedCondition(result.Invalid, components, result.Errors))
+	return nil
+}
+
+// ContourAvailable returns true if the Contour Deployment and the Envoy
+// DaemonSet or Deployment of contour in spec.namespace.name have minimum
+// availability.
+func ContourAvailable(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) (bool, error) {
+	deploy, err := objdeploy.CurrentDeployment(ctx, cli, contour)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get deployment for contour %s/%s: %w", contour.Namespace, contour.Name, err)
+	}
+	var envoy client.Object
+	workloadType := contour.Spec.Envoy.WorkloadType
+	switch workloadType {
+	case operatorv1alpha1.DeploymentEnvoyWorkloadType:
+		envoyDeploy, err := objdeploy.CurrentEnvoyDeployment(ctx, cli, contour)
+		if err != nil && !errors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to get envoy deployment for contour %s/%s: %w", contour.Namespace, contour.Name, err)
+		}
+		if envoyDeploy != nil {
+			envoy = envoyDeploy
+		}
+	default:
+		workloadType = operatorv1alpha1.DaemonSetEnvoyWorkloadType
+		ds, err := objds.CurrentDaemonSet(ctx, cli, contour)
+		if err != nil && !errors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to get daemonset for contour %s/%s: %w", contour.Namespace, contour.Name, err)
+		}
+		if ds != nil {
+			envoy = ds
+		}
+	}
+	cond := computeContourAvailableCondition(deploy, envoy, workloadType, false, false, false)
+	return cond.Status == metav1.ConditionTrue, nil
+}
+
+// envoyAddresses returns the addresses Envoy is reachable at through svc, the
+// Envoy service. The addresses are the ingress points of the load balancer of
+// a LoadBalancer service, otherwise the cluster IPs of svc.
+func envoyAddresses(svc *corev1.Service) []operatorv1alpha1.Address {
+	var addrs []operatorv1alpha1.Address
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			if len(ingress.IP) > 0 {
+				addrs = append(addrs, operatorv1alpha1.Address{Type: operatorv1alpha1.IPAddressType, Value: ingress.IP})
+			}
+			if len(ingress.Hostname) > 0 {
+				addrs = append(addrs, operatorv1alpha1.Address{Type: operatorv1alpha1.HostnameAddressType, Value: ingress.Hostname})
+			}
+		}
+		return addrs
+	}
+	ips := svc.Spec.ClusterIPs
+	if len(ips) == 0 && len(svc.Spec.ClusterIP) > 0 {
+		ips = []string{svc.Spec.ClusterIP}
+	}
+	for _, ip := range ips {
+		if ip != corev1.ClusterIPNone {
+			addrs = append(addrs, operatorv1alpha1.Address{Type: operatorv1alpha1.IPAddressType, Value: ip})
+		}
+	}
+	return addrs
+}
+
+// envoyNodePorts returns the ports allocated on each node for the ports of
+// svc, the Envoy service.
+func envoyNodePorts(svc *corev1.Service) []operatorv1alpha1.NodePortStatus {
+	var ports []operatorv1alpha1.NodePortStatus
+	for _, p := range svc.Spec.Ports {
+		if p.NodePort != 0 {
+			ports = append(ports, operatorv1alpha1.NodePortStatus{Name: p.Name, PortNumber: p.NodePort})
+		}
+	}
+	return ports
+}
+
+// certificateStatuses returns the status of the xDS TLS certificates in the
+// secrets of contour. Secrets that do not exist or do not contain a valid
+// certificate are skipped.
+func certificateStatuses(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) ([]operatorv1alpha1.CertificateStatus, error) {
+	var certs []operatorv1alpha1.CertificateStatus
+	ns := contour.Spec.Namespace.Name
+	names := []string{
+		objcontour.CACertsSecretName(contour),
+		objcontour.ContourXDSSecretName(contour),
+		objcontour.EnvoyXDSSecretName(contour),
+	}
+	for _, name := range names {
+		secret, err := objsecret.CurrentSecret(ctx, cli, ns, name)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get secret %s/%s: %w", ns, name, err)
+		}
+		cert, err := certificate.ParseCert(secret.Data[corev1.TLSCertKey])
+		if err != nil {
+			continue
+		}
+		certs = append(certs, operatorv1alpha1.CertificateStatus{Name: name, NotAfter: metav1.NewTime(cert.NotAfter)})
+	}
+	return certs, nil
+}
+
+// containerImage returns the image of the container named name in spec,
+// or an empty string if the container does not exist.
+func containerImage(spec corev1.PodSpec, name string) string {
+	for _, c := range spec.Containers {
+		if c.Name == name {
+			return c.Image
+		}
+	}
+	return ""
+}
+
 // SyncGatewayClass computes the current status of gc and updates status upon
 // any changes since last sync.
 func SyncGatewayClass(ctx context.Context, cli client.Client, gc *gatewayv1alpha1.GatewayClass, owned, valid bool) error {
@@ -0,0 +1,224 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	"github.com/projectcontour/contour-operator/internal/certificate"
+	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEnvoyAddresses(t *testing.T) {
+	testCases := []struct {
+		description string
+		svc         *corev1.Service
+		expected    []operatorv1alpha1.Address
+	}{
+		{
+			description: "load balancer not provisioned",
+			svc: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Type:       corev1.ServiceTypeLoadBalancer,
+					ClusterIPs: []string{"10.0.0.10"},
+				},
+			},
+		},
+		{
+			description: "load balancer provisioned",
+			svc: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Type:       corev1.ServiceTypeLoadBalancer,
+					ClusterIPs: []string{"10.0.0.10"},
+				},
+				Status: corev1.ServiceStatus{
+					LoadBalancer: corev1.LoadBalancerStatus{
+						Ingress: []corev1.LoadBalancerIngress{
+							{IP: "1.2.3.4"},
+							{Hostname: "lb.example.com"},
+						},
+					},
+				},
+			},
+			expected: []operatorv1alpha1.Address{
+				{Type: operatorv1alpha1.IPAddressType, Value: "1.2.3.4"},
+				{Type: operatorv1alpha1.HostnameAddressType, Value: "lb.example.com"},
+			},
+		},
+		{
+			description: "dual-stack cluster IPs",
+			svc: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Type:       corev1.ServiceTypeNodePort,
+					ClusterIP:  "10.0.0.10",
+					ClusterIPs: []string{"10.0.0.10", "fd00::10"},
+				},
+			},
+			expected: []operatorv1alpha1.Address{
+				{Type: operatorv1alpha1.IPAddressType, Value: "10.0.0.10"},
+				{Type: operatorv1alpha1.IPAddressType, Value: "fd00::10"},
+			},
+		},
+		{
+			description: "headless service",
+			svc: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Type:      corev1.ServiceTypeClusterIP,
+					ClusterIP: corev1.ClusterIPNone,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		actual := envoyAddresses(tc.svc)
+		if !apiequality.Semantic.DeepEqual(actual, tc.expected) {
+			t.Errorf("%q: expected addresses %v, got %v", tc.description, tc.expected, actual)
+		}
+	}
+}
+
+func TestEnvoyNodePorts(t *testing.T) {
+	svc := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeNodePort,
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: int32(80), NodePort: int32(30080)},
+				{Name: "https", Port: int32(443), NodePort: int32(30443)},
+			},
+		},
+	}
+	expected := []operatorv1alpha1.NodePortStatus{
+		{Name: "http", PortNumber: int32(30080)},
+		{Name: "https", PortNumber: int32(30443)},
+	}
+	if actual := envoyNodePorts(svc); !apiequality.Semantic.DeepEqual(actual, expected) {
+		t.Errorf("expected node ports %v, got %v", expected, actual)
+	}
+
+	svc.Spec.Type = corev1.ServiceTypeClusterIP
+	for i := range svc.Spec.Ports {
+		svc.Spec.Ports[i].NodePort = 0
+	}
+	if actual := envoyNodePorts(svc); len(actual) != 0 {
+		t.Errorf("expected no node ports, got %v", actual)
+	}
+}
+
+func TestTimeToAvailable(t *testing.T) {
+	created := metav1.NewTime(time.Unix(1000, 0))
+	contour := func(status metav1.ConditionStatus, transition int64) *operatorv1alpha1.Contour {
+		cntr := &operatorv1alpha1.Contour{
+			ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created},
+		}
+		if len(status) > 0 {
+			cntr.Status.Conditions = []metav1.Condition{{
+				Type:               operatorv1alpha1.ContourAvailableConditionType,
+				Status:             status,
+				LastTransitionTime: metav1.NewTime(time.Unix(transition, 0)),
+			}}
+		}
+		return cntr
+	}
+
+	testCases := []struct {
+		description string
+		latest      *operatorv1alpha1.Contour
+		updated     *operatorv1alpha1.Contour
+		expected    time.Duration
+		expectOK    bool
+	}{
+		{
+			description: "new contour becomes available",
+			latest:      contour("", 0),
+			updated:     contour(metav1.ConditionTrue, 1030),
+			expected:    30 * time.Second,
+			expectOK:    true,
+		},
+		{
+			description: "unavailable contour becomes available",
+			latest:      contour(metav1.ConditionFalse, 2000),
+			updated:     contour(metav1.ConditionTrue, 2045),
+			expected:    45 * time.Second,
+			expectOK:    true,
+		},
+		{
+			description: "available contour stays available",
+			latest:      contour(metav1.ConditionTrue, 1030),
+			updated:     contour(metav1.ConditionTrue, 1030),
+		},
+		{
+			description: "contour becomes unavailable",
+			latest:      contour(metav1.ConditionTrue, 1030),
+			updated:     contour(metav1.ConditionFalse, 2000),
+		},
+	}
+
+	for _, tc := range testCases {
+		d, ok := timeToAvailable(tc.latest, tc.updated)
+		if ok != tc.expectOK || d != tc.expected {
+			t.Errorf("%q: expected %v, %t, got %v, %t", tc.description, tc.expected, tc.expectOK, d, ok)
+		}
+	}
+}
+
+func TestCertificateStatuses(t *testing.T) {
+	ctx := context.Background()
+	cntr := objcontour.New(objcontour.Config{
+		Name:        "test-contour",
+		Namespace:   "test-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	now := time.Now().UTC().Truncate(time.Second)
+	ca, err := certificate.NewCA("test-ca", now, 2*time.Hour)
+	if err != nil {
+		t.Fatalf("failed to issue ca: %v", err)
+	}
+	cert, err := certificate.NewCert(ca, "contour", nil, now, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to issue certificate: %v", err)
+	}
+	secret := func(name string, certPEM []byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: cntr.Spec.Namespace.Name, Name: name},
+			Data:       map[string][]byte{corev1.TLSCertKey: certPEM},
+		}
+	}
+	// The CA secret does not exist and the Envoy secret does not contain a
+	// valid certificate.
+	cli := fake.NewClientBuilder().WithObjects(
+		secret(objcontour.ContourCertsSecretName(cntr), cert.CertPEM()),
+		secret(objcontour.EnvoyCertsSecretName(cntr), []byte("invalid")),
+	).Build()
+
+	expected := []operatorv1alpha1.CertificateStatus{
+		{Name: objcontour.ContourCertsSecretName(cntr), NotAfter: metav1.NewTime(now.Add(time.Hour))},
+	}
+	certs, err := certificateStatuses(ctx, cli, cntr)
+	if err != nil {
+		t.Fatalf("failed to get certificate statuses: %v", err)
+	}
+	if !apiequality.Semantic.DeepEqual(certs, expected) {
+		t.Errorf("expected certificates %+v, got %+v", expected, certs)
+	}
+}
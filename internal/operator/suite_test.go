@@ -15,17 +15,23 @@ package operator
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	operatorv1beta1 "github.com/projectcontour/contour-operator/api/v1beta1"
 	operatorconfig "github.com/projectcontour/contour-operator/internal/operator/config"
+	"github.com/projectcontour/contour-operator/internal/operator/migration"
 	"github.com/projectcontour/contour-operator/pkg/slice"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
@@ -105,8 +111,12 @@ var _ = BeforeSuite(func(done Done) {
 	opCRD := filepath.Join("..", "..", "config", "crd", "bases")
 	contourCRDs := filepath.Join("..", "..", "config", "crd", "contour")
 	gatewayCRDs := filepath.Join("..", "..", "config", "crd", "gateway")
+	webhooks := filepath.Join("..", "..", "config", "webhook")
 	testEnv = &envtest.Environment{
 		CRDDirectoryPaths: []string{opCRD, contourCRDs, gatewayCRDs},
+		WebhookInstallOptions: envtest.WebhookInstallOptions{
+			Paths: []string{webhooks},
+		},
 	}
 
 	cliCfg, err := testEnv.Start()
@@ -114,8 +124,15 @@ var _ = BeforeSuite(func(done Done) {
 	Expect(cliCfg).ToNot(BeNil())
 
 	opCfg := operatorconfig.New()
+	opCfg.EnableWebhooks = true
+	opCfg.WebhookPort = testEnv.WebhookInstallOptions.LocalServingPort
+	opCfg.WebhookCertDir = testEnv.WebhookInstallOptions.LocalServingCertDir
 	operator, err = New(cliCfg, opCfg)
 	Expect(err).ToNot(HaveOccurred())
+
+	By("Enabling the conversion webhook of the contour CRD")
+	Expect(enableConversionWebhook()).To(Succeed())
+
 	go func() {
 		err = operator.Start(ctrl.SetupSignalHandler(), opCfg)
 		Expect(err).ToNot(HaveOccurred())
@@ -128,6 +145,29 @@ var _ = BeforeSuite(func(done Done) {
 	close(done)
 }, 60)
 
+// enableConversionWebhook configures the Contour CRD to convert objects
+// using the webhook server of the test environment, since envtest installs
+// the CRD without the conversion webhook patch.
+func enableConversionWebhook() error {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := operator.client.Get(ctx, types.NamespacedName{Name: migration.ContourCRDName}, crd); err != nil {
+		return err
+	}
+	opts := testEnv.WebhookInstallOptions
+	url := fmt.Sprintf("https://%s/convert", net.JoinHostPort(opts.LocalServingHost, strconv.Itoa(opts.LocalServingPort)))
+	crd.Spec.Conversion = &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ClientConfig: &apiextensionsv1.WebhookClientConfig{
+				URL:      &url,
+				CABundle: opts.LocalServingCAData,
+			},
+			ConversionReviewVersions: []string{"v1", "v1beta1"},
+		},
+	}
+	return operator.client.Update(ctx, crd)
+}
+
 var _ = Describe("Run controller", func() {
 	Context("When creating a contour", func() {
 		It("Should set default fields", func() {
@@ -319,6 +359,169 @@ var _ = Describe("Run controller", func() {
 				return operator.client.Get(ctx, key, f)
 			}, timeout, interval).ShouldNot(Succeed())
 		})
+		It("Should be denied when invalid", func() {
+			invalidSuffix := "-invalid"
+
+			By("By creating a contour with duplicate container ports")
+			invalid := &operatorv1alpha1.Contour{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: cntr.Namespace,
+					Name:      cntr.Name + invalidSuffix,
+				},
+				Spec: operatorv1alpha1.ContourSpec{
+					Namespace: operatorv1alpha1.NamespaceSpec{
+						Name: defaultNamespace + invalidSuffix,
+					},
+					NetworkPublishing: operatorv1alpha1.NetworkPublishing{
+						Envoy: operatorv1alpha1.EnvoyNetworkPublishing{
+							Type: operatorv1alpha1.LoadBalancerServicePublishingType,
+							ContainerPorts: []operatorv1alpha1.ContainerPort{
+								{Name: "http", PortNumber: int32(8080)},
+								{Name: "https", PortNumber: int32(8080)},
+							},
+						},
+					},
+				},
+			}
+			err := operator.client.Create(ctx, invalid)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("denied the request"))
+
+			By("Expecting the contour to not exist")
+			key := types.NamespacedName{Namespace: invalid.Namespace, Name: invalid.Name}
+			Expect(operator.client.Get(ctx, key, &operatorv1alpha1.Contour{})).ShouldNot(Succeed())
+		})
+		It("Should allow an invalid contour to be deleted", func() {
+			deletingSuffix := "-deleting"
+			testFinalizer := "operator.projectcontour.io/test-finalizer"
+
+			key := types.NamespacedName{
+				Name:      cntr.Name + deletingSuffix,
+				Namespace: cntr.Namespace,
+			}
+
+			By("By creating a contour with a finalizer that blocks its deletion")
+			created := &operatorv1alpha1.Contour{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:  key.Namespace,
+					Name:       key.Name,
+					Finalizers: []string{testFinalizer},
+				},
+				Spec: operatorv1alpha1.ContourSpec{
+					Namespace: operatorv1alpha1.NamespaceSpec{
+						Name: defaultNamespace + deletingSuffix,
+					},
+				},
+			}
+			Expect(operator.client.Create(ctx, created)).Should(Succeed())
+
+			By("Expecting the contour finalizer")
+			Eventually(func() []string {
+				f := &operatorv1alpha1.Contour{}
+				Expect(operator.client.Get(ctx, key, f)).Should(Succeed())
+				return f.Finalizers
+			}, timeout, interval).Should(ContainElement(finalizer))
+
+			By("Expecting to delete contour successfully")
+			Expect(operator.client.Delete(ctx, created)).Should(Succeed())
+
+			By("Expecting the contour to be finalized")
+			Eventually(func() []string {
+				f := &operatorv1alpha1.Contour{}
+				Expect(operator.client.Get(ctx, key, f)).Should(Succeed())
+				return f.Finalizers
+			}, timeout, interval).ShouldNot(ContainElement(finalizer))
+
+			By("Expecting to update the deleting contour with duplicate container ports")
+			Eventually(func() error {
+				updated := &operatorv1alpha1.Contour{}
+				Expect(operator.client.Get(ctx, key, updated)).Should(Succeed())
+				updated.Spec.NetworkPublishing.Envoy.ContainerPorts = []operatorv1alpha1.ContainerPort{
+					{Name: "http", PortNumber: int32(8080)},
+					{Name: "https", PortNumber: int32(8080)},
+				}
+				return operator.client.Update(ctx, updated)
+			}, timeout, interval).Should(Succeed())
+
+			By("Expecting to remove the finalizer of the invalid contour")
+			Eventually(func() error {
+				updated := &operatorv1alpha1.Contour{}
+				Expect(operator.client.Get(ctx, key, updated)).Should(Succeed())
+				updated.Finalizers = slice.RemoveString(updated.Finalizers, testFinalizer)
+				return operator.client.Update(ctx, updated)
+			}, timeout, interval).Should(Succeed())
+
+			By("Expecting contour deletion to finish")
+			Eventually(func() error {
+				f := &operatorv1alpha1.Contour{}
+				return operator.client.Get(ctx, key, f)
+			}, timeout, interval).ShouldNot(Succeed())
+		})
+	})
+
+	Context("When creating a v1beta1 contour", func() {
+		It("Should be converted to v1alpha1", func() {
+			v1beta1Suffix := "-v1beta1"
+
+			By("By creating a v1beta1 contour with custom ports")
+			c := &operatorv1beta1.Contour{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: testOperatorNs,
+					Name:      testContourName + v1beta1Suffix,
+				},
+				Spec: operatorv1beta1.ContourSpec{
+					Namespace: operatorv1beta1.NamespaceSpec{
+						Name: defaultNamespace + v1beta1Suffix,
+					},
+					NetworkPublishing: operatorv1beta1.NetworkPublishing{
+						Envoy: operatorv1beta1.EnvoyNetworkPublishing{
+							Type: operatorv1beta1.NodePortServicePublishingType,
+							Ports: operatorv1beta1.EnvoyPorts{
+								HTTP:  operatorv1beta1.EnvoyPort{ContainerPort: int32(8081), NodePort: pointer.Int32Ptr(int32(30081))},
+								HTTPS: operatorv1beta1.EnvoyPort{ContainerPort: int32(8444), NodePort: pointer.Int32Ptr(int32(30444))},
+							},
+						},
+					},
+				},
+			}
+			Expect(operator.client.Create(ctx, c)).Should(Succeed())
+
+			By("Expecting the v1alpha1 contour to have the ports")
+			key := types.NamespacedName{Namespace: c.Namespace, Name: c.Name}
+			converted := &operatorv1alpha1.Contour{}
+			Expect(operator.client.Get(ctx, key, converted)).Should(Succeed())
+			Expect(converted.Spec.NetworkPublishing.Envoy.ContainerPorts).To(Equal([]operatorv1alpha1.ContainerPort{
+				{Name: "http", PortNumber: int32(8081)},
+				{Name: "https", PortNumber: int32(8444)},
+			}))
+			Expect(converted.Spec.NetworkPublishing.Envoy.NodePorts).To(Equal([]operatorv1alpha1.NodePort{
+				{Name: "http", PortNumber: pointer.Int32Ptr(int32(30081))},
+				{Name: "https", PortNumber: pointer.Int32Ptr(int32(30444))},
+			}))
+
+			By("Deleting the contour")
+			Expect(operator.client.Delete(ctx, converted)).Should(Succeed())
+			Eventually(func() error {
+				return operator.client.Get(ctx, key, &operatorv1alpha1.Contour{})
+			}, timeout, interval).ShouldNot(Succeed())
+		})
+	})
+
+	Context("When creating a gatewayclass", func() {
+		It("Should be denied when invalid", func() {
+			By("By creating a gatewayclass without a parametersRef")
+			invalid := &gatewayv1alpha1.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testGatewayClassName + "-invalid",
+				},
+				Spec: gatewayv1alpha1.GatewayClassSpec{
+					Controller: operatorv1alpha1.GatewayClassControllerRef,
+				},
+			}
+			err := operator.client.Create(ctx, invalid)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("denied the request"))
+		})
 	})
 })
 
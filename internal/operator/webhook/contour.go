@@ -0,0 +1,62 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	"github.com/projectcontour/contour-operator/pkg/validation"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/validate-operator-projectcontour-io-v1alpha1-contour,mutating=false,failurePolicy=fail,sideEffects=None,groups=operator.projectcontour.io,resources=contours,verbs=create;update,versions=v1alpha1,name=vcontour.operator.projectcontour.io,admissionReviewVersions={v1,v1beta1}
+
+// contourValidator validates Contour objects.
+type contourValidator struct {
+	client  client.Client
+	decoder *admission.Decoder
+	log     logr.Logger
+}
+
+// Handle denies the request if the Contour of req is invalid. Requests for a
+// Contour that is being deleted are allowed, so the finalizer of a Contour
+// that became invalid, e.g. due to a conflicting node port, can be removed.
+func (v *contourValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	contour := &operatorv1alpha1.Contour{}
+	if err := v.decoder.Decode(req, contour); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if contour.DeletionTimestamp != nil {
+		return admission.Allowed("")
+	}
+	if err := validation.Contour(ctx, v.client, contour); err != nil {
+		if !validation.IsInvalid(err) {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		v.log.Info("denied contour", "namespace", contour.Namespace, "name", contour.Name, "error", err.Error())
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// InjectDecoder injects the decoder into v.
+func (v *contourValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
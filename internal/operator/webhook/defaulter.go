@@ -0,0 +1,53 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/mutate-operator-projectcontour-io-v1alpha1-contour,mutating=true,failurePolicy=fail,sideEffects=None,groups=operator.projectcontour.io,resources=contours,verbs=create;update,versions=v1alpha1,name=mcontour.operator.projectcontour.io,admissionReviewVersions={v1,v1beta1}
+
+// contourDefaulter sets the defaults of Contour objects, so the stored
+// spec is the spec the operator deploys.
+type contourDefaulter struct {
+	decoder *admission.Decoder
+}
+
+// Handle patches the Contour of req with its defaulted spec.
+func (d *contourDefaulter) Handle(_ context.Context, req admission.Request) admission.Response {
+	contour := &operatorv1alpha1.Contour{}
+	if err := d.decoder.Decode(req, contour); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	objcontour.Default(contour)
+	marshaled, err := json.Marshal(contour)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// InjectDecoder injects the decoder into d.
+func (d *contourDefaulter) InjectDecoder(dec *admission.Decoder) error {
+	d.decoder = dec
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	objgc "github.com/projectcontour/contour-operator/internal/objects/gatewayclass"
+	"github.com/projectcontour/contour-operator/pkg/validation"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	gatewayv1alpha1 "sigs.k8s.io/gateway-api/apis/v1alpha1"
+)
+
+// Gateways of other controllers are sent to the webhook too, so the webhook
+// is ignored if it's unavailable.
+//
+// +kubebuilder:webhook:path=/validate-networking-x-k8s-io-v1alpha1-gateway,mutating=false,failurePolicy=ignore,sideEffects=None,groups=networking.x-k8s.io,resources=gateways,verbs=create;update,versions=v1alpha1,name=vgateway.operator.projectcontour.io,admissionReviewVersions={v1,v1beta1}
+
+// gatewayValidator validates Gateway objects.
+type gatewayValidator struct {
+	client  client.Client
+	decoder *admission.Decoder
+	log     logr.Logger
+}
+
+// Handle denies the request if the Gateway of req references a GatewayClass
+// managed by the operator and its spec is invalid. The GatewayClass and Contour
+// referenced by the Gateway may be created after the Gateway, so they are
+// validated when the Gateway is reconciled instead.
+func (v *gatewayValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	gw := &gatewayv1alpha1.Gateway{}
+	if err := v.decoder.Decode(req, gw); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	gc, err := objgc.Get(ctx, v.client, gw.Spec.GatewayClassName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return admission.Allowed("gatewayclass not found")
+		}
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if !objgc.IsController(gc) {
+		return admission.Allowed("gatewayclass is not managed by the operator")
+	}
+	if err := validation.GatewaySpec(gw); err != nil {
+		v.log.Info("denied gateway", "namespace", gw.Namespace, "name", gw.Name, "error", err.Error())
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// InjectDecoder injects the decoder into v.
+func (v *gatewayValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
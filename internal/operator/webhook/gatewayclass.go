@@ -0,0 +1,60 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	objgc "github.com/projectcontour/contour-operator/internal/objects/gatewayclass"
+	"github.com/projectcontour/contour-operator/pkg/validation"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	gatewayv1alpha1 "sigs.k8s.io/gateway-api/apis/v1alpha1"
+)
+
+// GatewayClasses of other controllers are sent to the webhook too, so the
+// webhook is ignored if it's unavailable.
+//
+// +kubebuilder:webhook:path=/validate-networking-x-k8s-io-v1alpha1-gatewayclass,mutating=false,failurePolicy=ignore,sideEffects=None,groups=networking.x-k8s.io,resources=gatewayclasses,verbs=create;update,versions=v1alpha1,name=vgatewayclass.operator.projectcontour.io,admissionReviewVersions={v1,v1beta1}
+
+// gatewayClassValidator validates GatewayClass objects.
+type gatewayClassValidator struct {
+	decoder *admission.Decoder
+	log     logr.Logger
+}
+
+// Handle denies the request if the GatewayClass of req specifies the operator
+// as the controller and is invalid.
+func (v *gatewayClassValidator) Handle(_ context.Context, req admission.Request) admission.Response {
+	gc := &gatewayv1alpha1.GatewayClass{}
+	if err := v.decoder.Decode(req, gc); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if !objgc.IsController(gc) {
+		return admission.Allowed("gatewayclass is not managed by the operator")
+	}
+	if err := validation.GatewayClass(gc); err != nil {
+		v.log.Info("denied gatewayclass", "name", gc.Name, "error", err.Error())
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// InjectDecoder injects the decoder into v.
+func (v *gatewayClassValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
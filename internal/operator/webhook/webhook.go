@@ -0,0 +1,55 @@
+// Copyright Project Contour Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+)
+
+const (
+	// ConversionPath is the path of the conversion webhook for Contour objects.
+	ConversionPath = "/convert"
+	// ContourDefaultingPath is the path of the mutating webhook for Contour objects.
+	ContourDefaultingPath = "/mutate-operator-projectcontour-io-v1alpha1-contour"
+	// ContourPath is the path of the validating webhook for Contour objects.
+	ContourPath = "/validate-operator-projectcontour-io-v1alpha1-contour"
+	// GatewayClassPath is the path of the validating webhook for GatewayClass objects.
+	GatewayClassPath = "/validate-networking-x-k8s-io-v1alpha1-gatewayclass"
+	// GatewayPath is the path of the validating webhook for Gateway objects.
+	GatewayPath = "/validate-networking-x-k8s-io-v1alpha1-gateway"
+)
+
+// Register registers the conversion and defaulting webhooks for Contour
+// objects and the validating webhooks for Contour, GatewayClass and Gateway
+// objects with the webhook server of mgr.
+func Register(mgr manager.Manager) {
+	srv := mgr.GetWebhookServer()
+	cli := mgr.GetClient()
+	srv.Register(ConversionPath, &conversion.Webhook{})
+	srv.Register(ContourDefaultingPath, &webhook.Admission{Handler: &contourDefaulter{}})
+	srv.Register(ContourPath, &webhook.Admission{Handler: &contourValidator{
+		client: cli,
+		log:    ctrl.Log.WithName("contour_webhook"),
+	}})
+	srv.Register(GatewayClassPath, &webhook.Admission{Handler: &gatewayClassValidator{
+		log: ctrl.Log.WithName("gatewayclass_webhook"),
+	}})
+	srv.Register(GatewayPath, &webhook.Admission{Handler: &gatewayValidator{
+		client: cli,
+		log:    ctrl.Log.WithName("gateway_webhook"),
+	}})
+}
@@ -16,16 +16,24 @@ package validation
 import (
 	"context"
 	"fmt"
+	"net"
+	"regexp"
 	"strings"
+	"time"
 
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
 	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
 	objgw "github.com/projectcontour/contour-operator/internal/objects/gateway"
 	objgc "github.com/projectcontour/contour-operator/internal/objects/gatewayclass"
+	"github.com/projectcontour/contour-operator/internal/parse"
 	retryable "github.com/projectcontour/contour-operator/internal/retryableerror"
 	"github.com/projectcontour/contour-operator/pkg/slice"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayv1alpha1 "sigs.k8s.io/gateway-api/apis/v1alpha1"
@@ -33,31 +41,359 @@ import (
 
 const gatewayClassNamespacedParamRef = "Namespace"
 
+// azureResourceGroupRegexp matches a valid Azure resource group name.
+var azureResourceGroupRegexp = regexp.MustCompile(`^[-\w\.\(\)]*[-\w\(\)]$`)
+
+// validCipherSuites are the TLS 1.2 cipher suites supported by Contour.
+var validCipherSuites = []string{
+	"[ECDHE-ECDSA-AES128-GCM-SHA256|ECDHE-ECDSA-CHACHA20-POLY1305]",
+	"[ECDHE-RSA-AES128-GCM-SHA256|ECDHE-RSA-CHACHA20-POLY1305]",
+	"ECDHE-ECDSA-AES128-GCM-SHA256",
+	"ECDHE-RSA-AES128-GCM-SHA256",
+	"ECDHE-ECDSA-AES128-SHA",
+	"ECDHE-RSA-AES128-SHA",
+	"AES128-GCM-SHA256",
+	"AES128-SHA",
+	"ECDHE-ECDSA-AES256-GCM-SHA384",
+	"ECDHE-RSA-AES256-GCM-SHA384",
+	"ECDHE-ECDSA-AES256-SHA",
+	"ECDHE-RSA-AES256-SHA",
+	"AES256-GCM-SHA384",
+	"AES256-SHA",
+}
+
+// invalidError is an error returned when the validated object is invalid,
+// as opposed to when the object could not be validated.
+type invalidError struct {
+	error
+}
+
+// IsInvalid returns true if err was returned by Contour or NamespaceMigration
+// because the contour is invalid. Other errors returned by these functions are
+// transient.
+func IsInvalid(err error) bool {
+	_, ok := err.(invalidError)
+	return ok
+}
+
 // Contour returns true if contour is valid.
 func Contour(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
-	// TODO [danehans]: Remove when https://github.com/projectcontour/contour-operator/issues/18 is fixed.
-	exist, err := objcontour.OtherContoursExistInSpecNs(ctx, cli, contour)
+	// Managed resources are named from the name of contour, so another Contour
+	// of the same name can not use the same spec.namespace.name.
+	exist, err := objcontour.OtherContoursWithNameExistInSpecNs(ctx, cli, contour)
 	if err != nil {
-		return fmt.Errorf("failed to verify if other contours exist in namespace %s: %w",
-			contour.Spec.Namespace.Name, err)
+		return fmt.Errorf("failed to verify if other contours named %s exist in namespace %s: %w",
+			contour.Name, contour.Spec.Namespace.Name, err)
 	}
 	if exist {
-		return fmt.Errorf("other contours exist in namespace %s", contour.Spec.Namespace.Name)
+		return invalidError{fmt.Errorf("another contour named %s exists in namespace %s", contour.Name,
+			contour.Spec.Namespace.Name)}
+	}
+
+	if err := Names(contour); err != nil {
+		return invalidError{err}
+	}
+
+	if err := Images(contour); err != nil {
+		return invalidError{err}
 	}
 
 	if err := ContainerPorts(contour); err != nil {
-		return err
+		return invalidError{err}
+	}
+
+	if err := Config(contour); err != nil {
+		return invalidError{err}
+	}
+
+	if err := EnvoyService(contour); err != nil {
+		return invalidError{err}
+	}
+
+	if err := IPFamilies(contour); err != nil {
+		return invalidError{err}
+	}
+
+	if err := XDSTLS(contour); err != nil {
+		return invalidError{err}
 	}
 
 	if contour.Spec.NetworkPublishing.Envoy.Type == operatorv1alpha1.NodePortServicePublishingType {
 		if err := NodePorts(contour); err != nil {
-			return err
+			return invalidError{err}
 		}
 	}
 
 	return nil
 }
 
+// NamespaceMigration returns nil if contour can be migrated from the namespace
+// of status.appliedNamespace to spec.namespace.name, or if no migration is
+// pending, otherwise an error describing why the migration is blocked.
+func NamespaceMigration(ctx context.Context, cli client.Client, contour *operatorv1alpha1.Contour) error {
+	applied := contour.Status.AppliedNamespace
+	target := contour.Spec.Namespace.Name
+	if len(applied) == 0 || applied == target {
+		return nil
+	}
+
+	ns := &corev1.Namespace{}
+	if err := cli.Get(ctx, types.NamespacedName{Name: target}, ns); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get namespace %s: %w", target, err)
+		}
+	} else if !ns.DeletionTimestamp.IsZero() {
+		return invalidError{fmt.Errorf("namespace %s is terminating", target)}
+	}
+
+	// Managed resources are named from the name of contour, so the resources
+	// of another Contour of the same name must be removed from the namespace
+	// first, e.g. by completing its own migration.
+	contours := &operatorv1alpha1.ContourList{}
+	if err := cli.List(ctx, contours); err != nil {
+		return fmt.Errorf("failed to list contours: %w", err)
+	}
+	for _, c := range contours.Items {
+		if c.Namespace == contour.Namespace || c.Name != contour.Name {
+			continue
+		}
+		if c.Status.AppliedNamespace == target {
+			return invalidError{fmt.Errorf("contour %s/%s still runs in namespace %s", c.Namespace, c.Name, target)}
+		}
+	}
+
+	return nil
+}
+
+// Names validates the names of the resources derived from the name of contour,
+// returning an error if the API server would reject a resource named from it.
+// The Contour and Envoy Services must be named by DNS-1035 labels, and the name
+// of the certgen Job is used as the value of the "job-name" label of its pods.
+func Names(contour *operatorv1alpha1.Contour) error {
+	for _, name := range []string{objcontour.ContourName(contour), objcontour.EnvoyName(contour)} {
+		if errs := validation.IsDNS1035Label(name); len(errs) != 0 {
+			return fmt.Errorf("invalid service name %q derived from contour name %q: %s", name, contour.Name,
+				strings.Join(errs, ", "))
+		}
+	}
+	// The hash of the image has a fixed length, so the length of the name
+	// does not depend on the image.
+	job := objcontour.CertGenJobName(contour, "")
+	if errs := validation.IsValidLabelValue(job); len(errs) != 0 {
+		return fmt.Errorf("invalid certgen job name %q derived from contour name %q: %s", job, contour.Name,
+			strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// Images validates the container image references of contour, returning
+// an error if an image reference is specified but is invalid.
+func Images(contour *operatorv1alpha1.Contour) error {
+	if image := contour.Spec.Contour.Image; len(image) > 0 {
+		if err := parse.Image(image); err != nil {
+			return fmt.Errorf("invalid contour image %q: %w", image, err)
+		}
+	}
+	if image := contour.Spec.Envoy.Image; len(image) > 0 {
+		if err := parse.Image(image); err != nil {
+			return fmt.Errorf("invalid envoy image %q: %w", image, err)
+		}
+	}
+	return nil
+}
+
+// EnvoyService validates the Envoy Service parameters of contour, returning
+// an error if the parameters do not meet the API specification.
+func EnvoyService(contour *operatorv1alpha1.Contour) error {
+	envoy := contour.Spec.NetworkPublishing.Envoy
+	for k := range envoy.ServiceAnnotations {
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return fmt.Errorf("invalid service annotation %q: %s", k, strings.Join(errs, ", "))
+		}
+		if k == operatorv1alpha1.ManagedAnnotationsAnnotation || k == operatorv1alpha1.ManagedLabelsAnnotation {
+			return fmt.Errorf("service annotation %q is reserved", k)
+		}
+	}
+	for k, v := range envoy.ServiceLabels {
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return fmt.Errorf("invalid service label %q: %s", k, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) > 0 {
+			return fmt.Errorf("invalid value %q of service label %q: %s", v, k, strings.Join(errs, ", "))
+		}
+	}
+	if envoy.Type == operatorv1alpha1.ClusterIPServicePublishingType && len(envoy.ExternalTrafficPolicy) > 0 {
+		return fmt.Errorf("external traffic policy is not supported for network publishing type %s", envoy.Type)
+	}
+	if ip := envoy.LoadBalancer.LoadBalancerIP; len(ip) > 0 && net.ParseIP(ip) == nil {
+		return fmt.Errorf("invalid load balancer IP %q", ip)
+	}
+	for _, r := range envoy.LoadBalancer.LoadBalancerSourceRanges {
+		if _, _, err := net.ParseCIDR(r); err != nil {
+			return fmt.Errorf("invalid load balancer source range %q: %w", r, err)
+		}
+	}
+	return providerParameters(&envoy.LoadBalancer)
+}
+
+// providerParameters validates the provider-specific parameters of lb,
+// returning an error if the parameters do not match the provider type or
+// the load balancer scope.
+func providerParameters(lb *operatorv1alpha1.LoadBalancerStrategy) error {
+	params := lb.ProviderParameters
+	internal := lb.Scope == operatorv1alpha1.InternalLoadBalancer
+	if params.AWS != nil && params.Type != operatorv1alpha1.AWSLoadBalancerProvider {
+		return fmt.Errorf("aws parameters are not supported for provider type %s", params.Type)
+	}
+	if azure := params.Azure; azure != nil {
+		if params.Type != operatorv1alpha1.AzureLoadBalancerProvider {
+			return fmt.Errorf("azure parameters are not supported for provider type %s", params.Type)
+		}
+		if len(azure.ResourceGroup) > 0 && !azureResourceGroupRegexp.MatchString(azure.ResourceGroup) {
+			return fmt.Errorf("invalid azure resource group %q", azure.ResourceGroup)
+		}
+		if len(azure.Subnet) > 0 && !internal {
+			return fmt.Errorf("azure subnet is only supported for internal load balancers")
+		}
+		if probe := azure.HealthProbe; probe != nil && len(probe.RequestPath) > 0 {
+			if probe.Protocol == operatorv1alpha1.AzureHealthProbeProtocolTCP {
+				return fmt.Errorf("azure health probe request path is not supported for protocol %s", probe.Protocol)
+			}
+			if !strings.HasPrefix(probe.RequestPath, "/") {
+				return fmt.Errorf("invalid azure health probe request path %q: must start with \"/\"", probe.RequestPath)
+			}
+		}
+	}
+	if gcp := params.GCP; gcp != nil {
+		if params.Type != operatorv1alpha1.GCPLoadBalancerProvider {
+			return fmt.Errorf("gcp parameters are not supported for provider type %s", params.Type)
+		}
+		if len(gcp.Subnet) > 0 && !internal {
+			return fmt.Errorf("gcp subnet is only supported for internal load balancers")
+		}
+		if gcp.GlobalAccess && !internal {
+			return fmt.Errorf("gcp global access is only supported for internal load balancers")
+		}
+		if gcp.BackendServiceBased && internal {
+			return fmt.Errorf("gcp backend service-based load balancers are only supported for external load balancers")
+		}
+	}
+	return nil
+}
+
+// IPFamilies validates the IP family policy and IP families of contour,
+// returning an error if they do not meet the API specification.
+func IPFamilies(contour *operatorv1alpha1.Contour) error {
+	families := contour.Spec.IPFamilies
+	for i, family := range families {
+		if family != corev1.IPv4Protocol && family != corev1.IPv6Protocol {
+			return fmt.Errorf("invalid ip family %q", family)
+		}
+		if i > 0 && families[0] == family {
+			return fmt.Errorf("duplicate ip family %q", family)
+		}
+	}
+	policy := contour.Spec.IPFamilyPolicy
+	if policy != nil && *policy == corev1.IPFamilyPolicySingleStack && len(families) > 1 {
+		return fmt.Errorf("ip family policy %s supports a single ip family", *policy)
+	}
+	return nil
+}
+
+// minCertificateLifetime is the minimum lifetime of the xDS TLS certificates
+// issued by the operator or cert-manager.
+const minCertificateLifetime = 24 * time.Hour
+
+// XDSTLS validates spec.xdsTLS of contour, returning an error if the xDS TLS
+// settings do not meet the API specification.
+func XDSTLS(contour *operatorv1alpha1.Contour) error {
+	xdsTLS := contour.Spec.XDSTLS
+	lifetime := xdsTLS.CertificateLifetime
+	if lifetime != nil && lifetime.Duration < minCertificateLifetime {
+		return fmt.Errorf("certificate lifetime %s is less than %s", lifetime.Duration, minCertificateLifetime)
+	}
+	if xdsTLS.Provider == operatorv1alpha1.CertManagerXDSTLSProvider && (xdsTLS.IssuerRef == nil || len(xdsTLS.IssuerRef.Name) == 0) {
+		return fmt.Errorf("provider %s requires an issuer reference", xdsTLS.Provider)
+	}
+	if refs := xdsTLS.SecretRefs; refs != nil {
+		if len(xdsTLS.Provider) > 0 && xdsTLS.Provider != operatorv1alpha1.CertGenXDSTLSProvider {
+			return fmt.Errorf("secret references are not supported by provider %s", xdsTLS.Provider)
+		}
+		if len(refs.Contour) == 0 || len(refs.Envoy) == 0 {
+			return fmt.Errorf("secret references require the secret names of both contour and envoy")
+		}
+	}
+	return nil
+}
+
+// Config validates spec.config of contour, returning an error if the
+// Contour configuration does not meet the API specification.
+func Config(contour *operatorv1alpha1.Contour) error {
+	cfg := contour.Spec.Config
+	if cfg.TLS != nil {
+		var found []string
+		for _, cipher := range cfg.TLS.CipherSuites {
+			if !slice.ContainsString(validCipherSuites, cipher) {
+				return fmt.Errorf("invalid cipher suite %q", cipher)
+			}
+			if slice.ContainsString(found, cipher) {
+				return fmt.Errorf("duplicate cipher suite %q", cipher)
+			}
+			found = append(found, cipher)
+		}
+	}
+	var names []string
+	for _, field := range cfg.JSONFields {
+		name := strings.SplitN(field, "=", 2)[0]
+		if len(name) == 0 {
+			return fmt.Errorf("invalid json field %q", field)
+		}
+		if slice.ContainsString(names, name) {
+			return fmt.Errorf("duplicate json field %q", name)
+		}
+		names = append(names, name)
+	}
+	var versions []string
+	for _, v := range cfg.DefaultHTTPVersions {
+		if slice.ContainsString(versions, string(v)) {
+			return fmt.Errorf("duplicate default http version %q", v)
+		}
+		versions = append(versions, string(v))
+	}
+	if t := cfg.Timeouts; t != nil {
+		timeouts := map[string]string{
+			"requestTimeout":                t.RequestTimeout,
+			"connectionIdleTimeout":         t.ConnectionIdleTimeout,
+			"streamIdleTimeout":             t.StreamIdleTimeout,
+			"maxConnectionDuration":         t.MaxConnectionDuration,
+			"delayedCloseTimeout":           t.DelayedCloseTimeout,
+			"connectionShutdownGracePeriod": t.ConnectionShutdownGracePeriod,
+		}
+		for name, timeout := range timeouts {
+			if err := validateTimeout(timeout); err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateTimeout returns an error if timeout is neither unset, "infinity"
+// nor a non-negative duration.
+func validateTimeout(timeout string) error {
+	if len(timeout) == 0 || timeout == "infinity" {
+		return nil
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return fmt.Errorf("failed to parse timeout %q: %w", timeout, err)
+	}
+	if d < 0 {
+		return fmt.Errorf("timeout %q must not be negative", timeout)
+	}
+	return nil
+}
+
 // ContainerPorts validates container ports of contour, returning an
 // error if the container ports do not meet the API specification.
 func ContainerPorts(contour *operatorv1alpha1.Contour) error {
@@ -163,14 +499,8 @@ func Gateway(ctx context.Context, cli client.Client, gw *gatewayv1alpha1.Gateway
 			gatewayv1alpha1.GatewayClassConditionStatusAdmitted, metav1.ConditionTrue)
 	}
 
-	if err := gatewayListeners(gw); err != nil {
-		errs = append(errs, fmt.Errorf("failed to validate listeners for gateway %s/%s: %w", gw.Namespace,
-			gw.Name, err))
-	}
-
-	if err := gatewayAddresses(gw); err != nil {
-		errs = append(errs, fmt.Errorf("failed to validate addresses for gateway %s/%s: %w", gw.Namespace,
-			gw.Name, err))
+	if err := GatewaySpec(gw); err != nil {
+		errs = append(errs, err)
 	}
 
 	contour, err := gatewayContour(ctx, cli, gw)
@@ -185,6 +515,24 @@ func Gateway(ctx context.Context, cli client.Client, gw *gatewayv1alpha1.Gateway
 	return contour, nil
 }
 
+// GatewaySpec returns an error if the spec of gw is invalid. Unlike Gateway,
+// the GatewayClass and Contour referenced by gw are not validated.
+func GatewaySpec(gw *gatewayv1alpha1.Gateway) error {
+	var errs []error
+
+	if err := gatewayListeners(gw); err != nil {
+		errs = append(errs, fmt.Errorf("failed to validate listeners for gateway %s/%s: %w", gw.Namespace,
+			gw.Name, err))
+	}
+
+	if err := gatewayAddresses(gw); err != nil {
+		errs = append(errs, fmt.Errorf("failed to validate addresses for gateway %s/%s: %w", gw.Namespace,
+			gw.Name, err))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
 // gatewayListeners returns an error if the listeners of the provided gw are invalid.
 // TODO [danehans]: Refactor when more than 2 listeners are supported.
 func gatewayListeners(gw *gatewayv1alpha1.Gateway) error {
@@ -17,9 +17,12 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	operatorv1alpha1 "github.com/projectcontour/contour-operator/api/v1alpha1"
+	objcontour "github.com/projectcontour/contour-operator/internal/objects/contour"
 	"github.com/projectcontour/contour-operator/internal/operator"
 	"github.com/projectcontour/contour-operator/pkg/validation"
 
@@ -292,6 +295,692 @@ func TestNodePorts(t *testing.T) {
 	}
 }
 
+func TestNames(t *testing.T) {
+	testCases := []struct {
+		description string
+		name        string
+		expected    bool
+	}{
+		{
+			description: "short name",
+			name:        "test-names",
+			expected:    true,
+		},
+		{
+			description: "longest name",
+			name:        strings.Repeat("a", 44),
+			expected:    true,
+		},
+		{
+			description: "certgen job name too long",
+			name:        strings.Repeat("a", 45),
+			expected:    false,
+		},
+		{
+			description: "service name too long",
+			name:        strings.Repeat("a", 60),
+			expected:    false,
+		},
+		{
+			description: "name starting with a digit",
+			name:        "1-contour",
+			expected:    false,
+		},
+		{
+			description: "name containing a dot",
+			name:        "contour.example",
+			expected:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		cntr := objcontour.New(objcontour.Config{
+			Name:        tc.name,
+			Namespace:   "test-names-ns",
+			SpecNs:      "projectcontour",
+			NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+		})
+		err := validation.Names(cntr)
+		if err != nil && tc.expected {
+			t.Fatalf("%q: failed with error: %#v", tc.description, err)
+		}
+		if err == nil && !tc.expected {
+			t.Fatalf("%q: expected an error but got nil", tc.description)
+		}
+	}
+}
+
+func TestImages(t *testing.T) {
+	testCases := []struct {
+		description  string
+		contourImage string
+		envoyImage   string
+		expected     bool
+	}{
+		{
+			description: "unspecified images",
+			expected:    true,
+		},
+		{
+			description:  "valid contour and envoy images",
+			contourImage: "docker.io/projectcontour/contour:main",
+			envoyImage:   "docker.io/envoyproxy/envoy:v1.18.3",
+			expected:     true,
+		},
+		{
+			description:  "invalid contour image",
+			contourImage: "docker.io/projectcontour/contour:-main",
+			expected:     false,
+		},
+		{
+			description: "invalid envoy image",
+			envoyImage:  "Docker.io/Envoyproxy/Envoy:v1.18.3",
+			expected:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		cntr := objcontour.New(objcontour.Config{
+			Name:        "test-images",
+			Namespace:   "test-images-ns",
+			SpecNs:      "projectcontour",
+			NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+		})
+		cntr.Spec.Contour.Image = tc.contourImage
+		cntr.Spec.Envoy.Image = tc.envoyImage
+		err := validation.Images(cntr)
+		if err != nil && tc.expected {
+			t.Fatalf("%q: failed with error: %#v", tc.description, err)
+		}
+		if err == nil && !tc.expected {
+			t.Fatalf("%q: expected an error but got nil", tc.description)
+		}
+	}
+}
+
+func TestEnvoyService(t *testing.T) {
+	testCases := []struct {
+		description string
+		mutate      func(envoy *operatorv1alpha1.EnvoyNetworkPublishing)
+		expected    bool
+	}{
+		{
+			description: "unspecified service parameters",
+			mutate:      func(_ *operatorv1alpha1.EnvoyNetworkPublishing) {},
+			expected:    true,
+		},
+		{
+			description: "valid service parameters",
+			mutate: func(envoy *operatorv1alpha1.EnvoyNetworkPublishing) {
+				envoy.ServiceAnnotations = map[string]string{"external-dns.alpha.kubernetes.io/hostname": "example.com"}
+				envoy.ServiceLabels = map[string]string{"app.kubernetes.io/part-of": "ingress"}
+				envoy.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeCluster
+				envoy.LoadBalancer.LoadBalancerIP = "203.0.113.10"
+				envoy.LoadBalancer.LoadBalancerSourceRanges = []string{"10.0.0.0/8", "2001:db8::/32"}
+			},
+			expected: true,
+		},
+		{
+			description: "invalid service annotation key",
+			mutate: func(envoy *operatorv1alpha1.EnvoyNetworkPublishing) {
+				envoy.ServiceAnnotations = map[string]string{"foo bar": "baz"}
+			},
+			expected: false,
+		},
+		{
+			description: "reserved service annotation key",
+			mutate: func(envoy *operatorv1alpha1.EnvoyNetworkPublishing) {
+				envoy.ServiceAnnotations = map[string]string{operatorv1alpha1.ManagedAnnotationsAnnotation: "foo"}
+			},
+			expected: false,
+		},
+		{
+			description: "invalid service label value",
+			mutate: func(envoy *operatorv1alpha1.EnvoyNetworkPublishing) {
+				envoy.ServiceLabels = map[string]string{"foo": "bar baz"}
+			},
+			expected: false,
+		},
+		{
+			description: "external traffic policy with cluster ip service",
+			mutate: func(envoy *operatorv1alpha1.EnvoyNetworkPublishing) {
+				envoy.Type = operatorv1alpha1.ClusterIPServicePublishingType
+				envoy.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeLocal
+			},
+			expected: false,
+		},
+		{
+			description: "invalid load balancer ip",
+			mutate: func(envoy *operatorv1alpha1.EnvoyNetworkPublishing) {
+				envoy.LoadBalancer.LoadBalancerIP = "203.0.113"
+			},
+			expected: false,
+		},
+		{
+			description: "valid azure parameters",
+			mutate: func(envoy *operatorv1alpha1.EnvoyNetworkPublishing) {
+				envoy.LoadBalancer.Scope = operatorv1alpha1.InternalLoadBalancer
+				envoy.LoadBalancer.ProviderParameters = operatorv1alpha1.ProviderLoadBalancerParameters{
+					Type: operatorv1alpha1.AzureLoadBalancerProvider,
+					Azure: &operatorv1alpha1.AzureLoadBalancerParameters{
+						ResourceGroup: "contour_rg-1",
+						Subnet:        "contour-subnet",
+						HealthProbe: &operatorv1alpha1.AzureHealthProbe{
+							Protocol:    operatorv1alpha1.AzureHealthProbeProtocolHTTPS,
+							RequestPath: "/ready",
+						},
+					},
+				}
+			},
+			expected: true,
+		},
+		{
+			description: "azure parameters with gcp provider",
+			mutate: func(envoy *operatorv1alpha1.EnvoyNetworkPublishing) {
+				envoy.LoadBalancer.ProviderParameters = operatorv1alpha1.ProviderLoadBalancerParameters{
+					Type:  operatorv1alpha1.GCPLoadBalancerProvider,
+					Azure: &operatorv1alpha1.AzureLoadBalancerParameters{},
+				}
+			},
+			expected: false,
+		},
+		{
+			description: "invalid azure resource group",
+			mutate: func(envoy *operatorv1alpha1.EnvoyNetworkPublishing) {
+				envoy.LoadBalancer.ProviderParameters = operatorv1alpha1.ProviderLoadBalancerParameters{
+					Type:  operatorv1alpha1.AzureLoadBalancerProvider,
+					Azure: &operatorv1alpha1.AzureLoadBalancerParameters{ResourceGroup: "contour-rg."},
+				}
+			},
+			expected: false,
+		},
+		{
+			description: "azure subnet with external load balancer",
+			mutate: func(envoy *operatorv1alpha1.EnvoyNetworkPublishing) {
+				envoy.LoadBalancer.ProviderParameters = operatorv1alpha1.ProviderLoadBalancerParameters{
+					Type:  operatorv1alpha1.AzureLoadBalancerProvider,
+					Azure: &operatorv1alpha1.AzureLoadBalancerParameters{Subnet: "contour-subnet"},
+				}
+			},
+			expected: false,
+		},
+		{
+			description: "azure health probe request path with tcp protocol",
+			mutate: func(envoy *operatorv1alpha1.EnvoyNetworkPublishing) {
+				envoy.LoadBalancer.ProviderParameters = operatorv1alpha1.ProviderLoadBalancerParameters{
+					Type: operatorv1alpha1.AzureLoadBalancerProvider,
+					Azure: &operatorv1alpha1.AzureLoadBalancerParameters{
+						HealthProbe: &operatorv1alpha1.AzureHealthProbe{
+							Protocol:    operatorv1alpha1.AzureHealthProbeProtocolTCP,
+							RequestPath: "/ready",
+						},
+					},
+				}
+			},
+			expected: false,
+		},
+		{
+			description: "invalid azure health probe request path",
+			mutate: func(envoy *operatorv1alpha1.EnvoyNetworkPublishing) {
+				envoy.LoadBalancer.ProviderParameters = operatorv1alpha1.ProviderLoadBalancerParameters{
+					Type: operatorv1alpha1.AzureLoadBalancerProvider,
+					Azure: &operatorv1alpha1.AzureLoadBalancerParameters{
+						HealthProbe: &operatorv1alpha1.AzureHealthProbe{RequestPath: "ready"},
+					},
+				}
+			},
+			expected: false,
+		},
+		{
+			description: "valid gcp parameters",
+			mutate: func(envoy *operatorv1alpha1.EnvoyNetworkPublishing) {
+				envoy.LoadBalancer.ProviderParameters = operatorv1alpha1.ProviderLoadBalancerParameters{
+					Type: operatorv1alpha1.GCPLoadBalancerProvider,
+					GCP: &operatorv1alpha1.GCPLoadBalancerParameters{
+						BackendServiceBased:   true,
+						NetworkEndpointGroups: true,
+					},
+				}
+			},
+			expected: true,
+		},
+		{
+			description: "gcp global access with external load balancer",
+			mutate: func(envoy *operatorv1alpha1.EnvoyNetworkPublishing) {
+				envoy.LoadBalancer.ProviderParameters = operatorv1alpha1.ProviderLoadBalancerParameters{
+					Type: operatorv1alpha1.GCPLoadBalancerProvider,
+					GCP:  &operatorv1alpha1.GCPLoadBalancerParameters{GlobalAccess: true},
+				}
+			},
+			expected: false,
+		},
+		{
+			description: "gcp backend service with internal load balancer",
+			mutate: func(envoy *operatorv1alpha1.EnvoyNetworkPublishing) {
+				envoy.LoadBalancer.Scope = operatorv1alpha1.InternalLoadBalancer
+				envoy.LoadBalancer.ProviderParameters = operatorv1alpha1.ProviderLoadBalancerParameters{
+					Type: operatorv1alpha1.GCPLoadBalancerProvider,
+					GCP:  &operatorv1alpha1.GCPLoadBalancerParameters{BackendServiceBased: true},
+				}
+			},
+			expected: false,
+		},
+		{
+			description: "invalid load balancer source range",
+			mutate: func(envoy *operatorv1alpha1.EnvoyNetworkPublishing) {
+				envoy.LoadBalancer.LoadBalancerSourceRanges = []string{"10.0.0.0"}
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		cntr := objcontour.New(objcontour.Config{
+			Name:        "test-envoy-service",
+			Namespace:   "test-envoy-service-ns",
+			SpecNs:      "projectcontour",
+			NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+		})
+		tc.mutate(&cntr.Spec.NetworkPublishing.Envoy)
+		err := validation.EnvoyService(cntr)
+		if err != nil && tc.expected {
+			t.Fatalf("%q: failed with error: %#v", tc.description, err)
+		}
+		if err == nil && !tc.expected {
+			t.Fatalf("%q: expected an error but got nil", tc.description)
+		}
+	}
+}
+
+func TestIPFamilies(t *testing.T) {
+	singleStack := corev1.IPFamilyPolicySingleStack
+	dualStack := corev1.IPFamilyPolicyRequireDualStack
+	testCases := []struct {
+		description string
+		policy      *corev1.IPFamilyPolicyType
+		families    []corev1.IPFamily
+		expected    bool
+	}{
+		{
+			description: "unspecified ip families",
+			expected:    true,
+		},
+		{
+			description: "ipv6 single-stack",
+			policy:      &singleStack,
+			families:    []corev1.IPFamily{corev1.IPv6Protocol},
+			expected:    true,
+		},
+		{
+			description: "dual-stack with ipv6 primary",
+			policy:      &dualStack,
+			families:    []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol},
+			expected:    true,
+		},
+		{
+			description: "invalid ip family",
+			families:    []corev1.IPFamily{"IPv5"},
+			expected:    false,
+		},
+		{
+			description: "duplicate ip families",
+			families:    []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv4Protocol},
+			expected:    false,
+		},
+		{
+			description: "single-stack with two ip families",
+			policy:      &singleStack,
+			families:    []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol},
+			expected:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		cntr := objcontour.New(objcontour.Config{
+			Name:        "test-ip-families",
+			Namespace:   "test-ip-families-ns",
+			SpecNs:      "projectcontour",
+			NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+		})
+		cntr.Spec.IPFamilyPolicy = tc.policy
+		cntr.Spec.IPFamilies = tc.families
+		err := validation.IPFamilies(cntr)
+		if err != nil && tc.expected {
+			t.Fatalf("%q: failed with error: %#v", tc.description, err)
+		}
+		if err == nil && !tc.expected {
+			t.Fatalf("%q: expected an error but got nil", tc.description)
+		}
+	}
+}
+
+func TestXDSTLS(t *testing.T) {
+	testCases := []struct {
+		description string
+		provider    operatorv1alpha1.XDSTLSProvider
+		lifetime    *metav1.Duration
+		issuerRef   *operatorv1alpha1.IssuerReference
+		secretRefs  *operatorv1alpha1.XDSTLSSecretRefs
+		expected    bool
+	}{
+		{
+			description: "unspecified certificate lifetime",
+			provider:    operatorv1alpha1.OperatorXDSTLSProvider,
+			expected:    true,
+		},
+		{
+			description: "90 day certificate lifetime",
+			provider:    operatorv1alpha1.OperatorXDSTLSProvider,
+			lifetime:    &metav1.Duration{Duration: 90 * 24 * time.Hour},
+			expected:    true,
+		},
+		{
+			description: "one hour certificate lifetime",
+			provider:    operatorv1alpha1.OperatorXDSTLSProvider,
+			lifetime:    &metav1.Duration{Duration: time.Hour},
+			expected:    false,
+		},
+		{
+			description: "cert-manager with an issuer",
+			provider:    operatorv1alpha1.CertManagerXDSTLSProvider,
+			issuerRef: &operatorv1alpha1.IssuerReference{
+				Name: "xds-ca",
+				Kind: operatorv1alpha1.IssuerKindClusterIssuer,
+			},
+			expected: true,
+		},
+		{
+			description: "cert-manager without an issuer",
+			provider:    operatorv1alpha1.CertManagerXDSTLSProvider,
+			expected:    false,
+		},
+		{
+			description: "secret references",
+			provider:    operatorv1alpha1.CertGenXDSTLSProvider,
+			secretRefs:  &operatorv1alpha1.XDSTLSSecretRefs{Contour: "contour-xds", Envoy: "envoy-xds"},
+			expected:    true,
+		},
+		{
+			description: "secret references with the operator provider",
+			provider:    operatorv1alpha1.OperatorXDSTLSProvider,
+			secretRefs:  &operatorv1alpha1.XDSTLSSecretRefs{Contour: "contour-xds", Envoy: "envoy-xds"},
+			expected:    false,
+		},
+		{
+			description: "secret references without an envoy secret",
+			provider:    operatorv1alpha1.CertGenXDSTLSProvider,
+			secretRefs:  &operatorv1alpha1.XDSTLSSecretRefs{Contour: "contour-xds"},
+			expected:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		cntr := objcontour.New(objcontour.Config{
+			Name:        "test-xds-tls",
+			Namespace:   "test-xds-tls-ns",
+			SpecNs:      "projectcontour",
+			NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+		})
+		cntr.Spec.XDSTLS.Provider = tc.provider
+		cntr.Spec.XDSTLS.CertificateLifetime = tc.lifetime
+		cntr.Spec.XDSTLS.IssuerRef = tc.issuerRef
+		cntr.Spec.XDSTLS.SecretRefs = tc.secretRefs
+		err := validation.XDSTLS(cntr)
+		if err != nil && tc.expected {
+			t.Fatalf("%q: failed with error: %#v", tc.description, err)
+		}
+		if err == nil && !tc.expected {
+			t.Fatalf("%q: expected an error but got nil", tc.description)
+		}
+	}
+}
+
+func TestConfig(t *testing.T) {
+	testCases := []struct {
+		description string
+		cfg         operatorv1alpha1.ContourConfig
+		expected    bool
+	}{
+		{
+			description: "unspecified config",
+			expected:    true,
+		},
+		{
+			description: "valid config",
+			cfg: operatorv1alpha1.ContourConfig{
+				TLS: &operatorv1alpha1.TLSConfig{
+					MinimumProtocolVersion: "1.3",
+					CipherSuites:           []string{"ECDHE-ECDSA-AES256-GCM-SHA384", "ECDHE-RSA-AES256-GCM-SHA384"},
+				},
+				AccessLogFormat:     operatorv1alpha1.JSONAccessLogFormat,
+				JSONFields:          []string{"@timestamp", "x_trace_id=%REQ(X-TRACE-ID)%"},
+				DefaultHTTPVersions: []operatorv1alpha1.HTTPVersion{operatorv1alpha1.HTTPVersion2},
+				Timeouts: &operatorv1alpha1.TimeoutConfig{
+					RequestTimeout:        "infinity",
+					ConnectionIdleTimeout: "90s",
+				},
+			},
+			expected: true,
+		},
+		{
+			description: "invalid cipher suite",
+			cfg: operatorv1alpha1.ContourConfig{
+				TLS: &operatorv1alpha1.TLSConfig{
+					CipherSuites: []string{"ECDHE-RSA-DES-CBC3-SHA"},
+				},
+			},
+			expected: false,
+		},
+		{
+			description: "duplicate cipher suite",
+			cfg: operatorv1alpha1.ContourConfig{
+				TLS: &operatorv1alpha1.TLSConfig{
+					CipherSuites: []string{"AES256-SHA", "AES256-SHA"},
+				},
+			},
+			expected: false,
+		},
+		{
+			description: "invalid json field",
+			cfg: operatorv1alpha1.ContourConfig{
+				JSONFields: []string{"=%REQ(X-TRACE-ID)%"},
+			},
+			expected: false,
+		},
+		{
+			description: "duplicate json field",
+			cfg: operatorv1alpha1.ContourConfig{
+				JSONFields: []string{"method", "method=%REQ(:METHOD)%"},
+			},
+			expected: false,
+		},
+		{
+			description: "duplicate default http version",
+			cfg: operatorv1alpha1.ContourConfig{
+				DefaultHTTPVersions: []operatorv1alpha1.HTTPVersion{operatorv1alpha1.HTTPVersion1, operatorv1alpha1.HTTPVersion1},
+			},
+			expected: false,
+		},
+		{
+			description: "invalid timeout",
+			cfg: operatorv1alpha1.ContourConfig{
+				Timeouts: &operatorv1alpha1.TimeoutConfig{
+					StreamIdleTimeout: "5 minutes",
+				},
+			},
+			expected: false,
+		},
+		{
+			description: "negative timeout",
+			cfg: operatorv1alpha1.ContourConfig{
+				Timeouts: &operatorv1alpha1.TimeoutConfig{
+					DelayedCloseTimeout: "-1s",
+				},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		cntr := objcontour.New(objcontour.Config{
+			Name:        "test-config",
+			Namespace:   "test-config-ns",
+			SpecNs:      "projectcontour",
+			NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+		})
+		cntr.Spec.Config = tc.cfg
+		err := validation.Config(cntr)
+		if err != nil && tc.expected {
+			t.Fatalf("%q: failed with error: %#v", tc.description, err)
+		}
+		if err == nil && !tc.expected {
+			t.Fatalf("%q: expected an error but got nil", tc.description)
+		}
+	}
+}
+
+func TestContour(t *testing.T) {
+	existing := objcontour.New(objcontour.Config{
+		Name:        "test-contour",
+		Namespace:   "test-contour-ns",
+		SpecNs:      "projectcontour",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+
+	testCases := []struct {
+		description string
+		cfg         objcontour.Config
+		expected    bool
+	}{
+		{
+			description: "different name in the same spec namespace",
+			cfg: objcontour.Config{
+				Name:      "test-contour-2",
+				Namespace: "test-contour-ns",
+				SpecNs:    "projectcontour",
+			},
+			expected: true,
+		},
+		{
+			description: "same name in a different spec namespace",
+			cfg: objcontour.Config{
+				Name:      "test-contour",
+				Namespace: "test-contour-ns-2",
+				SpecNs:    "projectcontour-2",
+			},
+			expected: true,
+		},
+		{
+			description: "same name in the same spec namespace",
+			cfg: objcontour.Config{
+				Name:      "test-contour",
+				Namespace: "test-contour-ns-2",
+				SpecNs:    "projectcontour",
+			},
+			expected: false,
+		},
+	}
+
+	builder := fake.NewClientBuilder()
+	builder.WithScheme(operator.GetOperatorScheme())
+	builder.WithObjects(existing)
+	cl := builder.Build()
+
+	for _, tc := range testCases {
+		tc.cfg.NetworkType = operatorv1alpha1.LoadBalancerServicePublishingType
+		err := validation.Contour(context.TODO(), cl, objcontour.New(tc.cfg))
+		if err != nil && tc.expected {
+			t.Fatalf("%q: failed with error: %#v", tc.description, err)
+		}
+		if err == nil && !tc.expected {
+			t.Fatalf("%q: expected an error but got nil", tc.description)
+		}
+		if err != nil && !validation.IsInvalid(err) {
+			t.Fatalf("%q: expected an invalid contour error, got %#v", tc.description, err)
+		}
+	}
+}
+
+func TestNamespaceMigration(t *testing.T) {
+	other := objcontour.New(objcontour.Config{
+		Name:        "test-contour",
+		Namespace:   "test-contour-ns-2",
+		SpecNs:      "projectcontour-2",
+		NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+	})
+	other.Status.AppliedNamespace = "projectcontour-2"
+	now := metav1.Now()
+	terminating := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "projectcontour-terminating",
+			DeletionTimestamp: &now,
+			Finalizers:        []string{"kubernetes"},
+		},
+	}
+
+	testCases := []struct {
+		description string
+		applied     string
+		target      string
+		expected    bool
+	}{
+		{
+			description: "not yet applied",
+			target:      "projectcontour-3",
+			expected:    true,
+		},
+		{
+			description: "applied to the target namespace",
+			applied:     "projectcontour",
+			target:      "projectcontour",
+			expected:    true,
+		},
+		{
+			description: "migration to a new namespace",
+			applied:     "projectcontour",
+			target:      "projectcontour-3",
+			expected:    true,
+		},
+		{
+			description: "migration to a terminating namespace",
+			applied:     "projectcontour",
+			target:      "projectcontour-terminating",
+			expected:    false,
+		},
+		{
+			description: "migration to the namespace of a contour with the same name",
+			applied:     "projectcontour",
+			target:      "projectcontour-2",
+			expected:    false,
+		},
+	}
+
+	builder := fake.NewClientBuilder()
+	builder.WithScheme(operator.GetOperatorScheme())
+	builder.WithObjects(other, terminating)
+	cl := builder.Build()
+
+	for _, tc := range testCases {
+		cntr := objcontour.New(objcontour.Config{
+			Name:        "test-contour",
+			Namespace:   "test-contour-ns",
+			SpecNs:      tc.target,
+			NetworkType: operatorv1alpha1.LoadBalancerServicePublishingType,
+		})
+		cntr.Status.AppliedNamespace = tc.applied
+		err := validation.NamespaceMigration(context.TODO(), cl, cntr)
+		if err != nil && tc.expected {
+			t.Fatalf("%q: failed with error: %#v", tc.description, err)
+		}
+		if err == nil && !tc.expected {
+			t.Fatalf("%q: expected an error but got nil", tc.description)
+		}
+		if err != nil && !validation.IsInvalid(err) {
+			t.Fatalf("%q: expected an invalid contour error, got %#v", tc.description, err)
+		}
+	}
+}
+
 func TestGatewayClass(t *testing.T) {
 
 	testCases := map[string]struct {
@@ -133,7 +133,7 @@ func TestDefaultContour(t *testing.T) {
 	t.Logf("created contour %s/%s", cntr.Namespace, cntr.Name)
 
 	if isKind {
-		svcName := "envoy"
+		svcName := objcontour.EnvoyName(cntr)
 		if err := updateLbSvcIPAndNodePorts(ctx, kclient, timeout, cfg.SpecNs, svcName); err != nil {
 			t.Fatalf("failed to update service %s/%s: %v", cfg.SpecNs, svcName, err)
 		}
@@ -198,10 +198,10 @@ func TestDefaultContour(t *testing.T) {
 	t.Logf("deleted contour %s/%s", operatorNs, testName)
 
 	// Ensure the envoy service is cleaned up automatically.
-	if err := waitForServiceDeletion(ctx, kclient, timeout, cfg.SpecNs, "envoy"); err != nil {
-		t.Fatalf("failed to delete envoy service %s/envoy: %v", cfg.SpecNs, err)
+	if err := waitForServiceDeletion(ctx, kclient, timeout, cfg.SpecNs, objcontour.EnvoyName(cntr)); err != nil {
+		t.Fatalf("failed to delete envoy service %s/%s: %v", cfg.SpecNs, objcontour.EnvoyName(cntr), err)
 	}
-	t.Logf("cleaned up envoy service %s/envoy", cfg.SpecNs)
+	t.Logf("cleaned up envoy service %s/%s", cfg.SpecNs, objcontour.EnvoyName(cntr))
 
 	// Delete the operand namespace since contour.spec.namespace.removeOnDeletion
 	// defaults to false.
@@ -350,10 +350,10 @@ func TestContourNodePortService(t *testing.T) {
 	t.Logf("deleted contour %s/%s", cfg.Namespace, cfg.Name)
 
 	// Ensure the envoy service is cleaned up automatically.
-	if err := waitForServiceDeletion(ctx, kclient, timeout, cfg.SpecNs, "envoy"); err != nil {
-		t.Fatalf("failed to delete envoy service %s/envoy: %v", cfg.SpecNs, err)
+	if err := waitForServiceDeletion(ctx, kclient, timeout, cfg.SpecNs, objcontour.EnvoyName(cntr)); err != nil {
+		t.Fatalf("failed to delete envoy service %s/%s: %v", cfg.SpecNs, objcontour.EnvoyName(cntr), err)
 	}
-	t.Logf("cleaned up envoy service %s/envoy", cfg.SpecNs)
+	t.Logf("cleaned up envoy service %s/%s", cfg.SpecNs, objcontour.EnvoyName(cntr))
 
 	// Delete the operand namespace since contour.spec.namespace.removeOnDeletion
 	// defaults to false.
@@ -406,7 +406,7 @@ func TestContourClusterIPService(t *testing.T) {
 	t.Logf("created ingress %s/%s", cfg.SpecNs, appName)
 
 	// Get the Envoy ClusterIP to curl.
-	svcName := "envoy"
+	svcName := objcontour.EnvoyName(cntr)
 	ip, err := envoyClusterIP(ctx, kclient, cfg.SpecNs, svcName)
 	if err != nil {
 		t.Fatalf("failed to get clusterIP for service %s/%s: %v", cfg.SpecNs, svcName, err)
@@ -427,10 +427,10 @@ func TestContourClusterIPService(t *testing.T) {
 	t.Logf("deleted contour %s/%s", operatorNs, testName)
 
 	// Ensure the envoy service is cleaned up automatically.
-	if err := waitForServiceDeletion(ctx, kclient, timeout, cfg.SpecNs, "envoy"); err != nil {
-		t.Fatalf("failed to delete envoy service %s/envoy: %v", cfg.SpecNs, err)
+	if err := waitForServiceDeletion(ctx, kclient, timeout, cfg.SpecNs, objcontour.EnvoyName(cntr)); err != nil {
+		t.Fatalf("failed to delete envoy service %s/%s: %v", cfg.SpecNs, objcontour.EnvoyName(cntr), err)
 	}
-	t.Logf("cleaned up envoy service %s/envoy", cfg.SpecNs)
+	t.Logf("cleaned up envoy service %s/%s", cfg.SpecNs, objcontour.EnvoyName(cntr))
 
 	// Delete the operand namespace since contour.spec.namespace.removeOnDeletion
 	// defaults to false.
@@ -573,6 +573,96 @@ func TestMultipleContours(t *testing.T) {
 	}
 }
 
+func TestMultipleContoursInNamespace(t *testing.T) {
+	specNs := "test-shared-contour-ns"
+	testNames := []string{"test-shared-contour", "test-shared-contour-2"}
+	var contours []*operatorv1alpha1.Contour
+	for _, testName := range testNames {
+		cfg := objcontour.Config{
+			Name:        testName,
+			Namespace:   operatorNs,
+			SpecNs:      specNs,
+			NetworkType: operatorv1alpha1.ClusterIPServicePublishingType,
+		}
+		cntr, err := newContour(ctx, kclient, cfg)
+		if err != nil {
+			t.Fatalf("failed to create contour %s/%s: %v", operatorNs, testName, err)
+		}
+		t.Logf("created contour %s/%s", cntr.Namespace, cntr.Name)
+		contours = append(contours, cntr)
+	}
+
+	for _, testName := range testNames {
+		if err := waitForContourStatusConditions(ctx, kclient, timeout, testName, operatorNs, expectedContourConditions...); err != nil {
+			t.Fatalf("failed to observe expected status conditions for contour %s/%s: %v", operatorNs, testName, err)
+		}
+		t.Logf("observed expected status conditions for contour %s/%s", operatorNs, testName)
+	}
+
+	// Ensure the contours can be deleted and clean-up.
+	for _, cntr := range contours {
+		if err := deleteContour(ctx, kclient, timeout, cntr.Name, cntr.Namespace); err != nil {
+			t.Fatalf("failed to delete contour %s/%s: %v", cntr.Namespace, cntr.Name, err)
+		}
+		t.Logf("deleted contour %s/%s", cntr.Namespace, cntr.Name)
+
+		if err := waitForServiceDeletion(ctx, kclient, timeout, specNs, objcontour.EnvoyName(cntr)); err != nil {
+			t.Fatalf("failed to delete envoy service %s/%s: %v", specNs, objcontour.EnvoyName(cntr), err)
+		}
+		t.Logf("cleaned up envoy service %s/%s", specNs, objcontour.EnvoyName(cntr))
+	}
+
+	if err := deleteNamespace(ctx, kclient, timeout, specNs); err != nil {
+		t.Fatalf("failed to delete namespace %s: %v", specNs, err)
+	}
+	t.Logf("deleted namespace %s", specNs)
+}
+
+func TestEnvoyDeployment(t *testing.T) {
+	testName := "test-envoy-deployment"
+	specNs := fmt.Sprintf("%s-ns", testName)
+	cfg := objcontour.Config{
+		Name:              testName,
+		Namespace:         operatorNs,
+		SpecNs:            specNs,
+		NetworkType:       operatorv1alpha1.ClusterIPServicePublishingType,
+		EnvoyWorkloadType: operatorv1alpha1.DeploymentEnvoyWorkloadType,
+		EnvoyReplicas:     int32(1),
+	}
+	cntr, err := newContour(ctx, kclient, cfg)
+	if err != nil {
+		t.Fatalf("failed to create contour %s/%s: %v", operatorNs, testName, err)
+	}
+	t.Logf("created contour %s/%s", cntr.Namespace, cntr.Name)
+
+	if err := waitForContourStatusConditions(ctx, kclient, timeout, testName, operatorNs, expectedContourConditions...); err != nil {
+		t.Fatalf("failed to observe expected status conditions for contour %s/%s: %v", operatorNs, testName, err)
+	}
+	t.Logf("observed expected status conditions for contour %s/%s", operatorNs, testName)
+
+	envoyName := objcontour.EnvoyName(cntr)
+	if err := waitForDeploymentStatusConditions(ctx, kclient, timeout, envoyName, specNs, expectedDeploymentConditions...); err != nil {
+		t.Fatalf("failed to observe expected status conditions for envoy deployment %s/%s: %v", specNs, envoyName, err)
+	}
+	t.Logf("observed expected status conditions for envoy deployment %s/%s", specNs, envoyName)
+
+	// Ensure the contour can be deleted and clean-up.
+	if err := deleteContour(ctx, kclient, timeout, cntr.Name, cntr.Namespace); err != nil {
+		t.Fatalf("failed to delete contour %s/%s: %v", cntr.Namespace, cntr.Name, err)
+	}
+	t.Logf("deleted contour %s/%s", cntr.Namespace, cntr.Name)
+
+	if err := waitForServiceDeletion(ctx, kclient, timeout, specNs, envoyName); err != nil {
+		t.Fatalf("failed to delete envoy service %s/%s: %v", specNs, envoyName, err)
+	}
+	t.Logf("cleaned up envoy service %s/%s", specNs, envoyName)
+
+	if err := deleteNamespace(ctx, kclient, timeout, specNs); err != nil {
+		t.Fatalf("failed to delete namespace %s: %v", specNs, err)
+	}
+	t.Logf("deleted namespace %s", specNs)
+}
+
 func TestGateway(t *testing.T) {
 	testName := "test-gateway"
 	contourName := fmt.Sprintf("%s-contour", testName)
@@ -689,10 +779,10 @@ func TestGateway(t *testing.T) {
 		t.Fatalf("failed to delete contour %s/%s: %v", operatorNs, contourName, err)
 	}
 	// Ensure the envoy service is cleaned up automatically.
-	if err := waitForServiceDeletion(ctx, kclient, timeout, cfg.SpecNs, "envoy"); err != nil {
-		t.Fatalf("failed to delete envoy service %s/envoy: %v", cfg.SpecNs, err)
+	if err := waitForServiceDeletion(ctx, kclient, timeout, cfg.SpecNs, objcontour.EnvoyName(cntr)); err != nil {
+		t.Fatalf("failed to delete envoy service %s/%s: %v", cfg.SpecNs, objcontour.EnvoyName(cntr), err)
 	}
-	t.Logf("cleaned up envoy service %s/envoy", cfg.SpecNs)
+	t.Logf("cleaned up envoy service %s/%s", cfg.SpecNs, objcontour.EnvoyName(cntr))
 
 	// Delete the operand namespace since contour.spec.namespace.removeOnDeletion
 	// defaults to false.
@@ -778,7 +868,7 @@ func TestGatewayClusterIP(t *testing.T) {
 	t.Logf("created httproute %s/%s", cfg.SpecNs, appName)
 
 	// Get the Envoy ClusterIP to curl.
-	svcName := "envoy"
+	svcName := objcontour.EnvoyName(cntr)
 	ip, err := envoyClusterIP(ctx, kclient, cfg.SpecNs, svcName)
 	if err != nil {
 		t.Fatalf("failed to get clusterIP for service %s/%s: %v", cfg.SpecNs, svcName, err)
@@ -808,10 +898,10 @@ func TestGatewayClusterIP(t *testing.T) {
 	}
 
 	// Ensure the envoy service is cleaned up automatically.
-	if err := waitForServiceDeletion(ctx, kclient, timeout, cfg.SpecNs, "envoy"); err != nil {
-		t.Fatalf("failed to delete envoy service %s/envoy: %v", cfg.SpecNs, err)
+	if err := waitForServiceDeletion(ctx, kclient, timeout, cfg.SpecNs, objcontour.EnvoyName(cntr)); err != nil {
+		t.Fatalf("failed to delete envoy service %s/%s: %v", cfg.SpecNs, objcontour.EnvoyName(cntr), err)
 	}
-	t.Logf("cleaned up envoy service %s/envoy", cfg.SpecNs)
+	t.Logf("cleaned up envoy service %s/%s", cfg.SpecNs, objcontour.EnvoyName(cntr))
 
 	// Delete the operand namespace since contour.spec.namespace.removeOnDeletion
 	// defaults to false.
@@ -923,10 +1013,10 @@ func TestGatewayOwnership(t *testing.T) {
 	}
 
 	// Ensure the envoy service is cleaned up automatically.
-	if err := waitForServiceDeletion(ctx, kclient, timeout, cfg.SpecNs, "envoy"); err != nil {
-		t.Fatalf("failed to delete envoy service %s/envoy: %v", cfg.SpecNs, err)
+	if err := waitForServiceDeletion(ctx, kclient, timeout, cfg.SpecNs, objcontour.EnvoyName(cntr)); err != nil {
+		t.Fatalf("failed to delete envoy service %s/%s: %v", cfg.SpecNs, objcontour.EnvoyName(cntr), err)
 	}
-	t.Logf("cleaned up envoy service %s/envoy", cfg.SpecNs)
+	t.Logf("cleaned up envoy service %s/%s", cfg.SpecNs, objcontour.EnvoyName(cntr))
 
 	// Delete the operand namespace since contour.spec.namespace.removeOnDeletion
 	// defaults to false.
@@ -1015,7 +1105,7 @@ func TestOperatorUpgrade(t *testing.T) {
 	t.Logf("created ingress %s/%s", cfg.SpecNs, appName)
 
 	// Get the Envoy ClusterIP to curl.
-	svcName := "envoy"
+	svcName := objcontour.EnvoyName(cntr)
 	ip, err := envoyClusterIP(ctx, kclient, cfg.SpecNs, svcName)
 	if err != nil {
 		t.Fatalf("failed to get clusterIP for service %s/%s: %v", cfg.SpecNs, svcName, err)
@@ -303,7 +303,7 @@ func waitForContourStatusConditions(ctx context.Context, cl client.Client, timeo
 			return false, nil
 		}
 
-		envoyReplicas, err := envoyReplicas(ctx, cl, cntr.Spec.Namespace.Name)
+		envoyReplicas, err := envoyReplicas(ctx, cl, cntr)
 		if err != nil || cntr.Status.AvailableEnvoys != envoyReplicas {
 			return false, nil
 		}
@@ -749,14 +749,14 @@ func envoyClusterIP(ctx context.Context, cl client.Client, ns, name string) (str
 }
 
 // envoyReplicas returns the number of envoy pods running by numberReady in daemonset status.
-func envoyReplicas(ctx context.Context, cl client.Client, ns string) (int32, error) {
+func envoyReplicas(ctx context.Context, cl client.Client, cntr *operatorv1alpha1.Contour) (int32, error) {
 	ds := &appsv1.DaemonSet{}
 	key := types.NamespacedName{
-		Namespace: ns,
-		Name:      "envoy",
+		Namespace: cntr.Spec.Namespace.Name,
+		Name:      objcontour.EnvoyName(cntr),
 	}
 	if err := cl.Get(ctx, key, ds); err != nil {
-		return 0, fmt.Errorf("failed to get daemonset %s/envoy: %v", ns, err)
+		return 0, fmt.Errorf("failed to get daemonset %s: %v", key, err)
 
 	}
 	return ds.Status.NumberReady, nil